@@ -0,0 +1,96 @@
+// Package adaptertest 各框架回调适配器共用的测试：以真实的 CallbackDispatcher 和加密回调校验应答
+package adaptertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"github.com/sirupsen/logrus"
+)
+
+// Path 挂载回调的路径
+const Path = "/notify"
+
+const (
+	accessId  = "0123456789abcdef"
+	accessKey = "fedcba9876543210"
+)
+
+// ServeFunc 将 p 挂载到框架的 POST Path 路由并处理 req，返回应答
+type ServeFunc func(p xmpay.CallbackProcessor, req *http.Request) *http.Response
+
+// Run 依次校验成功、重复、处理失败、失败后重推和无法解密的回调的应答
+func Run(t *testing.T, serve ServeFunc) {
+	t.Helper()
+	log := logrus.New()
+	log.Out = io.Discard
+	client := xmpay.NewHttpClient(&xmpay.Config{ApiUrl: "http://127.0.0.1:1", AccessId: accessId, AccessKey: accessKey}, logrus.NewEntry(log))
+	dispatcher := xmpay.NewCallbackDispatcher(client, nil, logrus.NewEntry(log))
+
+	calls := map[string]int{}
+	failing := true
+	dispatcher.Handle(func(cb *pb.CallbackParam) error {
+		calls[cb.MerchantNo]++
+		if cb.MerchantNo == "M2" && failing {
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+
+	ok := &pb.CallbackParam{MerchantNo: "M1", OrderNo: "T1", Status: pb.ORDER_STATUS_SUCCESS}
+	fail := &pb.CallbackParam{MerchantNo: "M2", OrderNo: "T2", Status: pb.ORDER_STATUS_SUCCESS}
+	steps := []struct {
+		name     string
+		body     []byte
+		wantCode int
+		wantAck  string
+		merchant string
+		calls    int
+	}{
+		{"success", body(t, accessKey, ok), http.StatusOK, xmpay.CallbackSuccess, "M1", 1},
+		{"duplicate", body(t, accessKey, ok), http.StatusOK, xmpay.CallbackSuccess, "M1", 1},
+		{"handler failed", body(t, accessKey, fail), http.StatusInternalServerError, xmpay.CallbackFail, "M2", 1},
+		{"retry", body(t, accessKey, fail), http.StatusOK, xmpay.CallbackSuccess, "M2", 2},
+		{"decrypt failed", body(t, "0000000000000000", &pb.CallbackParam{MerchantNo: "M3", Status: pb.ORDER_STATUS_SUCCESS}),
+			http.StatusInternalServerError, xmpay.CallbackFail, "M3", 0},
+	}
+	for _, s := range steps {
+		if s.name == "retry" {
+			failing = false
+		}
+		resp := serve(dispatcher, httptest.NewRequest(http.MethodPost, Path, bytes.NewReader(s.body)))
+		ack, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != s.wantCode || string(ack) != s.wantAck {
+			t.Errorf("%s: response = %d %q, want %d %q", s.name, resp.StatusCode, ack, s.wantCode, s.wantAck)
+		}
+		if calls[s.merchant] != s.calls {
+			t.Errorf("%s: handler calls = %d, want %d", s.name, calls[s.merchant], s.calls)
+		}
+	}
+}
+
+// body 以 key 为 IV 加密的回调请求体
+func body(t *testing.T, key string, cb *pb.CallbackParam) []byte {
+	t.Helper()
+	data, err := json.Marshal(cb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypt, err := xmpay.NewAES([]byte(accessId), []byte(key)).Encrypt(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(&pb.PayRpcParam{AppKey: accessId, Data: encrypt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,19 @@
+// Package xmchi 将 XMPAY 回调处理挂载到 chi
+package xmchi
+
+import (
+	"net/http"
+
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/go-chi/chi/v5"
+)
+
+// Handler 返回处理 XMPAY 回调的 http.Handler，完成解密、校验、分发并按网关要求应答
+func Handler(p xmpay.CallbackProcessor) http.Handler {
+	return xmpay.CallbackHandler(p)
+}
+
+// Mount 在 chi 路由上以 POST 方式挂载回调地址
+func Mount(r chi.Router, pattern string, p xmpay.CallbackProcessor) {
+	r.Method(http.MethodPost, pattern, Handler(p))
+}
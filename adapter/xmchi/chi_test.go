@@ -0,0 +1,21 @@
+package xmchi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/XingMenTech/XMPAY-SDK-GO/adapter/internal/adaptertest"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestMount(t *testing.T) {
+	adaptertest.Run(t, func(p xmpay.CallbackProcessor, req *http.Request) *http.Response {
+		r := chi.NewRouter()
+		Mount(r, adaptertest.Path, p)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Result()
+	})
+}
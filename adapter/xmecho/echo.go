@@ -0,0 +1,12 @@
+// Package xmecho 将 XMPAY 回调处理挂载到 echo
+package xmecho
+
+import (
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/labstack/echo/v4"
+)
+
+// Handler 返回处理 XMPAY 回调的 echo.HandlerFunc，完成解密、校验、分发并按网关要求应答
+func Handler(p xmpay.CallbackProcessor) echo.HandlerFunc {
+	return echo.WrapHandler(xmpay.CallbackHandler(p))
+}
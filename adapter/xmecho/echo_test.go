@@ -0,0 +1,21 @@
+package xmecho
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/XingMenTech/XMPAY-SDK-GO/adapter/internal/adaptertest"
+	"github.com/labstack/echo/v4"
+)
+
+func TestHandler(t *testing.T) {
+	adaptertest.Run(t, func(p xmpay.CallbackProcessor, req *http.Request) *http.Response {
+		e := echo.New()
+		e.POST(adaptertest.Path, Handler(p))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Result()
+	})
+}
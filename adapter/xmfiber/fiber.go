@@ -0,0 +1,24 @@
+// Package xmfiber 将 XMPAY 回调处理挂载到 fiber
+package xmfiber
+
+import (
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler 返回处理 XMPAY 回调的 fiber.Handler，完成解密、校验、分发并按网关要求应答。
+// fiber 基于 fasthttp，请求体已读入内存，直接交给 p.Process 并按 xmpay.CallbackAck 应答
+func Handler(p xmpay.CallbackProcessor) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var err error
+		body := c.Body()
+		if len(body) > xmpay.MaxCallbackBody {
+			err = fiber.ErrRequestEntityTooLarge
+		} else {
+			err = p.Process(body)
+		}
+		code, ack := xmpay.CallbackAck(err)
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+		return c.Status(code).SendString(ack)
+	}
+}
@@ -0,0 +1,22 @@
+package xmfiber
+
+import (
+	"net/http"
+	"testing"
+
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/XingMenTech/XMPAY-SDK-GO/adapter/internal/adaptertest"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestHandler(t *testing.T) {
+	adaptertest.Run(t, func(p xmpay.CallbackProcessor, req *http.Request) *http.Response {
+		app := fiber.New()
+		app.Post(adaptertest.Path, Handler(p))
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	})
+}
@@ -0,0 +1,12 @@
+// Package xmgin 将 XMPAY 回调处理挂载到 gin
+package xmgin
+
+import (
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler 返回处理 XMPAY 回调的 gin.HandlerFunc，完成解密、校验、分发并按网关要求应答
+func Handler(p xmpay.CallbackProcessor) gin.HandlerFunc {
+	return gin.WrapH(xmpay.CallbackHandler(p))
+}
@@ -0,0 +1,22 @@
+package xmgin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/XingMenTech/XMPAY-SDK-GO/adapter/internal/adaptertest"
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	adaptertest.Run(t, func(p xmpay.CallbackProcessor, req *http.Request) *http.Response {
+		r := gin.New()
+		r.POST(adaptertest.Path, Handler(p))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Result()
+	})
+}
@@ -74,9 +74,17 @@ func pKCS7Padding(ciphertext []byte, blockSize int) []byte {
 
 func pKCS7UnPadding(origData []byte) ([]byte, error) {
 	length := len(origData)
+	if length == 0 {
+		return nil, errors.New("invalid padding")
+	}
 	unpadding := int(origData[length-1])
-	if length < unpadding {
-		return origData, errors.New("slice bounds out of range")
+	if unpadding == 0 || unpadding > aes.BlockSize || length < unpadding {
+		return nil, errors.New("invalid padding")
+	}
+	for _, b := range origData[length-unpadding:] {
+		if int(b) != unpadding {
+			return nil, errors.New("invalid padding")
+		}
 	}
 	return origData[:(length - unpadding)], nil
 }
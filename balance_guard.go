@@ -0,0 +1,269 @@
+package xmpay
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrInsufficientBalance 可用余额不足以覆盖付款金额和已预留金额
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+// InsufficientBalanceError 余额不足错误，errors.Is(err, ErrInsufficientBalance) 为 true
+type InsufficientBalanceError struct {
+	Amount    int64 // 本次付款金额
+	Available int64 // 网关可用余额
+	Reserved  int64 // 进行中付款已预留的金额
+}
+
+func (e *InsufficientBalanceError) Error() string {
+	return fmt.Sprintf("insufficient balance: amount %d, available %d, reserved %d", e.Amount, e.Available, e.Reserved)
+}
+
+func (e *InsufficientBalanceError) Is(target error) bool {
+	return target == ErrInsufficientBalance
+}
+
+// BalanceClient 查询商户余额的客户端
+type BalanceClient interface {
+	Balance() (*pb.MerchantBalanceResp, error)
+}
+
+// BalanceOutClient 查询余额并提交付款的客户端，HttpClient 与 GrpcClient 均已实现
+type BalanceOutClient interface {
+	OutClient
+	BalanceClient
+}
+
+type reservation struct {
+	amount     int64
+	createdAt  time.Time
+	acceptedAt time.Time // 网关受理时间，此后发起查询的余额已扣除该金额
+}
+
+// reserveRetries 校验期间有订单失败退回余额时，使用新查询的余额重新校验的最大次数
+const reserveRetries = 3
+
+// BalanceGuard 付款前的余额校验。缓存网关可用余额，并为提交中的付款在本地预留金额，
+// 可用余额减去已预留金额不足时直接拒绝付款。预留保留到查询或回调确认订单终态：
+// 网关受理后查询的余额已扣除该金额，不再重复计入；订单失败或取消时余额退回，余额缓存失效。
+// 网关拒绝付款或订单号未知时立即释放
+type BalanceGuard struct {
+	client BalanceOutClient
+	log    *logrus.Entry
+
+	CacheTTL       time.Duration // 余额缓存时间
+	ReservationTTL time.Duration // 预留最长保留时间，超时未到达终态的付款不再占用余额
+
+	fetch       sync.Mutex
+	mu          sync.Mutex
+	balance     *pb.MerchantBalanceResp
+	fetchedAt   time.Time // 缓存余额的查询发起时间
+	stale       bool      // 有订单失败退回余额，缓存需重新查询
+	invalidated int64     // 余额缓存失效次数
+	reserved    map[string]*reservation
+	seq         atomic.Int64
+}
+
+// NewBalanceGuard 创建余额校验，默认余额缓存 10 秒，预留保留 30 分钟
+func NewBalanceGuard(client BalanceOutClient, log *logrus.Entry) *BalanceGuard {
+	if log == nil {
+		log = logrus.WithField("model", "BalanceGuard")
+	}
+	return &BalanceGuard{
+		client:         client,
+		log:            log,
+		CacheTTL:       10 * time.Second,
+		ReservationTTL: 30 * time.Minute,
+		reserved:       make(map[string]*reservation),
+	}
+}
+
+// Balance 返回缓存的余额，缓存过期或失效时重新查询
+func (g *BalanceGuard) Balance() (*pb.MerchantBalanceResp, error) {
+	if balance := g.cached(); balance != nil {
+		return balance, nil
+	}
+
+	// 并发请求只查询一次
+	g.fetch.Lock()
+	defer g.fetch.Unlock()
+	if balance := g.cached(); balance != nil {
+		return balance, nil
+	}
+	return g.Refresh()
+}
+
+func (g *BalanceGuard) cached() *pb.MerchantBalanceResp {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.balance != nil && !g.stale && time.Since(g.fetchedAt) < g.CacheTTL {
+		return g.balance
+	}
+	return nil
+}
+
+// Refresh 立即查询并缓存余额
+func (g *BalanceGuard) Refresh() (*pb.MerchantBalanceResp, error) {
+	g.mu.Lock()
+	invalidated := g.invalidated
+	g.mu.Unlock()
+
+	start := time.Now()
+	balance, err := g.client.Balance()
+	if err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	g.balance = balance
+	g.fetchedAt = start
+	// 查询期间有订单失败退回余额时，结果可能未包含退回的金额
+	g.stale = g.invalidated != invalidated
+	g.mu.Unlock()
+	return balance, nil
+}
+
+// Reserved 返回进行中付款在当前缓存余额上预留的总金额
+func (g *BalanceGuard) Reserved() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.reservedLocked(time.Now())
+}
+
+// reservedLocked 未体现在缓存余额中的预留金额：未受理的付款，以及受理晚于余额查询的付款
+func (g *BalanceGuard) reservedLocked(now time.Time) int64 {
+	var total int64
+	for orderNo, r := range g.reserved {
+		if g.ReservationTTL > 0 && now.Sub(r.createdAt) > g.ReservationTTL {
+			delete(g.reserved, orderNo)
+			g.log.Warnf("reservation expired, orderNo: %s, amount: %d", orderNo, r.amount)
+			continue
+		}
+		if r.acceptedAt.IsZero() || !g.fetchedAt.After(r.acceptedAt) {
+			total += r.amount
+		}
+	}
+	return total
+}
+
+// reserve 校验余额并预留金额。余额不足且校验期间有订单失败退回余额时，使用新查询的余额重新校验
+func (g *BalanceGuard) reserve(key string, amount int64) error {
+	for attempt := 0; ; attempt++ {
+		g.mu.Lock()
+		invalidated := g.invalidated
+		g.mu.Unlock()
+		if _, err := g.Balance(); err != nil {
+			return err
+		}
+
+		g.mu.Lock()
+		if _, ok := g.reserved[key]; ok {
+			g.mu.Unlock()
+			return nil
+		}
+		now := time.Now()
+		available, reserved := g.balance.Available, g.reservedLocked(now)
+		if reserved+amount > available {
+			retry := g.invalidated != invalidated && attempt < reserveRetries
+			g.mu.Unlock()
+			if retry {
+				continue
+			}
+			return &InsufficientBalanceError{Amount: amount, Available: available, Reserved: reserved}
+		}
+		g.reserved[key] = &reservation{amount: amount, createdAt: now}
+		g.mu.Unlock()
+		return nil
+	}
+}
+
+// Release 释放订单的预留金额，不影响余额缓存
+func (g *BalanceGuard) Release(orderNo string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.reserved, orderNo)
+}
+
+// resolve 订单到达终态时释放预留，失败或取消的订单金额退回可用余额，使余额缓存失效
+func (g *BalanceGuard) resolve(orderNo string, status pb.ORDER_STATUS) {
+	if !isFinalStatus(status) {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.reserved[orderNo]; !ok {
+		return
+	}
+	delete(g.reserved, orderNo)
+	if status != pb.ORDER_STATUS_SUCCESS {
+		g.stale = true
+		g.invalidated++
+	}
+}
+
+// track 将预留改为按实际订单号记录，accepted 为 true 时记录受理时间
+func (g *BalanceGuard) track(key, orderNo string, accepted bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	r, ok := g.reserved[key]
+	if !ok {
+		return
+	}
+	if accepted {
+		r.acceptedAt = time.Now()
+	}
+	if key != orderNo {
+		delete(g.reserved, key)
+		g.reserved[orderNo] = r
+	}
+}
+
+// CreateOut 校验余额并预留金额后提交付款，预留保留到 QueryOut 或 HandleCallback 确认终态。
+// 网关拒绝付款时立即释放；传输错误时结果未知，已知订单号的预留保留到查询或回调确认，
+// 订单号未知时无法确认结果，直接释放
+func (g *BalanceGuard) CreateOut(param *OutParam) (*pb.OutResp, error) {
+	key := param.OrderNo
+	if key == "" {
+		key = "pending-" + strconv.FormatInt(g.seq.Add(1), 10)
+	}
+	if err := g.reserve(key, param.Amount); err != nil {
+		return nil, err
+	}
+
+	resp, err := g.client.CreateOut(param)
+	// 未指定订单号时客户端自动生成并写回参数
+	orderNo := param.OrderNo
+	if orderNo == "" && resp != nil {
+		orderNo = resp.MerchantNo
+	}
+	switch {
+	case orderNo == "" || (err != nil && !IsTransportError(err)):
+		g.Release(key)
+	default:
+		g.track(key, orderNo, err == nil)
+	}
+	return resp, err
+}
+
+// QueryOut 查询付款订单，到达终态时释放预留
+func (g *BalanceGuard) QueryOut(orderNo, trxNo string) (*pb.OrderQueryResp, error) {
+	resp, err := g.client.QueryOut(orderNo, trxNo)
+	if err == nil && resp != nil {
+		g.resolve(resp.MerchantNo, resp.Status)
+	}
+	return resp, err
+}
+
+// HandleCallback 付款回调到达终态时释放预留，可注册到 CallbackDispatcher
+func (g *BalanceGuard) HandleCallback(cb *pb.CallbackParam) error {
+	g.resolve(cb.MerchantNo, cb.Status)
+	return nil
+}
+
+var _ OutClient = (*BalanceGuard)(nil)
@@ -0,0 +1,166 @@
+package xmpay
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+// balanceGateway 受理付款时从可用余额中扣除金额
+type balanceGateway struct {
+	available int64
+	err       error
+	fetches   int
+	onBalance func()
+}
+
+func (g *balanceGateway) Balance() (*pb.MerchantBalanceResp, error) {
+	g.fetches++
+	if g.onBalance != nil {
+		g.onBalance()
+	}
+	return &pb.MerchantBalanceResp{Available: g.available}, nil
+}
+
+func (g *balanceGateway) CreateOut(param *OutParam) (*pb.OutResp, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	g.available -= param.Amount
+	return &pb.OutResp{OrderNo: "T-" + param.OrderNo, MerchantNo: param.OrderNo}, nil
+}
+
+func (g *balanceGateway) QueryOut(orderNo, trxNo string) (*pb.OrderQueryResp, error) {
+	return &pb.OrderQueryResp{MerchantNo: orderNo, Status: pb.ORDER_STATUS_SUCCESS}, nil
+}
+
+func createOut(guard *BalanceGuard, orderNo string, amount int64) error {
+	_, err := guard.CreateOut(&OutParam{OrderParam: OrderParam{OrderNo: orderNo, Amount: amount}})
+	return err
+}
+
+func TestBalanceGuardHoldUntilFinal(t *testing.T) {
+	gateway := &balanceGateway{available: 100}
+	guard := NewBalanceGuard(gateway, testLogger())
+	guard.CacheTTL = time.Hour
+
+	// 受理后预留保留到终态，余额缓存不因受理失效
+	for _, orderNo := range []string{"M1", "M2"} {
+		if err := createOut(guard, orderNo, 40); err != nil {
+			t.Fatalf("%s: %v", orderNo, err)
+		}
+	}
+	if reserved := guard.Reserved(); reserved != 80 {
+		t.Errorf("reserved = %d, want 80", reserved)
+	}
+	err := createOut(guard, "M3", 40)
+	var insufficient *InsufficientBalanceError
+	if !errors.As(err, &insufficient) || insufficient.Available != 100 || insufficient.Reserved != 80 {
+		t.Errorf("err = %v, want insufficient with reserved 80", err)
+	}
+	if gateway.fetches != 1 {
+		t.Errorf("fetches = %d, want 1", gateway.fetches)
+	}
+
+	// 成功回调释放预留，余额缓存仍有效
+	_ = guard.HandleCallback(&pb.CallbackParam{MerchantNo: "M1", Status: pb.ORDER_STATUS_PROCESSING})
+	if reserved := guard.Reserved(); reserved != 80 {
+		t.Errorf("reserved after processing = %d, want 80", reserved)
+	}
+	_ = guard.HandleCallback(&pb.CallbackParam{MerchantNo: "M1", Status: pb.ORDER_STATUS_SUCCESS})
+	if reserved := guard.Reserved(); reserved != 40 {
+		t.Errorf("reserved after success = %d, want 40", reserved)
+	}
+	if _, _ = guard.Balance(); gateway.fetches != 1 {
+		t.Errorf("fetches = %d, want 1", gateway.fetches)
+	}
+
+	// 失败回调退回余额，缓存失效
+	gateway.available += 40
+	_ = guard.HandleCallback(&pb.CallbackParam{MerchantNo: "M2", Status: pb.ORDER_STATUS_FAILURE})
+	if balance, _ := guard.Balance(); gateway.fetches != 2 || balance.Available != 60 {
+		t.Errorf("fetches = %d, available = %d, want 2, 60", gateway.fetches, balance.Available)
+	}
+}
+
+func TestBalanceGuardAcceptedInBalance(t *testing.T) {
+	gateway := &balanceGateway{available: 100}
+	guard := NewBalanceGuard(gateway, testLogger())
+	guard.CacheTTL = time.Hour
+
+	if err := createOut(guard, "M1", 40); err != nil {
+		t.Fatal(err)
+	}
+	// 受理后查询的余额已扣除该金额，不重复计入预留
+	if _, err := guard.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if reserved := guard.Reserved(); reserved != 0 {
+		t.Errorf("reserved = %d, want 0", reserved)
+	}
+	if err := createOut(guard, "M2", 60); err != nil {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestBalanceGuardReserveRetries(t *testing.T) {
+	gateway := &balanceGateway{}
+	guard := NewBalanceGuard(gateway, testLogger())
+	for i := 0; i < 10; i++ {
+		guard.reserved[fmt.Sprintf("F%d", i)] = &reservation{createdAt: time.Now()}
+	}
+	// 每次查询期间都有订单失败，重新校验次数有限
+	n := 0
+	gateway.onBalance = func() {
+		guard.resolve(fmt.Sprintf("F%d", n), pb.ORDER_STATUS_FAILURE)
+		n++
+	}
+	if err := createOut(guard, "M1", 40); !errors.Is(err, ErrInsufficientBalance) {
+		t.Fatalf("err = %v", err)
+	}
+	if gateway.fetches != reserveRetries+1 {
+		t.Errorf("fetches = %d, want %d", gateway.fetches, reserveRetries+1)
+	}
+}
+
+func TestBalanceGuardTransportError(t *testing.T) {
+	gateway := &balanceGateway{available: 100, err: httpError(errors.New("eof"))}
+	guard := NewBalanceGuard(gateway, testLogger())
+
+	// 订单号已知时保留预留，查询到终态后释放
+	if _, err := guard.CreateOut(&OutParam{OrderParam: OrderParam{OrderNo: "M1", Amount: 40}}); !IsTransportError(err) {
+		t.Fatalf("err = %v", err)
+	}
+	if reserved := guard.Reserved(); reserved != 40 {
+		t.Fatalf("reserved = %d, want 40", reserved)
+	}
+	if _, err := guard.QueryOut("M1", ""); err != nil {
+		t.Fatal(err)
+	}
+	if reserved := guard.Reserved(); reserved != 0 {
+		t.Errorf("reserved after final query = %d, want 0", reserved)
+	}
+
+	// 订单号未知时无法确认结果，直接释放
+	if _, err := guard.CreateOut(&OutParam{OrderParam: OrderParam{Amount: 40}}); !IsTransportError(err) {
+		t.Fatalf("err = %v", err)
+	}
+	if reserved := guard.Reserved(); reserved != 0 {
+		t.Errorf("pending reservation kept: %d", reserved)
+	}
+}
+
+func TestBalanceGuardApiError(t *testing.T) {
+	gateway := &balanceGateway{available: 100, err: apiError(http.StatusBadRequest, "rejected")}
+	guard := NewBalanceGuard(gateway, testLogger())
+	if _, err := guard.CreateOut(&OutParam{OrderParam: OrderParam{OrderNo: "M1", Amount: 40}}); !IsApiError(err) {
+		t.Fatalf("err = %v", err)
+	}
+	if reserved := guard.Reserved(); reserved != 0 {
+		t.Errorf("reserved = %d, want 0", reserved)
+	}
+}
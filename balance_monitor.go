@@ -0,0 +1,219 @@
+package xmpay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 余额告警类型
+const (
+	AlertLowAvailable    = "low_available"    // 可用余额低于阈值
+	AlertAvailableDrop   = "available_drop"   // 可用余额在窗口内下降超过比例
+	AlertSettlementStuck = "settlement_stuck" // 待结算余额长时间未变化
+)
+
+// BalanceSample 余额采样
+type BalanceSample struct {
+	Total      int64     `json:"total"`
+	Available  int64     `json:"available"`
+	Settlement int64     `json:"settlement"`
+	Time       time.Time `json:"time"`
+}
+
+// BalanceAlert 余额告警
+type BalanceAlert struct {
+	Kind    string        `json:"kind"`
+	Message string        `json:"message"`
+	Sample  BalanceSample `json:"sample"`
+}
+
+// BalanceAlertHook 告警回调，返回的错误仅记录日志
+type BalanceAlertHook func(alert *BalanceAlert) error
+
+// BalanceMonitor 定期查询商户余额并记录历史，满足告警条件时调用已注册的回调。
+// 同一类告警在条件解除前只触发一次
+type BalanceMonitor struct {
+	client BalanceClient
+	log    *logrus.Entry
+
+	Interval        time.Duration // 查询间隔
+	History         int           // 保留的采样数量
+	MinAvailable    int64         // 可用余额低于该值时告警，0 表示不检查
+	DropPercent     float64       // 可用余额在 DropWindow 内下降超过该百分比时告警，0 表示不检查
+	DropWindow      time.Duration
+	SettlementStuck time.Duration // 待结算余额大于 0 且持续该时长未变化时告警，0 表示不检查
+
+	mu      sync.Mutex
+	samples []BalanceSample
+	hooks   []BalanceAlertHook
+	firing  map[string]bool
+}
+
+// NewBalanceMonitor 创建余额监控，默认每分钟查询一次，保留 24 小时的采样
+func NewBalanceMonitor(client BalanceClient, log *logrus.Entry) *BalanceMonitor {
+	if log == nil {
+		log = logrus.WithField("model", "BalanceMonitor")
+	}
+	return &BalanceMonitor{
+		client:     client,
+		log:        log,
+		Interval:   time.Minute,
+		History:    24 * 60,
+		DropWindow: time.Hour,
+		firing:     make(map[string]bool),
+	}
+}
+
+// OnAlert 注册告警回调
+func (m *BalanceMonitor) OnAlert(hook BalanceAlertHook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook)
+}
+
+// Samples 返回余额采样历史，按时间升序
+func (m *BalanceMonitor) Samples() []BalanceSample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]BalanceSample(nil), m.samples...)
+}
+
+// Run 循环查询余额直到 ctx 结束
+func (m *BalanceMonitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+	for {
+		if err := m.Poll(); err != nil {
+			m.log.Errorf("balance poll failed, err: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll 查询一次余额并检查告警条件
+func (m *BalanceMonitor) Poll() error {
+	balance, err := m.client.Balance()
+	if err != nil {
+		return err
+	}
+	sample := BalanceSample{
+		Total:      balance.Total,
+		Available:  balance.Available,
+		Settlement: balance.Settlement,
+		Time:       timesOf(m.client).Now(),
+	}
+
+	m.mu.Lock()
+	m.samples = append(m.samples, sample)
+	if m.History > 0 && len(m.samples) > m.History {
+		m.samples = append(m.samples[:0], m.samples[len(m.samples)-m.History:]...)
+	}
+	alerts := m.check(sample)
+	hooks := append([]BalanceAlertHook(nil), m.hooks...)
+	m.mu.Unlock()
+
+	for _, alert := range alerts {
+		m.log.Warnf("balance alert %s: %s", alert.Kind, alert.Message)
+		for _, hook := range hooks {
+			if err := hook(alert); err != nil {
+				m.log.Errorf("balance alert hook failed, kind: %s, err: %v", alert.Kind, err)
+			}
+		}
+	}
+	return nil
+}
+
+// check 检查各告警条件，返回新触发的告警
+func (m *BalanceMonitor) check(s BalanceSample) []*BalanceAlert {
+	var alerts []*BalanceAlert
+	trigger := func(kind string, on bool, format string, args ...interface{}) {
+		if on && !m.firing[kind] {
+			alerts = append(alerts, &BalanceAlert{Kind: kind, Message: fmt.Sprintf(format, args...), Sample: s})
+		}
+		m.firing[kind] = on
+	}
+
+	if m.MinAvailable > 0 {
+		trigger(AlertLowAvailable, s.Available < m.MinAvailable,
+			"available %d below threshold %d", s.Available, m.MinAvailable)
+	}
+
+	if m.DropPercent > 0 {
+		// 窗口内的最高可用余额
+		var peak int64
+		for _, p := range m.samples {
+			if s.Time.Sub(p.Time) <= m.DropWindow && p.Available > peak {
+				peak = p.Available
+			}
+		}
+		drop := 0.0
+		if peak > 0 {
+			drop = float64(peak-s.Available) / float64(peak) * 100
+		}
+		trigger(AlertAvailableDrop, drop > m.DropPercent,
+			"available dropped %.1f%% within %s, from %d to %d", drop, m.DropWindow, peak, s.Available)
+	}
+
+	if m.SettlementStuck > 0 {
+		// 待结算余额保持不变的起始时间
+		since := s.Time
+		for i := len(m.samples) - 1; i >= 0 && m.samples[i].Settlement == s.Settlement; i-- {
+			since = m.samples[i].Time
+		}
+		stuck := s.Time.Sub(since)
+		trigger(AlertSettlementStuck, s.Settlement > 0 && stuck >= m.SettlementStuck,
+			"settlement %d unchanged for %s", s.Settlement, stuck.Round(time.Second))
+	}
+	return alerts
+}
+
+// WebhookNotifier 以 JSON 格式将告警 POST 到 Webhook 地址
+type WebhookNotifier struct {
+	URL     string
+	Headers map[string]string
+	Client  *http.Client
+}
+
+// NewWebhookNotifier 创建 Webhook 通知，请求超时 10 秒
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify 发送告警，可作为 BalanceAlertHook 注册
+func (n *WebhookNotifier) Notify(alert *BalanceAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook status: %d", resp.StatusCode)
+	}
+	return nil
+}
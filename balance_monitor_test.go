@@ -0,0 +1,149 @@
+package xmpay
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+// balanceSeq 按顺序返回可用余额和待结算余额的余额客户端
+type balanceSeq struct {
+	balances [][2]int64
+	calls    int
+}
+
+func (b *balanceSeq) Balance() (*pb.MerchantBalanceResp, error) {
+	v := b.balances[min(b.calls, len(b.balances)-1)]
+	b.calls++
+	return &pb.MerchantBalanceResp{Total: v[0] + v[1], Available: v[0], Settlement: v[1]}, nil
+}
+
+// pollAlerts 依次查询 n 次余额，返回每次查询新触发的告警类型
+func pollAlerts(t *testing.T, m *BalanceMonitor, n int) [][]string {
+	t.Helper()
+	var current []string
+	m.OnAlert(func(alert *BalanceAlert) error {
+		current = append(current, alert.Kind)
+		return nil
+	})
+	var result [][]string
+	for i := 0; i < n; i++ {
+		current = nil
+		if err := m.Poll(); err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, current)
+	}
+	return result
+}
+
+func TestBalanceMonitorLowAvailable(t *testing.T) {
+	client := &balanceSeq{balances: [][2]int64{{500, 0}, {90, 0}, {80, 0}, {200, 0}, {50, 0}}}
+	m := NewBalanceMonitor(client, testLogger())
+	m.MinAvailable = 100
+
+	got := pollAlerts(t, m, 5)
+	// 条件持续时只触发一次，恢复后再次低于阈值重新触发
+	want := []int{0, 1, 0, 0, 1}
+	for i, alerts := range got {
+		if len(alerts) != want[i] || (want[i] == 1 && alerts[0] != AlertLowAvailable) {
+			t.Errorf("poll %d: alerts = %v", i, alerts)
+		}
+	}
+}
+
+func TestBalanceMonitorDrop(t *testing.T) {
+	client := &balanceSeq{balances: [][2]int64{{1000, 0}, {900, 0}, {400, 0}, {350, 0}}}
+	m := NewBalanceMonitor(client, testLogger())
+	m.DropPercent = 50
+
+	got := pollAlerts(t, m, 3)
+	if len(got[0]) != 0 || len(got[1]) != 0 || len(got[2]) != 1 || got[2][0] != AlertAvailableDrop {
+		t.Fatalf("alerts = %v", got)
+	}
+
+	// 窗口外的峰值不计入
+	m.mu.Lock()
+	for i := range m.samples {
+		m.samples[i].Time = m.samples[i].Time.Add(-2 * m.DropWindow)
+	}
+	m.mu.Unlock()
+	_ = m.Poll()
+	if m.firing[AlertAvailableDrop] {
+		t.Error("drop alert still firing after peak left the window")
+	}
+}
+
+func TestBalanceMonitorSettlementStuck(t *testing.T) {
+	client := &balanceSeq{balances: [][2]int64{{100, 300}, {100, 300}, {100, 300}, {100, 0}}}
+	m := NewBalanceMonitor(client, testLogger())
+	m.SettlementStuck = time.Hour
+
+	var alerts []*BalanceAlert
+	m.OnAlert(func(alert *BalanceAlert) error {
+		alerts = append(alerts, alert)
+		return nil
+	})
+	_ = m.Poll()
+	_ = m.Poll()
+	if len(alerts) != 0 {
+		t.Fatalf("alerts = %v", alerts)
+	}
+
+	// 待结算余额已持续两小时未变化
+	m.mu.Lock()
+	for i := range m.samples {
+		m.samples[i].Time = m.samples[i].Time.Add(-2 * time.Hour)
+	}
+	m.mu.Unlock()
+	_ = m.Poll()
+	if len(alerts) != 1 || alerts[0].Kind != AlertSettlementStuck || alerts[0].Sample.Settlement != 300 {
+		t.Fatalf("alerts = %+v", alerts)
+	}
+
+	// 待结算余额为 0 时解除
+	_ = m.Poll()
+	if len(alerts) != 1 || m.firing[AlertSettlementStuck] {
+		t.Errorf("alerts = %+v, firing = %v", alerts, m.firing)
+	}
+}
+
+func TestBalanceMonitorHistory(t *testing.T) {
+	client := &balanceSeq{balances: [][2]int64{{1, 0}, {2, 0}, {3, 0}, {4, 0}}}
+	m := NewBalanceMonitor(client, testLogger())
+	m.History = 2
+	pollAlerts(t, m, 4)
+	if samples := m.Samples(); len(samples) != 2 || samples[0].Available != 3 || samples[1].Available != 4 {
+		t.Errorf("samples = %+v", samples)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got BalanceAlert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	n := NewWebhookNotifier(srv.URL)
+	n.Headers = map[string]string{"X-Token": "secret"}
+	if err := n.Notify(&BalanceAlert{Kind: AlertLowAvailable, Sample: BalanceSample{Available: 10}}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Kind != AlertLowAvailable || got.Sample.Available != 10 {
+		t.Errorf("received = %+v", got)
+	}
+
+	n.Headers = nil
+	if err := n.Notify(&BalanceAlert{Kind: AlertLowAvailable}); err == nil {
+		t.Error("non-2xx status accepted")
+	}
+}
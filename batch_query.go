@@ -0,0 +1,174 @@
+package xmpay
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BatchQuery 批量订单查询的 HTTP 路径，同时作为限流的操作名
+const BatchQuery = "/gateway/api/order/batch/query"
+
+// 批量查询默认参数
+const (
+	MaxBatchQuery           = 100 // 单次批量请求的最大订单数，超出时分批请求
+	DefaultBatchQueryFanOut = 8   // 逐个查询时的默认并发数
+)
+
+// BatchQueryRecheck 网关不支持批量接口时逐个查询的时长，之后重新尝试批量接口
+const BatchQueryRecheck = 10 * time.Minute
+
+// ErrBatchOrderType 批量查询只支持收款单或付款单
+var ErrBatchOrderType = errors.New("batch query requires receive or out order type")
+
+// QueryResult 批量查询中单个订单的结果，Err 为空时 Order 为查询结果
+type QueryResult struct {
+	Order *pb.OrderQueryResp
+	Err   error
+}
+
+// BatchQuerier 批量查询订单，HttpClient 与 GrpcClient 均已实现
+type BatchQuerier interface {
+	BatchQuery(orderType pb.ORDER_TYPE, merchantNos []string) (map[string]*QueryResult, error)
+}
+
+var (
+	_ BatchQuerier = (*HttpClient)(nil)
+	_ BatchQuerier = (*GrpcClient)(nil)
+)
+
+// BatchQuery 使用批量查询接口查询订单，结果以商户订单号为键；网关不支持批量接口时改为并发逐个查询，
+// 并在 BatchQueryRecheck 内直接逐个查询
+func (c *GrpcClient) BatchQuery(orderType pb.ORDER_TYPE, merchantNos []string) (map[string]*QueryResult, error) {
+	return c.batchQuery(c, orderType, merchantNos, c.batchQueryOnce)
+}
+
+func (c *GrpcClient) batchQueryOnce(param *pb.BatchQueryParam) (*pb.BatchQueryResp, error) {
+	return call(c, opBatchQuery, param)
+}
+
+// BatchQuery 使用批量查询接口查询订单，结果以商户订单号为键；网关不支持批量接口时改为并发逐个查询，
+// 并在 BatchQueryRecheck 内直接逐个查询
+func (c *HttpClient) BatchQuery(orderType pb.ORDER_TYPE, merchantNos []string) (map[string]*QueryResult, error) {
+	return c.batchQuery(c, orderType, merchantNos, c.batchQueryOnce)
+}
+
+func (c *HttpClient) batchQueryOnce(param *pb.BatchQueryParam) (*pb.BatchQueryResp, error) {
+	return call(c, opBatchQuery, param)
+}
+
+// batchQuery 按 MaxBatchQuery 分批调用 batch，批量接口不可用时使用 single 逐个查询剩余订单
+func (c *PayClientImpl) batchQuery(single QueryClient, orderType pb.ORDER_TYPE, merchantNos []string,
+	batch func(param *pb.BatchQueryParam) (*pb.BatchQueryResp, error)) (map[string]*QueryResult, error) {
+	if orderType != pb.ORDER_TYPE_RECEIVE && orderType != pb.ORDER_TYPE_OUT {
+		return nil, ErrBatchOrderType
+	}
+	merchantNos = uniqueStrings(merchantNos)
+	results := make(map[string]*QueryResult, len(merchantNos))
+
+	for len(merchantNos) > 0 && c.batchSupported() {
+		chunk := merchantNos[:min(len(merchantNos), MaxBatchQuery)]
+		resp, err := batch(&pb.BatchQueryParam{OrderType: orderType, MerchantNos: chunk})
+		if batchUnsupported(err) {
+			c.log.Warnf("batch query not supported, fallback to single queries for %s, err: %v", BatchQueryRecheck, err)
+			c.noBatch.Store(time.Now().UnixNano())
+			break
+		}
+		merchantNos = merchantNos[len(chunk):]
+		if err != nil {
+			for _, merchantNo := range chunk {
+				results[merchantNo] = &QueryResult{Err: err}
+			}
+			continue
+		}
+		for _, item := range resp.GetItems() {
+			if item.Code != http.StatusOK {
+				results[item.MerchantNo] = &QueryResult{Err: apiError(item.Code, item.Message)}
+				continue
+			}
+			results[item.MerchantNo] = &QueryResult{Order: item.Order}
+			c.recordOrder(orderFromQuery(c.times, orderType, item.Order), OrderSourceQuery)
+		}
+		for _, merchantNo := range chunk {
+			if results[merchantNo] == nil {
+				results[merchantNo] = &QueryResult{Err: ErrOrderNotFound}
+			}
+		}
+	}
+
+	for merchantNo, r := range FanOutQuery(single, orderType, merchantNos, c.batchFanOut) {
+		results[merchantNo] = r
+	}
+	return results, nil
+}
+
+// batchSupported 上次发现网关不支持批量接口超过 BatchQueryRecheck 后重新尝试，网关升级后可恢复批量查询
+func (c *PayClientImpl) batchSupported() bool {
+	since := c.noBatch.Load()
+	return since == 0 || time.Since(time.Unix(0, since)) >= BatchQueryRecheck
+}
+
+// batchUnsupported 网关是否未提供批量查询接口
+func batchUnsupported(err error) bool {
+	if status.Code(err) == codes.Unimplemented {
+		return true
+	}
+	var e *StatusError
+	if errors.As(err, &e) {
+		return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusMethodNotAllowed ||
+			e.StatusCode == http.StatusNotImplemented
+	}
+	return false
+}
+
+// FanOutQuery 使用 client 并发逐个查询订单，concurrency 小于等于 0 时使用 DefaultBatchQueryFanOut
+func FanOutQuery(client QueryClient, orderType pb.ORDER_TYPE, merchantNos []string, concurrency int) map[string]*QueryResult {
+	if concurrency <= 0 {
+		concurrency = DefaultBatchQueryFanOut
+	}
+	query := client.QueryReceive
+	if orderType == pb.ORDER_TYPE_OUT {
+		query = client.QueryOut
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, concurrency)
+		results = make(map[string]*QueryResult, len(merchantNos))
+	)
+	for _, merchantNo := range uniqueStrings(merchantNos) {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(merchantNo string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			order, err := query(merchantNo, "")
+			mu.Lock()
+			results[merchantNo] = &QueryResult{Order: order, Err: err}
+			mu.Unlock()
+		}(merchantNo)
+	}
+	wg.Wait()
+	return results
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
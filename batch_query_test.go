@@ -0,0 +1,77 @@
+package xmpay
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// singleQuery 逐个查询的桩，记录查询次数
+type singleQuery struct{ calls atomic.Int64 }
+
+func (q *singleQuery) QueryReceive(orderNo, trxNo string) (*pb.OrderQueryResp, error) {
+	q.calls.Add(1)
+	return &pb.OrderQueryResp{MerchantNo: orderNo, Status: pb.ORDER_STATUS_SUCCESS}, nil
+}
+
+func (q *singleQuery) QueryOut(orderNo, trxNo string) (*pb.OrderQueryResp, error) {
+	return q.QueryReceive(orderNo, trxNo)
+}
+
+func TestBatchQueryRecheck(t *testing.T) {
+	c := NewHttpClient(testConfig("http://127.0.0.1:1"), testLogger())
+	single := &singleQuery{}
+	supported := false
+	batches := 0
+	batch := func(param *pb.BatchQueryParam) (*pb.BatchQueryResp, error) {
+		batches++
+		if !supported {
+			return nil, grpcError(status.Error(codes.Unimplemented, "method BatchQuery not implemented"))
+		}
+		resp := &pb.BatchQueryResp{}
+		for _, no := range param.MerchantNos {
+			resp.Items = append(resp.Items, &pb.BatchQueryItem{MerchantNo: no, Code: http.StatusOK,
+				Order: &pb.OrderQueryResp{MerchantNo: no, Status: pb.ORDER_STATUS_SUCCESS}})
+		}
+		return resp, nil
+	}
+	query := func() {
+		t.Helper()
+		results, err := c.batchQuery(single, pb.ORDER_TYPE_RECEIVE, []string{"M1", "M2"}, batch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, no := range []string{"M1", "M2"} {
+			if r := results[no]; r == nil || r.Err != nil {
+				t.Fatalf("%s: result = %+v", no, r)
+			}
+		}
+	}
+
+	query()
+	if batches != 1 || single.calls.Load() != 2 {
+		t.Fatalf("batches = %d, single = %d, want 1, 2", batches, single.calls.Load())
+	}
+	// 冷却期内不再尝试批量接口
+	query()
+	if batches != 1 || single.calls.Load() != 4 {
+		t.Fatalf("batches = %d, single = %d, want 1, 4", batches, single.calls.Load())
+	}
+
+	// 冷却期过后网关已支持批量接口，恢复批量查询
+	supported = true
+	c.noBatch.Store(time.Now().Add(-BatchQueryRecheck).UnixNano())
+	query()
+	if batches != 2 || single.calls.Load() != 4 {
+		t.Fatalf("batches = %d, single = %d, want 2, 4", batches, single.calls.Load())
+	}
+	query()
+	if batches != 3 {
+		t.Errorf("batches = %d, want 3", batches)
+	}
+}
@@ -0,0 +1,40 @@
+package xmpay
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+var (
+	ErrCallbackAppKey = errors.New("callback app key mismatch")
+	ErrCallbackData   = errors.New("callback data invalid")
+)
+
+// ParseCallback 解析网关回调请求体（pb.PayRpcParam），校验 app_key 并解密回调参数
+func (c *PayClientImpl) ParseCallback(body []byte) (*pb.CallbackParam, error) {
+	var param pb.PayRpcParam
+	if err := json.Unmarshal(body, &param); err != nil {
+		return nil, err
+	}
+	if param.AppKey == "" {
+		return nil, ErrCallbackAppKey
+	}
+
+	data, _, err := c.decryptWith(c.keys.Load(), param.AppKey, []byte(param.Data))
+	if err != nil {
+		return nil, err
+	}
+	c.log.Debug("回调数据：", string(data))
+
+	var cb pb.CallbackParam
+	if err = json.Unmarshal(data, &cb); err != nil {
+		return nil, err
+	}
+	if cb.MerchantNo == "" {
+		return nil, ErrCallbackData
+	}
+	c.recordOrder(orderFromCallback(c.times, &cb), OrderSourceCallback)
+	return &cb, nil
+}
@@ -0,0 +1,254 @@
+package xmpay
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"github.com/sirupsen/logrus"
+)
+
+// 回调应答内容，网关收到 success 后停止重试
+const (
+	CallbackSuccess = "success"
+	CallbackFail    = "fail"
+)
+
+// MaxCallbackBody 回调请求体最大长度
+const MaxCallbackBody = 1 << 20
+
+// CallbackParser 解析回调请求体，HttpClient 与 GrpcClient 均已实现
+type CallbackParser interface {
+	ParseCallback(body []byte) (*pb.CallbackParam, error)
+}
+
+// CallbackProcessor 处理回调请求体，CallbackDispatcher 已实现，供各 HTTP 框架适配器使用
+type CallbackProcessor interface {
+	Process(body []byte) error
+}
+
+// CallbackHandlerFunc 业务回调处理函数，返回错误时应答失败，网关将重新推送
+type CallbackHandlerFunc func(cb *pb.CallbackParam) error
+
+// CallbackStore 回调去重与订单状态存储，多实例部署时应使用共享存储
+type CallbackStore interface {
+	// Claim 原子地检查并记录回调：回调未处理过且推进了订单状态时记录回调与新状态并返回 true；
+	// 重复或过期的回调只记录回调，返回 false。多个实例同时领取同一回调时只有一个返回 true
+	Claim(key, merchantNo string, status pb.ORDER_STATUS) (advanced bool, err error)
+	// Release 撤销 Claim 返回 true 的记录，业务处理失败时调用，网关重试的回调可再次被领取
+	Release(key, merchantNo string, status pb.ORDER_STATUS) error
+}
+
+// MemoryCallbackStore 基于内存的回调存储，记录保留 TTL 后清理
+type MemoryCallbackStore struct {
+	TTL time.Duration // 回调与订单状态的保留时间，0 表示永久保留
+
+	mu     sync.Mutex
+	seen   map[string]time.Time
+	status map[string]callbackStatus
+	claims map[string]callbackStatus // 已领取回调推进前的订单状态，用于 Release
+	pruned time.Time
+}
+
+type callbackStatus struct {
+	status pb.ORDER_STATUS
+	ok     bool
+	at     time.Time
+}
+
+// NewMemoryCallbackStore 创建内存回调存储，记录默认保留 24 小时
+func NewMemoryCallbackStore() *MemoryCallbackStore {
+	return &MemoryCallbackStore{
+		TTL:    24 * time.Hour,
+		seen:   make(map[string]time.Time),
+		status: make(map[string]callbackStatus),
+		claims: make(map[string]callbackStatus),
+	}
+}
+
+func (s *MemoryCallbackStore) Claim(key, merchantNo string, status pb.ORDER_STATUS) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.prune(now)
+
+	if _, ok := s.seen[key]; ok {
+		return false, nil
+	}
+	s.seen[key] = now
+	last := s.status[merchantNo]
+	if last.ok && statusRank(status) <= statusRank(last.status) {
+		return false, nil
+	}
+	s.status[merchantNo] = callbackStatus{status: status, ok: true, at: now}
+	s.claims[key] = last
+	return true, nil
+}
+
+func (s *MemoryCallbackStore) Release(key, merchantNo string, status pb.ORDER_STATUS) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, ok := s.claims[key]
+	if !ok {
+		return nil
+	}
+	delete(s.claims, key)
+	delete(s.seen, key)
+	// 期间已有其他回调推进状态时保留其结果
+	if cur := s.status[merchantNo]; cur.ok && cur.status == status {
+		if prev.ok {
+			s.status[merchantNo] = prev
+		} else {
+			delete(s.status, merchantNo)
+		}
+	}
+	return nil
+}
+
+// prune 清理超过 TTL 的记录，最多每 TTL/10 执行一次
+func (s *MemoryCallbackStore) prune(now time.Time) {
+	if s.TTL <= 0 || now.Sub(s.pruned) < s.TTL/10 {
+		return
+	}
+	s.pruned = now
+	for key, at := range s.seen {
+		if now.Sub(at) > s.TTL {
+			delete(s.seen, key)
+			delete(s.claims, key)
+		}
+	}
+	for merchantNo, st := range s.status {
+		if now.Sub(st.at) > s.TTL {
+			delete(s.status, merchantNo)
+		}
+	}
+}
+
+// statusRank 订单状态先后顺序，终态之间不再流转
+func statusRank(status pb.ORDER_STATUS) int {
+	switch status {
+	case pb.ORDER_STATUS_WAIT:
+		return 0
+	case pb.ORDER_STATUS_PROCESSING, pb.ORDER_STATUS_REFUNDING:
+		return 1
+	case pb.ORDER_STATUS_ABNORMAL:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// callbackOrderNo 回调对应的订单，退款回调为退款单号
+func callbackOrderNo(cb *pb.CallbackParam) string {
+	if cb.RefundNo != "" {
+		return cb.RefundNo
+	}
+	return cb.MerchantNo
+}
+
+// callbackKey 回调去重键
+func callbackKey(cb *pb.CallbackParam) string {
+	return fmt.Sprintf("%s|%d|%d", callbackOrderNo(cb), cb.Status, cb.FinishTime)
+}
+
+// CallbackDispatcher 回调分发器
+//
+// 按 (merchant_no, status, finish_time) 去重，退款回调以 refund_no 代替 merchant_no，同一订单的回调串行处理，
+// 只有推进订单状态的回调才会转发给业务处理函数，重复或过期的回调直接应答成功
+type CallbackDispatcher struct {
+	parser   CallbackParser
+	store    CallbackStore
+	log      *logrus.Entry
+	locks    keyedMutex
+	mu       sync.RWMutex
+	handlers []CallbackHandlerFunc
+}
+
+// NewCallbackDispatcher 创建回调分发器，store 为空时使用内存存储
+func NewCallbackDispatcher(parser CallbackParser, store CallbackStore, log *logrus.Entry) *CallbackDispatcher {
+	if store == nil {
+		store = NewMemoryCallbackStore()
+	}
+	if log == nil {
+		log = logrus.WithField("model", "CallbackDispatcher")
+	}
+	return &CallbackDispatcher{parser: parser, store: store, log: log}
+}
+
+// Handle 注册业务回调处理函数，按注册顺序依次调用；任一函数失败时整条回调重试，处理函数需保证幂等
+func (d *CallbackDispatcher) Handle(fn CallbackHandlerFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers = append(d.handlers, fn)
+}
+
+// Process 解析并分发回调请求体
+func (d *CallbackDispatcher) Process(body []byte) error {
+	cb, err := d.parser.ParseCallback(body)
+	if err != nil {
+		d.log.Errorf("callback parse failed, err: %v", err)
+		return err
+	}
+	return d.Dispatch(cb)
+}
+
+// Dispatch 分发已解析的回调
+func (d *CallbackDispatcher) Dispatch(cb *pb.CallbackParam) error {
+	orderNo := callbackOrderNo(cb)
+	unlock := d.locks.lock(orderNo)
+	defer unlock()
+
+	key := callbackKey(cb)
+	advanced, err := d.store.Claim(key, orderNo, cb.Status)
+	if err != nil {
+		return err
+	}
+	if !advanced {
+		d.log.Debugf("callback duplicate or stale: %s", key)
+		return nil
+	}
+
+	d.mu.RLock()
+	handlers := d.handlers
+	d.mu.RUnlock()
+	for _, fn := range handlers {
+		if err = fn(cb); err != nil {
+			d.log.Errorf("callback handle failed, merchantNo: %s, err: %v", orderNo, err)
+			if rerr := d.store.Release(key, orderNo, cb.Status); rerr != nil {
+				d.log.Errorf("callback release failed: %s, err: %v", key, rerr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// ServeHTTP 作为回调地址的 http.Handler
+func (d *CallbackDispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	CallbackHandler(d).ServeHTTP(w, r)
+}
+
+// CallbackHandler 返回处理回调的 http.Handler：读取请求体交给 p.Process，按 CallbackAck 应答
+func CallbackHandler(p CallbackProcessor) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, MaxCallbackBody))
+		if err == nil {
+			err = p.Process(body)
+		}
+		code, ack := CallbackAck(err)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(ack))
+	})
+}
+
+// CallbackAck 根据处理结果返回应答的 HTTP 状态码和内容
+func CallbackAck(err error) (int, string) {
+	if err != nil {
+		return http.StatusInternalServerError, CallbackFail
+	}
+	return http.StatusOK, CallbackSuccess
+}
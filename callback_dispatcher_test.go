@@ -0,0 +1,132 @@
+package xmpay
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+func TestCallbackDispatch(t *testing.T) {
+	d := NewCallbackDispatcher(nil, nil, testLogger())
+	var got []pb.ORDER_STATUS
+	d.Handle(func(cb *pb.CallbackParam) error {
+		got = append(got, cb.Status)
+		return nil
+	})
+
+	for _, cb := range []*pb.CallbackParam{
+		{MerchantNo: "M1", Status: pb.ORDER_STATUS_PROCESSING, FinishTime: 1},
+		{MerchantNo: "M1", Status: pb.ORDER_STATUS_PROCESSING, FinishTime: 1}, // 重复
+		{MerchantNo: "M1", Status: pb.ORDER_STATUS_SUCCESS, FinishTime: 2},
+		{MerchantNo: "M1", Status: pb.ORDER_STATUS_PROCESSING, FinishTime: 3}, // 状态回退
+	} {
+		if err := d.Dispatch(cb); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(got) != 2 || got[0] != pb.ORDER_STATUS_PROCESSING || got[1] != pb.ORDER_STATUS_SUCCESS {
+		t.Errorf("dispatched = %v", got)
+	}
+}
+
+func TestCallbackDispatchRetryAfterFailure(t *testing.T) {
+	d := NewCallbackDispatcher(nil, nil, testLogger())
+	fail := errors.New("handler failed")
+	calls := 0
+	d.Handle(func(cb *pb.CallbackParam) error {
+		if calls++; calls == 1 {
+			return fail
+		}
+		return nil
+	})
+
+	cb := &pb.CallbackParam{MerchantNo: "M1", Status: pb.ORDER_STATUS_SUCCESS, FinishTime: 1}
+	if err := d.Dispatch(cb); !errors.Is(err, fail) {
+		t.Fatalf("err = %v", err)
+	}
+	// 处理失败后重试的回调再次转发
+	if err := d.Dispatch(cb); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Dispatch(cb); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestMemoryCallbackStoreClaimOnce(t *testing.T) {
+	// 多个分发器共用同一存储，模拟多实例同时收到同一回调
+	store := NewMemoryCallbackStore()
+	var handled atomic.Int32
+	cb := &pb.CallbackParam{MerchantNo: "M1", Status: pb.ORDER_STATUS_SUCCESS, FinishTime: 1}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		d := NewCallbackDispatcher(nil, store, testLogger())
+		d.Handle(func(*pb.CallbackParam) error {
+			handled.Add(1)
+			return nil
+		})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := d.Dispatch(cb); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := handled.Load(); n != 1 {
+		t.Errorf("handled %d times, want 1", n)
+	}
+}
+
+func TestMemoryCallbackStoreRelease(t *testing.T) {
+	store := NewMemoryCallbackStore()
+	if ok, _ := store.Claim("k1", "M1", pb.ORDER_STATUS_PROCESSING); !ok {
+		t.Fatal("first claim rejected")
+	}
+	if ok, _ := store.Claim("k2", "M1", pb.ORDER_STATUS_SUCCESS); !ok {
+		t.Fatal("advancing claim rejected")
+	}
+	if err := store.Release("k2", "M1", pb.ORDER_STATUS_SUCCESS); err != nil {
+		t.Fatal(err)
+	}
+	// 撤销后恢复到 PROCESSING，SUCCESS 回调可再次领取，PROCESSING 仍为过期
+	if ok, _ := store.Claim("k3", "M1", pb.ORDER_STATUS_PROCESSING); ok {
+		t.Error("stale claim accepted after release")
+	}
+	if ok, _ := store.Claim("k2", "M1", pb.ORDER_STATUS_SUCCESS); !ok {
+		t.Error("released claim rejected")
+	}
+}
+
+func TestMemoryCallbackStoreTTL(t *testing.T) {
+	store := NewMemoryCallbackStore()
+	store.TTL = time.Hour
+	if ok, _ := store.Claim("k1", "M1", pb.ORDER_STATUS_SUCCESS); !ok {
+		t.Fatal("claim rejected")
+	}
+
+	store.mu.Lock()
+	expired := time.Now().Add(-2 * time.Hour)
+	store.seen["k1"] = expired
+	store.status["M1"] = callbackStatus{status: pb.ORDER_STATUS_SUCCESS, ok: true, at: expired}
+	store.pruned = time.Time{}
+	store.mu.Unlock()
+
+	if _, err := store.Claim("k2", "M2", pb.ORDER_STATUS_SUCCESS); err != nil {
+		t.Fatal(err)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.seen) != 1 || len(store.status) != 1 || len(store.claims) != 1 {
+		t.Errorf("expired records kept: seen %d, status %d, claims %d", len(store.seen), len(store.status), len(store.claims))
+	}
+}
@@ -0,0 +1,307 @@
+package xmpay
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrCircuitOpen 熔断器处于打开状态，请求未发送
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError 熔断错误，errors.Is(err, ErrCircuitOpen) 为 true
+type CircuitOpenError struct {
+	Key   string    // 网关操作或 操作#通道ID
+	Until time.Time // 预计进入半开状态的时间
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker is open: %s", e.Key)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// CircuitState 熔断器状态
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+type circuit struct {
+	state      CircuitState
+	generation uint64 // 每次状态变化加一，Allow 时记录，用于丢弃旧状态下放行的请求结果
+	failures   int
+	successes  int
+	probes     int
+	openUntil  time.Time
+}
+
+// CircuitBreaker 按 key 维护的熔断器。连续失败达到阈值后打开，
+// 打开期间直接返回 CircuitOpenError，超时后进入半开状态放行少量探测请求，探测成功后关闭
+type CircuitBreaker struct {
+	FailureThreshold int           // 连续失败次数达到后打开
+	OpenTimeout      time.Duration // 打开状态持续时间
+	HalfOpenRequests int           // 半开状态允许的并发探测请求数，全部成功后关闭
+
+	log      *logrus.Entry
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// NewCircuitBreaker 创建熔断器，默认连续失败 5 次打开 30 秒，半开时放行 1 个请求
+func NewCircuitBreaker(log *logrus.Entry) *CircuitBreaker {
+	if log == nil {
+		log = logrus.WithField("model", "CircuitBreaker")
+	}
+	return &CircuitBreaker{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+		HalfOpenRequests: 1,
+		log:              log,
+		circuits:         make(map[string]*circuit),
+	}
+}
+
+// Allow 检查 key 是否允许请求，允许时返回当前状态的代数，调用方须在请求结束后以该代数调用 Report
+func (b *CircuitBreaker) Allow(key string) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.get(key)
+	switch c.state {
+	case CircuitOpen:
+		if time.Now().Before(c.openUntil) {
+			return 0, &CircuitOpenError{Key: key, Until: c.openUntil}
+		}
+		b.transition(key, c, CircuitHalfOpen)
+		fallthrough
+	case CircuitHalfOpen:
+		if c.probes >= b.halfOpenRequests() {
+			return 0, &CircuitOpenError{Key: key, Until: c.openUntil}
+		}
+		c.probes++
+	}
+	return c.generation, nil
+}
+
+// Report 记录请求结果，failed 为 true 时计入失败。generation 为 Allow 返回的代数，
+// 状态已变化时忽略，避免关闭状态下放行的请求被当作半开探测的结果
+func (b *CircuitBreaker) Report(key string, generation uint64, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.get(key)
+	if c.generation != generation {
+		return
+	}
+	switch c.state {
+	case CircuitHalfOpen:
+		if c.probes > 0 {
+			c.probes--
+		}
+		if failed {
+			b.open(key, c)
+			return
+		}
+		c.successes++
+		if c.successes >= b.halfOpenRequests() {
+			b.transition(key, c, CircuitClosed)
+		}
+	case CircuitClosed:
+		if !failed {
+			c.failures = 0
+			return
+		}
+		c.failures++
+		if b.FailureThreshold > 0 && c.failures >= b.FailureThreshold {
+			b.open(key, c)
+		}
+	}
+}
+
+// release 归还 Allow 占用的半开探测名额，不记录结果
+func (b *CircuitBreaker) release(key string, generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c := b.get(key); c.generation == generation && c.state == CircuitHalfOpen && c.probes > 0 {
+		c.probes--
+	}
+}
+
+// State 返回 key 当前的状态
+func (b *CircuitBreaker) State(key string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[key]
+	if !ok {
+		return CircuitClosed
+	}
+	if c.state == CircuitOpen && !time.Now().Before(c.openUntil) {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+func (b *CircuitBreaker) get(key string) *circuit {
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+	return c
+}
+
+func (b *CircuitBreaker) open(key string, c *circuit) {
+	c.openUntil = time.Now().Add(b.OpenTimeout)
+	b.transition(key, c, CircuitOpen)
+}
+
+func (b *CircuitBreaker) transition(key string, c *circuit, state CircuitState) {
+	b.log.Warnf("circuit %s: %s -> %s", key, c.state, state)
+	c.state = state
+	c.generation++
+	c.failures = 0
+	c.successes = 0
+	c.probes = 0
+}
+
+func (b *CircuitBreaker) halfOpenRequests() int {
+	if b.HalfOpenRequests <= 0 {
+		return 1
+	}
+	return b.HalfOpenRequests
+}
+
+// CircuitKey 通道熔断 key，由网关操作和通道ID组成，通道ID为 0 表示配置中的默认通道
+func CircuitKey(operation string, pid int32) string {
+	return operation + "#" + strconv.Itoa(int(pid))
+}
+
+// CircuitBreakerClient 带熔断的客户端。网关操作按传输层错误熔断，
+// 下单操作另按通道ID熔断，计入通道失败的错误由 IsFailure 判断
+type CircuitBreakerClient struct {
+	client  PayClient
+	breaker *CircuitBreaker
+
+	// IsFailure 判断错误是否计入通道失败，默认为传输层错误或网关 5xx 业务错误
+	IsFailure func(err error) bool
+}
+
+// NewCircuitBreakerClient 为 client 添加熔断，breaker 为 nil 时使用默认配置
+func NewCircuitBreakerClient(client PayClient, breaker *CircuitBreaker) *CircuitBreakerClient {
+	if breaker == nil {
+		breaker = NewCircuitBreaker(nil)
+	}
+	return &CircuitBreakerClient{
+		client:    client,
+		breaker:   breaker,
+		IsFailure: isChannelFailure,
+	}
+}
+
+// Breaker 返回使用的熔断器，可用于查询状态
+func (c *CircuitBreakerClient) Breaker() *CircuitBreaker {
+	return c.breaker
+}
+
+func isChannelFailure(err error) bool {
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code >= 500
+	}
+	return IsTransportError(err)
+}
+
+// guard 依次检查网关操作和通道熔断器，pid 小于 0 表示不按通道熔断
+func guard[T any](c *CircuitBreakerClient, operation string, pid int32, call func() (T, error)) (T, error) {
+	var zero T
+	gen, err := c.breaker.Allow(operation)
+	if err != nil {
+		return zero, err
+	}
+	channel, channelGen := "", uint64(0)
+	if pid >= 0 {
+		channel = CircuitKey(operation, pid)
+		if channelGen, err = c.breaker.Allow(channel); err != nil {
+			// 未发送请求，释放网关操作的探测名额
+			c.breaker.release(operation, gen)
+			return zero, err
+		}
+	}
+
+	result, err := call()
+	c.breaker.Report(operation, gen, IsTransportError(err))
+	if channel != "" {
+		c.breaker.Report(channel, channelGen, err != nil && c.IsFailure(err))
+	}
+	return result, err
+}
+
+func (c *CircuitBreakerClient) CreateVirtual(param *OrderParam) (*pb.VirtualResp, error) {
+	return guard(c, CreateVirtual, param.Pid, func() (*pb.VirtualResp, error) {
+		return c.client.CreateVirtual(param)
+	})
+}
+
+func (c *CircuitBreakerClient) CreateReceive(param *ReceiveParam) (*pb.ReceiveResp, error) {
+	return guard(c, CreateReceive, param.Pid, func() (*pb.ReceiveResp, error) {
+		return c.client.CreateReceive(param)
+	})
+}
+
+func (c *CircuitBreakerClient) QueryReceive(orderNo, trxNo string) (*pb.OrderQueryResp, error) {
+	return guard(c, QueryReceive, -1, func() (*pb.OrderQueryResp, error) {
+		return c.client.QueryReceive(orderNo, trxNo)
+	})
+}
+
+func (c *CircuitBreakerClient) CreateOut(param *OutParam) (*pb.OutResp, error) {
+	return guard(c, CreateOut, param.Pid, func() (*pb.OutResp, error) {
+		return c.client.CreateOut(param)
+	})
+}
+
+func (c *CircuitBreakerClient) QueryOut(orderNo, trxNo string) (*pb.OrderQueryResp, error) {
+	return guard(c, QueryOut, -1, func() (*pb.OrderQueryResp, error) {
+		return c.client.QueryOut(orderNo, trxNo)
+	})
+}
+
+func (c *CircuitBreakerClient) Channel(orderType pb.ORDER_TYPE) ([]*pb.ChannelQueryResp, error) {
+	return guard(c, Channel, -1, func() ([]*pb.ChannelQueryResp, error) {
+		return c.client.Channel(orderType)
+	})
+}
+
+func (c *CircuitBreakerClient) Balance() (*pb.MerchantBalanceResp, error) {
+	return guard(c, Balance, -1, func() (*pb.MerchantBalanceResp, error) {
+		return c.client.Balance()
+	})
+}
+
+// Close 关闭底层客户端
+func (c *CircuitBreakerClient) Close() error {
+	closeClient(c.client)
+	return nil
+}
+
+var _ PayClient = (*CircuitBreakerClient)(nil)
@@ -0,0 +1,125 @@
+package xmpay
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestBreaker(probes int) *CircuitBreaker {
+	b := NewCircuitBreaker(testLogger())
+	b.FailureThreshold = 3
+	b.HalfOpenRequests = probes
+	return b
+}
+
+// expire 使打开状态立即超时
+func expire(b *CircuitBreaker, key string) {
+	b.mu.Lock()
+	b.circuits[key].openUntil = time.Now().Add(-time.Millisecond)
+	b.mu.Unlock()
+}
+
+func allow(t *testing.T, b *CircuitBreaker, key string) uint64 {
+	t.Helper()
+	gen, err := b.Allow(key)
+	if err != nil {
+		t.Fatalf("allow %s: %v", key, err)
+	}
+	return gen
+}
+
+func TestCircuitThreshold(t *testing.T) {
+	b := newTestBreaker(1)
+	// 成功重置连续失败次数
+	for _, failed := range []bool{true, true, false, true, true} {
+		b.Report("op", allow(t, b, "op"), failed)
+	}
+	if s := b.State("op"); s != CircuitClosed {
+		t.Fatalf("state = %s, want closed", s)
+	}
+	b.Report("op", allow(t, b, "op"), true)
+	if s := b.State("op"); s != CircuitOpen {
+		t.Fatalf("state = %s, want open", s)
+	}
+	var openErr *CircuitOpenError
+	if _, err := b.Allow("op"); !errors.Is(err, ErrCircuitOpen) || !errors.As(err, &openErr) || openErr.Key != "op" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestCircuitHalfOpenProbes(t *testing.T) {
+	b := newTestBreaker(2)
+	for i := 0; i < 3; i++ {
+		b.Report("op", allow(t, b, "op"), true)
+	}
+	expire(b, "op")
+
+	first, second := allow(t, b, "op"), allow(t, b, "op")
+	if _, err := b.Allow("op"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("probe over limit allowed, err = %v", err)
+	}
+	b.Report("op", first, false)
+	if s := b.State("op"); s != CircuitHalfOpen {
+		t.Fatalf("state = %s after one probe, want half-open", s)
+	}
+	b.Report("op", second, false)
+	if s := b.State("op"); s != CircuitClosed {
+		t.Errorf("state = %s, want closed", s)
+	}
+}
+
+func TestCircuitProbeFailureReopens(t *testing.T) {
+	b := newTestBreaker(1)
+	for i := 0; i < 3; i++ {
+		b.Report("op", allow(t, b, "op"), true)
+	}
+	expire(b, "op")
+	b.Report("op", allow(t, b, "op"), true)
+	if s := b.State("op"); s != CircuitOpen {
+		t.Errorf("state = %s, want open", s)
+	}
+}
+
+func TestCircuitStaleReport(t *testing.T) {
+	b := newTestBreaker(1)
+	// 关闭状态下放行的请求在半开后才结束
+	stale := allow(t, b, "op")
+	for i := 0; i < 3; i++ {
+		b.Report("op", allow(t, b, "op"), true)
+	}
+	expire(b, "op")
+	probe := allow(t, b, "op")
+
+	b.Report("op", stale, false)
+	if s := b.State("op"); s != CircuitHalfOpen {
+		t.Fatalf("stale success changed state to %s", s)
+	}
+	// 旧结果不占用探测名额
+	if _, err := b.Allow("op"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("probe over limit allowed, err = %v", err)
+	}
+	b.Report("op", probe, false)
+	if s := b.State("op"); s != CircuitClosed {
+		t.Errorf("state = %s, want closed", s)
+	}
+}
+
+func TestCircuitRelease(t *testing.T) {
+	b := newTestBreaker(1)
+	for i := 0; i < 3; i++ {
+		b.Report("op", allow(t, b, "op"), true)
+	}
+	expire(b, "op")
+
+	gen := allow(t, b, "op")
+	b.release("op", gen)
+	// 归还的名额可被下一个探测使用，状态不变
+	if s := b.State("op"); s != CircuitHalfOpen {
+		t.Fatalf("state = %s, want half-open", s)
+	}
+	b.Report("op", allow(t, b, "op"), false)
+	if s := b.State("op"); s != CircuitClosed {
+		t.Errorf("state = %s, want closed", s)
+	}
+}
@@ -2,10 +2,8 @@ package xmpay
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"net/http"
+	"net"
 	"time"
 
 	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
@@ -27,257 +25,117 @@ func GrpcClientInterceptor(ctx context.Context, method string, req, reply interf
 }
 
 // NewGrpcClient 创建一个新的gRPC客户端
-func NewGrpcClient(config *Config, log *logrus.Entry) (*GrpcClient, error) {
-	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithUnaryInterceptor(GrpcClientInterceptor),
-	}
-
-	conn, err := grpc.NewClient(config.ApiUrl, opts...)
-
-	if err != nil {
-		return nil, err
-	}
-
+func NewGrpcClient(config *Config, log *logrus.Entry, opts ...Option) (*GrpcClient, error) {
 	if log == nil {
 		log = logrus.WithField("model", "HttpClient")
 		log.Level = logrus.DebugLevel
 	}
 
-	return &GrpcClient{
+	c := &GrpcClient{
 		PayClientImpl: PayClientImpl{
-			accessId: config.AccessId,
-			aes:      NewAES([]byte(config.AccessId), []byte(config.AccessKey)),
-			log:      log,
+			Config: config,
+			log:    log,
 		},
-		conn:   conn,
-		client: pb.NewPayServiceClient(conn),
-	}, nil
-}
-
-// Close 关闭gRPC连接
-func (c *GrpcClient) Close() error {
-	return c.conn.Close()
-}
-
-// SayHello 调用SayHello RPC方法
-func (c *GrpcClient) CreateVirtual(param *OrderParam) (*pb.VirtualResp, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	req := &pb.VirtualParam{
-		OrderNo:   param.OrderNo,
-		Uid:       param.Uid,
-		Ip:        param.Ip,
-		Email:     param.Email,
-		Phone:     param.Phone,
-		Name:      param.Name,
-		Pid:       param.Pid,
-		IdNum:     param.IdNum,
-		NotifyUrl: param.NotifyUrl,
 	}
-
-	if param.NotifyUrl == "" {
-		req.NotifyUrl = c.InNotifyUrl
-	}
-	if param.Pid <= 0 {
-		req.Pid = StringToInt32(c.InId)
+	c.conf.Store(config)
+	c.setCredentials(config.AccessId, config.AccessKey)
+	for _, opt := range opts {
+		opt(&c.PayClientImpl)
 	}
-
-	rpcParam := c.encrypt(req)
-	resp, err := c.client.VirtualAccount(ctx, rpcParam)
-	if err != nil {
+	if err := c.RefreshCredentials(); err != nil {
 		return nil, err
 	}
 
-	if resp.Code != http.StatusOK {
-		return nil, errors.New(resp.Message)
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(GrpcClientInterceptor),
+		grpc.WithChainUnaryInterceptor(c.limitInterceptor),
+		grpc.WithStatsHandler(sentHandler{}),
 	}
 
-	respData := c.Decrypt([]byte(resp.Data))
-	var user pb.VirtualResp
-	if err := json.Unmarshal([]byte(respData), &user); err != nil {
-		return nil, err
+	target := config.ApiUrl
+	if addrs := config.Endpoints(); len(addrs) > 1 {
+		c.endpoints = NewEndpointPool(addrs, config.LoadBalance)
+		target, dialOpts = c.endpoints.grpcDialOptions(dialOpts)
 	}
-	return &user, nil
-}
 
-func (c *GrpcClient) CreateReceive(param *ReceiveParam) (*pb.ReceiveResp, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	conn, err := grpc.NewClient(target, dialOpts...)
 
-	req := &pb.ReceiveParam{
-		OrderNo:   param.OrderNo,
-		Amount:    param.Amount,
-		Uid:       param.Uid,
-		Ip:        param.Ip,
-		Email:     param.Email,
-		Phone:     param.Phone,
-		Name:      param.Name,
-		Pid:       param.Pid,
-		IdNum:     param.IdNum,
-		NotifyUrl: param.NotifyUrl,
-		ReturnUrl: param.ReturnUrl,
-		Subject:   param.Subject,
-		Body:      param.Body,
-	}
-	if param.NotifyUrl == "" {
-		req.NotifyUrl = c.InNotifyUrl
-	}
-	if param.Pid <= 0 {
-		req.Pid = StringToInt32(c.InId)
-	}
-	rpcParam := c.encrypt(req)
-	resp, err := c.client.Receive(ctx, rpcParam)
 	if err != nil {
 		return nil, err
 	}
-	if resp.Code != http.StatusOK {
-		return nil, errors.New(resp.Message)
-	}
-	respData := c.Decrypt([]byte(resp.Data))
-	var user pb.ReceiveResp
-	if err := json.Unmarshal([]byte(respData), &user); err != nil {
-		return nil, err
+	c.conn = conn
+	c.client = pb.NewPayServiceClient(conn)
+	return c, nil
+}
+
+// Endpoints 返回各网关地址状态，仅配置多个地址时有效
+func (c *GrpcClient) Endpoints() []Endpoint {
+	if c.endpoints == nil {
+		return nil
 	}
-	return &user, nil
+	return c.endpoints.Endpoints()
 }
 
-func (c *GrpcClient) QueryReceive(orderNo, trxNo string) (*pb.OrderQueryResp, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+// ProbeEndpoints 定期建立 TCP 连接探测各网关地址直到 ctx 结束，仅配置多个地址时有效
+func (c *GrpcClient) ProbeEndpoints(ctx context.Context, interval time.Duration) {
+	if c.endpoints == nil {
+		return
+	}
+	c.endpoints.Probe(ctx, interval, func(ctx context.Context, addr string) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	})
+}
 
-	req := &pb.OrderQueryParam{
-		OrderNo:    trxNo,
-		MerchantNo: orderNo,
-	}
-	rpcParam := c.encrypt(req)
-	resp, err := c.client.ReceiveQuery(ctx, rpcParam)
-	if err != nil {
-		return nil, err
-	}
-	if resp.Code != http.StatusOK {
-		return nil, errors.New(resp.Message)
-	}
-	respData := c.Decrypt([]byte(resp.Data))
-	var user pb.OrderQueryResp
-	if err := json.Unmarshal([]byte(respData), &user); err != nil {
-		return nil, err
-	}
-	return &user, nil
+// Close 关闭gRPC连接
+func (c *GrpcClient) Close() error {
+	return c.conn.Close()
 }
 
-func (c *GrpcClient) CreateOut(param *OutParam) (*pb.OutResp, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+// invoke 调用 r 对应的 RPC 方法
+func (c *GrpcClient) invoke(r route, param interface{}) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(c.context(), 30*time.Second)
 	defer cancel()
+	ctx, sent := withSentFlag(ctx)
 
-	req := &pb.OutParam{
-		OrderNo:   param.OrderNo,
-		Amount:    param.Amount,
-		Uid:       param.Uid,
-		Ip:        param.Ip,
-		Email:     param.Email,
-		Phone:     param.Phone,
-		Name:      param.Name,
-		IdNum:     param.IdNum,
-		Pid:       param.Pid,
-		BankNo:    param.BankNo,
-		BankCode:  param.BankCode,
-		BankName:  param.BankName,
-		Mode:      param.Mode,
-		NotifyUrl: param.NotifyUrl,
-		Subject:   param.Subject,
-		Body:      param.Body,
-	}
-	if param.NotifyUrl == "" {
-		req.NotifyUrl = c.OutNotifyUrl
-	}
-	if param.Pid <= 0 {
-		req.Pid = StringToInt32(c.OutId)
-	}
-
-	rpcParam := c.encrypt(req)
-	resp, err := c.client.Out(ctx, rpcParam)
+	rpcParam := c.encrypt(param)
+	resp, err := r.rpc(c.client, ctx, rpcParam)
 	if err != nil {
-		return nil, err
-	}
-	if resp.Code != http.StatusOK {
-		return nil, errors.New(resp.Message)
-	}
-	respData := c.Decrypt([]byte(resp.Data))
-	var menu *pb.OutResp
-	if err := json.Unmarshal([]byte(respData), &menu); err != nil {
-		return nil, err
+		return nil, unsentError(grpcError(err), sent.Load())
 	}
-	return menu, nil
+	return c.decode(resp)
 }
-func (c *GrpcClient) QueryOut(orderNo, trxNo string) (*pb.OrderQueryResp, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
 
-	req := &pb.OrderQueryParam{
-		OrderNo:    trxNo,
-		MerchantNo: orderNo,
-	}
+func (c *GrpcClient) CreateVirtual(param *OrderParam) (*pb.VirtualResp, error) {
+	return c.createVirtual(c, param)
+}
 
-	rpcParam := c.encrypt(req)
-	resp, err := c.client.OutQuery(ctx, rpcParam)
-	if err != nil {
-		return nil, err
-	}
-	if resp.Code != http.StatusOK {
-		return nil, errors.New(resp.Message)
-	}
-	respData := c.Decrypt([]byte(resp.Data))
-	var menu *pb.OrderQueryResp
-	if err := json.Unmarshal([]byte(respData), &menu); err != nil {
-		return nil, err
-	}
-	return menu, nil
+func (c *GrpcClient) CreateReceive(param *ReceiveParam) (*pb.ReceiveResp, error) {
+	return c.createReceive(c, param)
 }
 
-func (c *GrpcClient) Channel(orderType pb.ORDER_TYPE) ([]*pb.ChannelQueryResp, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+func (c *GrpcClient) QueryReceive(orderNo, trxNo string) (*pb.OrderQueryResp, error) {
+	return c.query(c, pb.ORDER_TYPE_RECEIVE, orderNo, trxNo)
+}
 
-	rpcParam := c.encrypt(&pb.ChannelQueryParam{
-		OrderType: orderType,
-	})
-	resp, err := c.client.ChannelQuery(ctx, rpcParam)
-	if err != nil {
-		return nil, err
-	}
+func (c *GrpcClient) CreateOut(param *OutParam) (*pb.OutResp, error) {
+	return c.createOut(c, param)
+}
 
-	if resp.Code != http.StatusOK {
-		return nil, errors.New(resp.Message)
-	}
+func (c *GrpcClient) QueryOut(orderNo, trxNo string) (*pb.OrderQueryResp, error) {
+	return c.query(c, pb.ORDER_TYPE_OUT, orderNo, trxNo)
+}
 
-	respData := c.Decrypt([]byte(resp.Data))
-	var user []*pb.ChannelQueryResp
-	if err := json.Unmarshal([]byte(respData), &user); err != nil {
-		return nil, err
-	}
-	return user, nil
+func (c *GrpcClient) Channel(orderType pb.ORDER_TYPE) ([]*pb.ChannelQueryResp, error) {
+	return call(c, opChannel, &pb.ChannelQueryParam{OrderType: orderType})
 }
 
 func (c *GrpcClient) Balance() (*pb.MerchantBalanceResp, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	rpcParam := c.encrypt(nil)
-	resp, err := c.client.MerchantBalance(ctx, rpcParam)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.Code != http.StatusOK {
-		return nil, errors.New(resp.Message)
-	}
-
-	respData := c.Decrypt([]byte(resp.Data))
-	var user pb.MerchantBalanceResp
-	if err := json.Unmarshal([]byte(respData), &user); err != nil {
-		return nil, err
-	}
-	return &user, nil
+	return call(c, opBalance, nil)
 }
@@ -2,6 +2,7 @@ package xmpay
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -23,174 +24,146 @@ const (
 	Balance       = "/gateway/api/merchant/balance"
 )
 
-func NewHttpClient(config *Config, log *logrus.Entry) *HttpClient {
+// NewHttpClient 创建一个新的HTTP客户端，SecretProvider 获取密钥失败时记录日志并使用配置中的密钥
+func NewHttpClient(config *Config, log *logrus.Entry, opts ...Option) *HttpClient {
+	c, err := newHttpClient(config, log, opts...)
+	if err != nil {
+		c.log.Errorf("resolve credentials failed, fallback to config, err: %v", err)
+	}
+	return c
+}
+
+// NewHttpClientE 创建一个新的HTTP客户端，SecretProvider 获取密钥失败时返回错误，与 NewGrpcClient 一致
+func NewHttpClientE(config *Config, log *logrus.Entry, opts ...Option) (*HttpClient, error) {
+	c, err := newHttpClient(config, log, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
 
+func newHttpClient(config *Config, log *logrus.Entry, opts ...Option) (*HttpClient, error) {
 	if log == nil {
 		log = logrus.WithField("model", "HttpClient")
 		log.Level = logrus.DebugLevel
 	}
 
-	return &HttpClient{
+	c := &HttpClient{
 		PayClientImpl: PayClientImpl{
-			Config:   config,
-			accessId: config.AccessId,
-			aes:      NewAES([]byte(config.AccessId), []byte(config.AccessKey)),
-			log:      log,
+			Config: config,
+			log:    log,
 		},
-		apiUrl: config.ApiUrl,
+		endpoints: NewEndpointPool(config.Endpoints(), config.LoadBalance),
 		client: &http.Client{
 			Timeout: 60 * time.Second,
 		},
 	}
-}
-func (c *HttpClient) CreateVirtual(param *OrderParam) (data *pb.VirtualResp, err error) {
-	req := &pb.VirtualParam{
-		OrderNo:   param.OrderNo,
-		Uid:       param.Uid,
-		Ip:        param.Ip,
-		Email:     param.Email,
-		Phone:     param.Phone,
-		Name:      param.Name,
-		IdNum:     param.IdNum,
-		Pid:       param.Pid,
-		NotifyUrl: param.NotifyUrl,
-	}
-	if param.NotifyUrl == "" {
-		req.NotifyUrl = c.InNotifyUrl
-	}
-	if param.Pid <= 0 {
-		req.Pid = StringToInt32(c.InId)
+	c.conf.Store(config)
+	c.setCredentials(config.AccessId, config.AccessKey)
+	for _, opt := range opts {
+		opt(&c.PayClientImpl)
 	}
-	err = c.doRequest(CreateVirtual, http.MethodPost, req, &data)
-
-	return
+	return c, c.RefreshCredentials()
 }
-
-func (c *HttpClient) CreateReceive(param *ReceiveParam) (data *pb.ReceiveResp, err error) {
-	req := &pb.ReceiveParam{
-		OrderNo:   param.OrderNo,
-		Amount:    param.Amount,
-		Uid:       param.Uid,
-		Ip:        param.Ip,
-		Email:     param.Email,
-		Phone:     param.Phone,
-		Name:      param.Name,
-		Subject:   param.Subject,
-		Body:      param.Body,
-		IdNum:     param.IdNum,
-		Pid:       param.Pid,
-		NotifyUrl: param.NotifyUrl,
-	}
-	if param.NotifyUrl == "" {
-		req.NotifyUrl = c.InNotifyUrl
-	}
-	if param.Pid <= 0 {
-		req.Pid = StringToInt32(c.InId)
-	}
-	err = c.doRequest(CreateReceive, http.MethodPost, req, &data)
-	return
+func (c *HttpClient) CreateVirtual(param *OrderParam) (*pb.VirtualResp, error) {
+	return c.createVirtual(c, param)
 }
 
-func (c *HttpClient) QueryReceive(orderNo, trxNo string) (data *pb.OrderQueryResp, err error) {
-
-	req := &pb.OrderQueryParam{
-		OrderNo:    trxNo,
-		MerchantNo: orderNo,
-	}
-	err = c.doRequest(QueryReceive, http.MethodPost, req, &data)
-
-	return
+func (c *HttpClient) CreateReceive(param *ReceiveParam) (*pb.ReceiveResp, error) {
+	return c.createReceive(c, param)
 }
 
-func (c *HttpClient) CreateOut(param *OutParam) (data *pb.OutResp, err error) {
-
-	req := &pb.OutParam{
-		OrderNo:   param.OrderNo,
-		Amount:    param.Amount,
-		Uid:       param.Uid,
-		Ip:        param.Ip,
-		Email:     param.Email,
-		Phone:     param.Phone,
-		Name:      param.Name,
-		IdNum:     param.IdNum,
-		Pid:       param.Pid,
-		BankNo:    param.BankNo,
-		BankCode:  param.BankCode,
-		BankName:  param.BankName,
-		Mode:      param.Mode,
-		NotifyUrl: param.NotifyUrl,
-		Subject:   param.Subject,
-		Body:      param.Body,
-	}
-
-	if param.NotifyUrl == "" {
-		req.NotifyUrl = c.OutNotifyUrl
-	}
-	if param.Pid <= 0 {
-		req.Pid = StringToInt32(c.OutId)
-	}
-	err = c.doRequest(CreateOut, http.MethodPost, req, &data)
-
-	return
+func (c *HttpClient) QueryReceive(orderNo, trxNo string) (*pb.OrderQueryResp, error) {
+	return c.query(c, pb.ORDER_TYPE_RECEIVE, orderNo, trxNo)
 }
 
-func (c *HttpClient) QueryOut(orderNo, trxNo string) (data *pb.OrderQueryResp, err error) {
-	req := &pb.OrderQueryParam{
-		OrderNo:    trxNo,
-		MerchantNo: orderNo,
-	}
-	err = c.doRequest(QueryOut, http.MethodPost, req, &data)
-
-	return
+func (c *HttpClient) CreateOut(param *OutParam) (*pb.OutResp, error) {
+	return c.createOut(c, param)
 }
-func (c *HttpClient) Channel(orderType pb.ORDER_TYPE) (data []*pb.ChannelQueryResp, err error) {
-
-	param := &pb.ChannelQueryParam{
-		OrderType: orderType,
-	}
 
-	err = c.doRequest(Channel, http.MethodPost, param, &data)
+func (c *HttpClient) QueryOut(orderNo, trxNo string) (*pb.OrderQueryResp, error) {
+	return c.query(c, pb.ORDER_TYPE_OUT, orderNo, trxNo)
+}
 
-	return
+func (c *HttpClient) Channel(orderType pb.ORDER_TYPE) ([]*pb.ChannelQueryResp, error) {
+	return call(c, opChannel, &pb.ChannelQueryParam{OrderType: orderType})
 }
 
-func (c *HttpClient) Balance() (data *pb.MerchantBalanceResp, err error) {
+func (c *HttpClient) Balance() (*pb.MerchantBalanceResp, error) {
+	return call(c, opBalance, nil)
+}
 
-	err = c.doRequest(Balance, http.MethodPost, nil, &data)
+// Endpoints 返回各网关地址状态
+func (c *HttpClient) Endpoints() []Endpoint {
+	return c.endpoints.Endpoints()
+}
 
-	return
+// ProbeEndpoints 定期请求各网关地址直到 ctx 结束，连接失败或返回 5xx 的地址被摘除
+func (c *HttpClient) ProbeEndpoints(ctx context.Context, interval time.Duration) {
+	c.endpoints.Probe(ctx, interval, func(ctx context.Context, addr string) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("probe status: %d", resp.StatusCode)
+		}
+		return nil
+	})
 }
 
-func (c *HttpClient) doRequest(path, method string, params interface{}, result interface{}) error {
-	url := c.apiUrl + path
+// invoke 向 r.path 发送 POST 请求
+func (c *HttpClient) invoke(r route, params interface{}) (data []byte, err error) {
+	path := r.path
+	ctx, sent := withSentFlag(c.context())
+	if err = c.limit(ctx, path); err != nil {
+		return nil, err
+	}
+
+	addr := c.endpoints.Pick()
+	url := addr + path
+	start := time.Now()
+	defer func() {
+		c.endpoints.Report(addr, time.Since(start), err)
+	}()
 
 	requestParam := c.encrypt(params)
 	reqParam, _ := json.Marshal(requestParam)
 
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(reqParam))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(reqParam))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := c.client.Do(req)
 	if err != nil {
 		c.log.Errorf("pay center http request failed , err: %v", err)
-		return err
+		return nil, unsentError(httpError(err), sent.Load())
 	}
 	defer func(Body io.ReadCloser) {
 		_ = Body.Close()
 	}(resp.Body)
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, c.throttled(path, parseRetryAfter(resp.Header.Get("Retry-After")))
+	}
 	if resp.StatusCode != http.StatusOK {
-		msg := fmt.Sprintf("network error: (%d)", resp.StatusCode)
-		c.log.Error(msg)
-		return errors.New(msg)
+		err = &StatusError{StatusCode: resp.StatusCode}
+		c.log.Error(err.Error())
+		return nil, httpError(err)
 	}
 	bodyByte, err := io.ReadAll(resp.Body)
 	if err != nil {
 		c.log.Error("response body read error")
-		return errors.New("response body read error")
+		return nil, httpError(errors.New("response body read error"))
 	}
 
 	c.log.Debug("解码前响应数据：", string(bodyByte))
@@ -198,20 +171,22 @@ func (c *HttpClient) doRequest(path, method string, params interface{}, result i
 	err = json.Unmarshal(bodyByte, &res)
 	if err != nil {
 		c.log.Error("response body unmarshal error")
-		return errors.New("response body unmarshal error")
+		return nil, httpError(errors.New("response body unmarshal error"))
+	}
+	if res.Code == http.StatusTooManyRequests {
+		return nil, c.throttled(path, parseRetryAfter(resp.Header.Get("Retry-After")))
 	}
 	if res.Code != http.StatusOK {
 		c.log.Error(res.Message)
-		return errors.New(res.Message)
+		return nil, apiError(res.Code, res.Message)
 	}
 
-	decrypt := c.Decrypt([]byte(res.Data))
-
-	c.log.Debug("解码后响应数据：", decrypt)
-	err = json.Unmarshal([]byte(decrypt), result)
+	data, _, err = c.DecryptKey([]byte(res.Data))
 	if err != nil {
-		c.log.Error("response body unmarshal error")
-		return err
+		c.log.Errorf("response data decrypt failed, err: %v", err)
+		return nil, err
 	}
-	return nil
+
+	c.log.Debug("解码后响应数据：", string(data))
+	return data, nil
 }
@@ -0,0 +1,80 @@
+// xmpay-sim 向本地回调地址推送模拟的 XMPAY 回调
+//
+//	xmpay-sim -notify http://localhost:8080/notify/receive -merchant-no ORDER123 -status SUCCESS -amount 10000
+//	xmpay-sim -notify http://localhost:8080/notify/receive -merchant-no ORDER123 -scenario duplicate
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"github.com/XingMenTech/XMPAY-SDK-GO/simulator"
+)
+
+func main() {
+	var (
+		accessId   = flag.String("access-id", os.Getenv("XMPAY_ACCESS_ID"), "accessId，默认读取 XMPAY_ACCESS_ID")
+		accessKey  = flag.String("access-key", os.Getenv("XMPAY_ACCESS_KEY"), "accessKey，默认读取 XMPAY_ACCESS_KEY，为空时以 accessId 作为 IV")
+		notifyUrl  = flag.String("notify", "", "本地回调地址")
+		merchantNo = flag.String("merchant-no", "", "商户订单号")
+		status     = flag.String("status", "SUCCESS", "订单状态："+statusNames())
+		amount     = flag.Int64("amount", 0, "实际金额（分）")
+		scenario   = flag.String("scenario", "", "内置场景："+strings.Join(simulator.Scenarios, ", "))
+	)
+	flag.Parse()
+
+	// accessKey 为空时以 accessId 作为 IV，与客户端一致
+	if *notifyUrl == "" || *merchantNo == "" || *accessId == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	sim := simulator.New(&xmpay.Config{AccessId: *accessId, AccessKey: *accessKey}, *notifyUrl)
+
+	var steps []simulator.Step
+	if *scenario != "" {
+		var err error
+		if steps, err = simulator.Scenario(*scenario, *merchantNo, *amount); err != nil {
+			exit(fmt.Errorf("%w: %s", err, *scenario))
+		}
+	} else {
+		value, ok := pb.ORDER_STATUS_value[strings.ToUpper(*status)]
+		if !ok {
+			exit(fmt.Errorf("unknown status: %s", *status))
+		}
+		cb := simulator.Callback(*merchantNo, pb.ORDER_STATUS(value), *amount)
+		steps = []simulator.Step{{Name: cb.Status.String(), Callback: cb}}
+	}
+
+	results, err := sim.Run(steps)
+	for _, r := range results {
+		data, _ := json.Marshal(r)
+		fmt.Println(string(data))
+	}
+	if err != nil {
+		exit(err)
+	}
+	for _, r := range results {
+		if !r.Passed {
+			os.Exit(1)
+		}
+	}
+}
+
+func statusNames() string {
+	names := make([]string, 0, len(pb.ORDER_STATUS_name))
+	for _, s := range []pb.ORDER_STATUS{pb.ORDER_STATUS_WAIT, pb.ORDER_STATUS_PROCESSING, pb.ORDER_STATUS_ABNORMAL, pb.ORDER_STATUS_FAILURE, pb.ORDER_STATUS_SUCCESS} {
+		names = append(names, s.String())
+	}
+	return strings.Join(names, ", ")
+}
+
+func exit(err error) {
+	_, _ = fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// options 全局参数
+type options struct {
+	configFile string
+	transport  string
+	output     string
+	verbose    bool
+}
+
+// configFields 配置字段对应的命令行参数
+var configFields = []struct {
+	flag  string
+	usage string
+	field func(c *xmpay.Config) *string
+}{
+	{"api-url", "API地址", func(c *xmpay.Config) *string { return &c.ApiUrl }},
+	{"access-id", "accessId", func(c *xmpay.Config) *string { return &c.AccessId }},
+	{"access-key", "accessKey", func(c *xmpay.Config) *string { return &c.AccessKey }},
+	{"in-id", "收款通道ID", func(c *xmpay.Config) *string { return &c.InId }},
+	{"out-id", "代付通道ID", func(c *xmpay.Config) *string { return &c.OutId }},
+	{"notify-in", "收款回调地址", func(c *xmpay.Config) *string { return &c.InNotifyUrl }},
+	{"notify-out", "代付回调地址", func(c *xmpay.Config) *string { return &c.OutNotifyUrl }},
+}
+
+func globalFlags(fs *flag.FlagSet) *options {
+	o := &options{}
+	fs.StringVar(&o.configFile, "config", os.Getenv("XMPAY_CONFIG"), "YAML 配置文件，默认读取 XMPAY_CONFIG")
+	fs.StringVar(&o.transport, "transport", "http", "通信方式：http|grpc")
+	fs.StringVar(&o.output, "output", "json", "输出格式：json|table")
+	fs.BoolVar(&o.verbose, "verbose", false, "输出调试日志")
+	for _, f := range configFields {
+		fs.String(f.flag, "", f.usage)
+	}
+	return o
+}
+
+// app 命令执行环境
+type app struct {
+	config *xmpay.Config
+	output string
+	client xmpay.PayClient
+	closer io.Closer
+	in     io.Reader
+	out    io.Writer
+}
+
+func newApp(o *options, fs *flag.FlagSet, validate bool) (*app, error) {
+	config, err := loadConfig(o, fs)
+	if err != nil {
+		return nil, err
+	}
+	if validate {
+		if err = config.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid config:\n%w", err)
+		}
+	}
+	if o.output != "json" && o.output != "table" {
+		return nil, fmt.Errorf("unknown output: %s", o.output)
+	}
+
+	log := logrus.NewEntry(logrus.New())
+	log.Logger.SetOutput(os.Stderr)
+	log.Logger.SetLevel(logrus.WarnLevel)
+	if o.verbose {
+		log.Logger.SetLevel(logrus.DebugLevel)
+	}
+
+	a := &app{config: config, output: o.output}
+	switch o.transport {
+	case "http":
+		c, err := xmpay.NewHttpClientE(config, log)
+		if err != nil {
+			return nil, err
+		}
+		a.client = c
+	case "grpc":
+		c, err := xmpay.NewGrpcClient(config, log)
+		if err != nil {
+			return nil, err
+		}
+		a.client, a.closer = c, c
+	default:
+		return nil, fmt.Errorf("unknown transport: %s", o.transport)
+	}
+	return a, nil
+}
+
+func (a *app) close() {
+	if a.closer != nil {
+		_ = a.closer.Close()
+	}
+}
+
+// loadConfig 依次合并 YAML 文件、环境变量和命令行参数
+func loadConfig(o *options, fs *flag.FlagSet) (*xmpay.Config, error) {
+	config := &xmpay.Config{}
+	if o.configFile != "" {
+		data, err := os.ReadFile(o.configFile)
+		if err != nil {
+			return nil, err
+		}
+		if err = yaml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", o.configFile, err)
+		}
+	}
+	config.ApplyEnv()
+
+	fs.Visit(func(f *flag.Flag) {
+		for _, field := range configFields {
+			if field.flag == f.Name {
+				*field.field(config) = f.Value.String()
+			}
+		}
+	})
+	return config, nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+// orderFlags 下单公共参数
+func orderFlags(fs *flag.FlagSet) *xmpay.OrderParam {
+	p := &xmpay.OrderParam{}
+	fs.StringVar(&p.OrderNo, "order-no", "", "商户订单号")
+	fs.StringVar(&p.Ip, "ip", "", "用户IP地址")
+	fs.StringVar(&p.Uid, "uid", "", "用户ID")
+	fs.StringVar(&p.Name, "name", "", "用户姓名")
+	fs.StringVar(&p.Phone, "phone", "", "用户手机号")
+	fs.StringVar(&p.Email, "email", "", "用户邮箱")
+	fs.StringVar(&p.IdNum, "id-num", "", "用户证件号码")
+	fs.StringVar(&p.NotifyUrl, "notify-url", "", "回调地址，默认使用配置")
+	fs.StringVar(&p.Subject, "subject", "", "商品标题")
+	fs.StringVar(&p.Body, "body", "", "商品描述")
+	fs.StringVar(&p.IdempotencyKey, "idempotency-key", "", "幂等业务键")
+	fs.Int64Var(&p.Amount, "amount", 0, "交易金额（分）")
+	fs.Func("pid", "支付通道ID，默认使用配置", func(v string) error {
+		pid, err := strconv.ParseInt(v, 10, 32)
+		p.Pid = int32(pid)
+		return err
+	})
+	return p
+}
+
+func receiveCreateFlags(fs *flag.FlagSet) func(a *app, args []string) error {
+	param := &xmpay.ReceiveParam{}
+	order := orderFlags(fs)
+	fs.StringVar(&param.ReturnUrl, "return-url", "", "付款成功后跳转地址")
+	return func(a *app, args []string) error {
+		param.OrderParam = *order
+		if param.OrderNo == "" || param.Amount <= 0 {
+			return errors.New("--order-no and --amount are required")
+		}
+		resp, err := a.client.CreateReceive(param)
+		if err != nil {
+			return err
+		}
+		return a.print(resp)
+	}
+}
+
+func outCreateFlags(fs *flag.FlagSet) func(a *app, args []string) error {
+	param := &xmpay.OutParam{}
+	order := orderFlags(fs)
+	fs.StringVar(&param.BankNo, "bank-no", "", "银行卡号")
+	fs.StringVar(&param.BankCode, "bank-code", "", "银行编号")
+	fs.StringVar(&param.BankName, "bank-name", "", "银行名称")
+	fs.StringVar(&param.Mode, "mode", "", "付款方式")
+	return func(a *app, args []string) error {
+		param.OrderParam = *order
+		if param.OrderNo == "" || param.Amount <= 0 || param.BankNo == "" {
+			return errors.New("--order-no, --amount and --bank-no are required")
+		}
+		resp, err := a.client.CreateOut(param)
+		if err != nil {
+			return err
+		}
+		return a.print(resp)
+	}
+}
+
+func virtualCreateFlags(fs *flag.FlagSet) func(a *app, args []string) error {
+	order := orderFlags(fs)
+	return func(a *app, args []string) error {
+		param := *order
+		if param.OrderNo == "" || param.Uid == "" {
+			return errors.New("--order-no and --uid are required")
+		}
+		resp, err := a.client.CreateVirtual(&param)
+		if err != nil {
+			return err
+		}
+		return a.print(resp)
+	}
+}
+
+func queryFlags(out bool) func(fs *flag.FlagSet) func(a *app, args []string) error {
+	return func(fs *flag.FlagSet) func(a *app, args []string) error {
+		orderNo := fs.String("order-no", "", "商户订单号")
+		trxNo := fs.String("trx-no", "", "平台订单号")
+		return func(a *app, args []string) error {
+			if *orderNo == "" && *trxNo == "" {
+				return errors.New("--order-no or --trx-no is required")
+			}
+			query := a.client.QueryReceive
+			if out {
+				query = a.client.QueryOut
+			}
+			resp, err := query(*orderNo, *trxNo)
+			if err != nil {
+				return err
+			}
+			return a.print(resp)
+		}
+	}
+}
+
+func refundFlags(fs *flag.FlagSet) func(a *app, args []string) error {
+	param := &xmpay.RefundParam{}
+	fs.StringVar(&param.MerchantNo, "order-no", "", "原收款单商户订单号")
+	fs.StringVar(&param.OrderNo, "trx-no", "", "原收款单平台订单号")
+	fs.StringVar(&param.RefundNo, "refund-no", "", "商户退款单号")
+	fs.Int64Var(&param.Amount, "amount", 0, "退款金额（分）")
+	fs.StringVar(&param.Reason, "reason", "", "退款原因")
+	fs.StringVar(&param.NotifyUrl, "notify-url", "", "退款回调地址，默认使用收款回调地址")
+	fs.StringVar(&param.IdempotencyKey, "idempotency-key", "", "幂等业务键")
+	return func(a *app, args []string) error {
+		if (param.MerchantNo == "" && param.OrderNo == "") || param.RefundNo == "" || param.Amount <= 0 {
+			return errors.New("--order-no or --trx-no, --refund-no and --amount are required")
+		}
+		resp, err := a.refundClient().Refund(param)
+		if err != nil {
+			return err
+		}
+		return a.print(resp)
+	}
+}
+
+func cancelFlags(fs *flag.FlagSet) func(a *app, args []string) error {
+	param := &xmpay.CancelParam{}
+	fs.StringVar(&param.MerchantNo, "order-no", "", "商户订单号")
+	fs.StringVar(&param.OrderNo, "trx-no", "", "平台订单号")
+	fs.StringVar(&param.Reason, "reason", "", "取消原因")
+	fs.StringVar(&param.IdempotencyKey, "idempotency-key", "", "幂等业务键")
+	return func(a *app, args []string) error {
+		if param.MerchantNo == "" && param.OrderNo == "" {
+			return errors.New("--order-no or --trx-no is required")
+		}
+		resp, err := a.refundClient().Cancel(param)
+		if err != nil {
+			return err
+		}
+		return a.print(resp)
+	}
+}
+
+func channelListFlags(fs *flag.FlagSet) func(a *app, args []string) error {
+	orderType := fs.String("type", pb.ORDER_TYPE_ALL.String(), "订单类型：ALL|RECEIVE|OUT|VIRTUAL")
+	return func(a *app, args []string) error {
+		value, ok := pb.ORDER_TYPE_value[strings.ToUpper(*orderType)]
+		if !ok {
+			return fmt.Errorf("unknown order type: %s", *orderType)
+		}
+		resp, err := a.client.Channel(pb.ORDER_TYPE(value))
+		if err != nil {
+			return err
+		}
+		return a.print(resp)
+	}
+}
+
+func balanceFlags(fs *flag.FlagSet) func(a *app, args []string) error {
+	return func(a *app, args []string) error {
+		resp, err := a.client.Balance()
+		if err != nil {
+			return err
+		}
+		return a.print(resp)
+	}
+}
+
+func encryptFlags(fs *flag.FlagSet) func(a *app, args []string) error {
+	return func(a *app, args []string) error {
+		data, err := a.payload(args)
+		if err != nil {
+			return err
+		}
+		encrypt, err := a.aes().Encrypt(data)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(a.out, encrypt)
+		return err
+	}
+}
+
+func decryptFlags(fs *flag.FlagSet) func(a *app, args []string) error {
+	return func(a *app, args []string) error {
+		data, err := a.payload(args)
+		if err != nil {
+			return err
+		}
+		decrypt, err := a.aes().Decrypt([]byte(strings.TrimSpace(string(data))))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(a.out, string(decrypt))
+		return err
+	}
+}
+
+// refundClient HttpClient 与 GrpcClient 均实现了 RefundClient
+func (a *app) refundClient() xmpay.RefundClient {
+	return a.client.(xmpay.RefundClient)
+}
+
+// aes 与客户端一致的加解密方式
+func (a *app) aes() *xmpay.AES {
+	return xmpay.NewAES([]byte(a.config.AccessId), []byte(a.config.AccessKey))
+}
+
+// payload 读取参数中的报文，未指定时读取标准输入
+func (a *app) payload(args []string) ([]byte, error) {
+	if len(args) > 0 {
+		return []byte(strings.Join(args, " ")), nil
+	}
+	return io.ReadAll(a.in)
+}
@@ -0,0 +1,105 @@
+// xmpay XMPAY 运维命令行工具
+//
+//	xmpay balance --config xmpay.yaml
+//	xmpay receive query --order-no ORDER123 --output table
+//	xmpay channel list --type OUT --transport grpc
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// command 子命令
+type command struct {
+	usage string
+	flags func(fs *flag.FlagSet) func(app *app, args []string) error
+	// raw 为 true 时不校验完整配置，仅需要密钥
+	raw bool
+}
+
+var commands = map[string]command{
+	"receive create": {usage: "创建收款订单", flags: receiveCreateFlags},
+	"receive query":  {usage: "查询收款订单", flags: queryFlags(false)},
+	"receive refund": {usage: "收款订单退款", flags: refundFlags},
+	"out create":     {usage: "创建付款订单", flags: outCreateFlags},
+	"out query":      {usage: "查询付款订单", flags: queryFlags(true)},
+	"out cancel":     {usage: "取消待处理的付款订单", flags: cancelFlags},
+	"virtual create": {usage: "创建虚拟账户", flags: virtualCreateFlags},
+	"channel list":   {usage: "查询支付通道", flags: channelListFlags},
+	"balance":        {usage: "查询商户余额", flags: balanceFlags},
+	"encrypt":        {usage: "加密报文（参数或标准输入）", flags: encryptFlags, raw: true},
+	"decrypt":        {usage: "解密报文（参数或标准输入）", flags: decryptFlags, raw: true},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		_, _ = fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// run 执行子命令，报文从 stdin 读取，结果输出到 stdout
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	name, cmd, rest, ok := lookup(args)
+	if !ok {
+		usage()
+		return flag.ErrHelp
+	}
+
+	fs := flag.NewFlagSet("xmpay "+name, flag.ContinueOnError)
+	opts := globalFlags(fs)
+	exec := cmd.flags(fs)
+	if err := fs.Parse(rest); err != nil {
+		return err
+	}
+
+	a, err := newApp(opts, fs, !cmd.raw)
+	if err != nil {
+		return err
+	}
+	defer a.close()
+	a.in, a.out = stdin, stdout
+	return exec(a, fs.Args())
+}
+
+// lookup 匹配一级或二级子命令
+func lookup(args []string) (string, command, []string, bool) {
+	if len(args) >= 2 {
+		name := args[0] + " " + args[1]
+		if cmd, ok := commands[name]; ok {
+			return name, cmd, args[2:], true
+		}
+	}
+	if len(args) >= 1 {
+		if cmd, ok := commands[args[0]]; ok {
+			return args[0], cmd, args[1:], true
+		}
+	}
+	return "", command{}, nil, false
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("Usage: xmpay <command> [flags]\n\nCommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %-16s %s\n", name, commands[name].usage)
+	}
+	b.WriteString("\n配置优先级：命令行参数 > XMPAY_ 环境变量 > --config 指定的 YAML 文件\n")
+	b.WriteString("执行 xmpay <command> -h 查看命令参数\n")
+	_, _ = fmt.Fprint(os.Stderr, b.String())
+}
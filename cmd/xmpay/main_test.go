@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"github.com/XingMenTech/XMPAY-SDK-GO/simulator"
+	"google.golang.org/grpc"
+)
+
+const (
+	testAccessId  = "0123456789abcdef"
+	testAccessKey = "fedcba9876543210"
+)
+
+// simGateway 同时以 HTTP 和 gRPC 方式启动模拟网关
+type simGateway struct {
+	*simulator.Gateway
+	httpUrl  string
+	grpcAddr string
+}
+
+func newSimGateway(t *testing.T) *simGateway {
+	t.Helper()
+	g := simulator.NewGateway(&xmpay.Config{AccessId: testAccessId, AccessKey: testAccessKey})
+	server := httptest.NewServer(g)
+	t.Cleanup(server.Close)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	g.Register(s)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return &simGateway{Gateway: g, httpUrl: server.URL, grpcAddr: lis.Addr().String()}
+}
+
+// exec 以 transport 方式执行命令并返回标准输出，全局参数放在命令参数之前
+func (g *simGateway) exec(t *testing.T, transport, stdin, name string, args ...string) (string, error) {
+	t.Helper()
+	apiUrl := g.httpUrl
+	if transport == "grpc" {
+		apiUrl = g.grpcAddr
+	}
+	args = append(append(strings.Fields(name),
+		"--transport", transport,
+		"--api-url", apiUrl,
+		"--access-id", testAccessId,
+		"--access-key", testAccessKey,
+		"--in-id", "7",
+		"--out-id", "8",
+	), args...)
+	var out bytes.Buffer
+	err := run(args, strings.NewReader(stdin), &out)
+	return out.String(), err
+}
+
+// call 执行命令并将 JSON 输出解析到 v
+func (g *simGateway) call(t *testing.T, transport string, v interface{}, name string, args ...string) {
+	t.Helper()
+	out, err := g.exec(t, transport, "", name, args...)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if err = json.Unmarshal([]byte(out), v); err != nil {
+		t.Fatalf("%s: output %q: %v", name, out, err)
+	}
+}
+
+func TestReceiveCommands(t *testing.T) {
+	g := newSimGateway(t)
+	for _, transport := range []string{"http", "grpc"} {
+		t.Run(transport, func(t *testing.T) {
+			no := "R-" + transport
+			var created pb.ReceiveResp
+			g.call(t, transport, &created, "receive create", "--order-no", no, "--amount", "1000", "--uid", "u1")
+			if created.MerchantNo != no || created.OrderNo == "" || created.PayUrl == "" {
+				t.Fatalf("create = %+v", &created)
+			}
+
+			var order pb.OrderQueryResp
+			g.call(t, transport, &order, "receive query", "--order-no", no)
+			if order.OrderNo != created.OrderNo || order.Amount != 1000 || order.Status != pb.ORDER_STATUS_WAIT {
+				t.Fatalf("query = %+v", &order)
+			}
+
+			// 未支付的订单不能退款
+			if _, err := g.exec(t, transport, "", "receive refund", "--order-no", no, "--refund-no", "F1", "--amount", "100"); err == nil {
+				t.Error("refund wait order, want error")
+			}
+			g.SetOrder(pb.ORDER_TYPE_RECEIVE, &pb.OrderQueryResp{OrderNo: created.OrderNo, MerchantNo: no, Amount: 1000, Status: pb.ORDER_STATUS_SUCCESS})
+			var refund pb.RefundResp
+			g.call(t, transport, &refund, "receive refund", "--order-no", no, "--refund-no", "F-"+transport, "--amount", "100")
+			if refund.MerchantNo != no || refund.Amount != 100 || refund.Status != pb.ORDER_STATUS_REFUNDING {
+				t.Errorf("refund = %+v", &refund)
+			}
+		})
+	}
+}
+
+func TestOutCommands(t *testing.T) {
+	g := newSimGateway(t)
+	for _, transport := range []string{"http", "grpc"} {
+		t.Run(transport, func(t *testing.T) {
+			no := "O-" + transport
+			var created pb.OutResp
+			g.call(t, transport, &created, "out create", "--order-no", no, "--amount", "500", "--bank-no", "6222000011112222")
+			if created.MerchantNo != no || created.OrderNo == "" {
+				t.Fatalf("create = %+v", &created)
+			}
+
+			var cancel pb.CancelResp
+			g.call(t, transport, &cancel, "out cancel", "--order-no", no, "--reason", "test")
+			if cancel.MerchantNo != no || cancel.Status != pb.ORDER_STATUS_CANCELED {
+				t.Fatalf("cancel = %+v", &cancel)
+			}
+
+			var order pb.OrderQueryResp
+			g.call(t, transport, &order, "out query", "--trx-no", created.OrderNo, "--order-no", no)
+			if order.Amount != 500 || order.Status != pb.ORDER_STATUS_CANCELED {
+				t.Errorf("query = %+v", &order)
+			}
+		})
+	}
+}
+
+func TestVirtualCreate(t *testing.T) {
+	g := newSimGateway(t)
+	var resp pb.VirtualResp
+	g.call(t, "http", &resp, "virtual create", "--order-no", "V1", "--uid", "u1", "--name", "张三")
+	if resp.MerchantNo != "V1" || resp.AccountNo == "" || resp.AccountName != "张三" {
+		t.Errorf("virtual = %+v", &resp)
+	}
+
+	// 缺少必填参数时不请求网关
+	if _, err := g.exec(t, "http", "", "virtual create", "--order-no", "V2"); err == nil {
+		t.Error("missing uid, want error")
+	}
+}
+
+func TestChannelList(t *testing.T) {
+	g := newSimGateway(t)
+	var all []*pb.ChannelQueryResp
+	g.call(t, "grpc", &all, "channel list")
+	if len(all) != len(g.Channels) {
+		t.Errorf("channels = %d, want %d", len(all), len(g.Channels))
+	}
+
+	out, err := g.exec(t, "http", "", "channel list", "--type", "out", "--output", "table")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "CHANNEL") || !strings.Contains(lines[1], "SIM-OUT") {
+		t.Errorf("table output:\n%s", out)
+	}
+
+	if _, err = g.exec(t, "http", "", "channel list", "--type", "unknown"); err == nil {
+		t.Error("unknown type, want error")
+	}
+}
+
+func TestBalance(t *testing.T) {
+	g := newSimGateway(t)
+	for _, transport := range []string{"http", "grpc"} {
+		var resp pb.MerchantBalanceResp
+		g.call(t, transport, &resp, "balance")
+		if resp.Name != g.Balance.Name || resp.Available != g.Balance.Available {
+			t.Errorf("%s balance = %+v", transport, &resp)
+		}
+	}
+
+	out, err := g.exec(t, "http", "", "balance", "--output", "table")
+	if err != nil || !strings.Contains(out, "available") {
+		t.Errorf("table output %q, err = %v", out, err)
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	g := newSimGateway(t)
+	plain := `{"merchant_no":"M1"}`
+	encrypted, err := g.exec(t, "http", "", "encrypt", plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 与网关使用相同的密钥，网关可解密
+	data, err := xmpay.NewAES([]byte(testAccessId), []byte(testAccessKey)).Decrypt([]byte(strings.TrimSpace(encrypted)))
+	if err != nil || string(data) != plain {
+		t.Fatalf("decrypt by gateway key = %q, err = %v", data, err)
+	}
+
+	// 从标准输入读取报文
+	decrypted, err := g.exec(t, "http", encrypted, "decrypt")
+	if err != nil || strings.TrimSpace(decrypted) != plain {
+		t.Errorf("decrypt = %q, err = %v", decrypted, err)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// print 按输出格式打印结果
+func (a *app) print(v interface{}) error {
+	if a.output == "table" {
+		return printTable(a, v)
+	}
+	enc := json.NewEncoder(a.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printTable 单个对象按字段逐行输出，列表按行输出
+func printTable(a *app, v interface{}) error {
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	rv := reflect.Indirect(reflect.ValueOf(v))
+
+	switch rv.Kind() {
+	case reflect.Slice:
+		var header []string
+		for i := 0; i < rv.Len(); i++ {
+			names, values := fields(rv.Index(i))
+			if header == nil {
+				header = names
+				fmt.Fprintln(w, strings.ToUpper(strings.Join(header, "\t")))
+			}
+			fmt.Fprintln(w, strings.Join(values, "\t"))
+		}
+	case reflect.Struct:
+		names, values := fields(rv)
+		for i := range names {
+			fmt.Fprintf(w, "%s\t%s\n", names[i], values[i])
+		}
+	default:
+		fmt.Fprintf(w, "%v\n", v)
+	}
+	return w.Flush()
+}
+
+// fields 返回结构体导出字段的 json 名称和值
+func fields(v reflect.Value) (names, values []string) {
+	v = reflect.Indirect(v)
+	if v.Kind() != reflect.Struct {
+		return []string{"value"}, []string{fmt.Sprint(v.Interface())}
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			name = f.Name
+		}
+		names = append(names, name)
+		values = append(values, fmt.Sprint(v.Field(i).Interface()))
+	}
+	return names, values
+}
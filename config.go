@@ -0,0 +1,242 @@
+package xmpay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrChannelId 请求未指定通道ID，且配置中没有对应业务的默认通道ID
+var ErrChannelId = errors.New("channel id required: set Pid or in_id/out_id in config")
+
+// EnvPrefix 环境变量前缀，变量名为前缀加大写的 yaml 字段名，如 XMPAY_API_URL
+const EnvPrefix = "XMPAY_"
+
+// 配置文件格式
+const (
+	ConfigYAML = "yaml"
+	ConfigJSON = "json"
+)
+
+// LoadConfig 从文件加载配置，按扩展名识别 JSON，其余按 YAML 解析，
+// 随后使用 XMPAY_ 环境变量覆盖并校验，校验失败时同时返回配置和错误
+func LoadConfig(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	format := ConfigYAML
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = ConfigJSON
+	}
+	config, err := decodeConfig(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	config.ApplyEnv()
+	return config, config.Validate()
+}
+
+// LoadConfigFromReader 从 r 读取 YAML 或 JSON 格式的配置并校验
+func LoadConfigFromReader(r io.Reader, format string) (*Config, error) {
+	config, err := decodeConfig(r, format)
+	if err != nil {
+		return nil, err
+	}
+	return config, config.Validate()
+}
+
+// LoadConfigFromEnv 从 XMPAY_ 环境变量加载配置并校验
+func LoadConfigFromEnv() (*Config, error) {
+	config := &Config{}
+	config.ApplyEnv()
+	return config, config.Validate()
+}
+
+func decodeConfig(r io.Reader, format string) (*Config, error) {
+	config := &Config{}
+	switch strings.ToLower(format) {
+	case ConfigYAML, "yml":
+		if err := yaml.NewDecoder(r).Decode(config); err != nil && err != io.EOF {
+			return nil, err
+		}
+	case ConfigJSON:
+		if err := json.NewDecoder(r).Decode(config); err != nil && err != io.EOF {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown config format: %s", format)
+	}
+	return config, nil
+}
+
+// ApplyEnv 使用已设置的 XMPAY_ 环境变量覆盖配置，切片字段以逗号分隔
+func (c *Config) ApplyEnv() {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		value, ok := os.LookupEnv(EnvPrefix + strings.ToUpper(name))
+		if !ok {
+			continue
+		}
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Slice:
+			if field.Type().Elem().Kind() != reflect.String {
+				continue
+			}
+			var list []string
+			for _, s := range strings.Split(value, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					list = append(list, s)
+				}
+			}
+			field.Set(reflect.ValueOf(list))
+		}
+	}
+}
+
+// Validate 校验配置，一次返回所有问题
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	if len(c.ApiUrls) > 0 {
+		for i, u := range c.ApiUrls {
+			if err := validateEndpoint(u); err != nil {
+				add(fmt.Sprintf("api_urls[%d]", i), "%v", err)
+			}
+		}
+	} else if c.ApiUrl == "" {
+		add("api_url", "required")
+	} else if err := validateApiUrl(c.ApiUrl); err != nil {
+		add("api_url", "%v", err)
+	}
+	switch c.LoadBalance {
+	case "", BalanceRoundRobin, BalanceLeastLatency:
+	default:
+		add("load_balance", "must be %s or %s, got %q", BalanceRoundRobin, BalanceLeastLatency, c.LoadBalance)
+	}
+
+	switch len(c.AccessId) {
+	case 16, 24, 32:
+	default:
+		add("access_id", "length must be 16, 24 or 32 bytes for AES, got %d", len(c.AccessId))
+	}
+	// AccessKey 作为 IV 使用，为空时以 AccessId 代替
+	if c.AccessKey != "" && len(c.AccessKey) < 16 {
+		add("access_key", "length must be at least 16 bytes, got %d", len(c.AccessKey))
+	}
+
+	// 仅收款或仅付款的商户只需配置对应的通道ID，配置了回调地址的业务须配置通道ID
+	if c.InId == "" && c.OutId == "" {
+		add("in_id", "in_id or out_id required")
+	}
+	for _, f := range []struct{ name, id, notify, url string }{
+		{"in_id", c.InId, "notify_in", c.InNotifyUrl},
+		{"out_id", c.OutId, "notify_out", c.OutNotifyUrl},
+	} {
+		if f.id == "" {
+			if f.url != "" {
+				add(f.name, "required when %s is set", f.notify)
+			}
+		} else if n, err := strconv.ParseInt(f.id, 10, 32); err != nil || n <= 0 {
+			add(f.name, "must be a positive integer, got %q", f.id)
+		}
+	}
+
+	for _, f := range [][2]string{{"notify_in", c.InNotifyUrl}, {"notify_out", c.OutNotifyUrl}} {
+		if f[1] == "" {
+			continue
+		}
+		if err := validateHttpUrl(f[1]); err != nil {
+			add(f[0], "%v", err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Endpoints 返回网关地址列表，设置了 ApiUrls 时使用 ApiUrls，否则为 ApiUrl
+func (c *Config) Endpoints() []string {
+	if len(c.ApiUrls) > 0 {
+		return c.ApiUrls
+	}
+	return []string{c.ApiUrl}
+}
+
+// validateApiUrl HTTP 客户端使用完整的 http(s) 地址，gRPC 客户端使用 host:port 或 gRPC 目标地址
+func validateApiUrl(s string) error {
+	if !strings.Contains(s, "://") {
+		host, port, err := net.SplitHostPort(s)
+		if err != nil {
+			return fmt.Errorf("invalid address %q: %v", s, err)
+		}
+		if host == "" {
+			return fmt.Errorf("invalid address %q: missing host", s)
+		}
+		if _, err = strconv.ParseUint(port, 10, 16); err != nil {
+			return fmt.Errorf("invalid address %q: invalid port", s)
+		}
+		return nil
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %v", s, err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return fmt.Errorf("invalid url %q: missing host", s)
+		}
+	case "dns", "passthrough", "unix", "unix-abstract":
+	default:
+		return fmt.Errorf("invalid url %q: unsupported scheme %q", s, u.Scheme)
+	}
+	return nil
+}
+
+// validateEndpoint 多地址时每个地址由客户端直接拨号，只接受 host:port 或 http(s) 地址，
+// 不接受 dns:///、unix: 等需要 gRPC 解析器的目标地址
+func validateEndpoint(s string) error {
+	if err := validateApiUrl(s); err != nil {
+		return err
+	}
+	if u, err := url.Parse(s); err == nil && strings.Contains(s, "://") && u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid address %q: scheme %q is not supported with multiple endpoints, use host:port", s, u.Scheme)
+	}
+	return nil
+}
+
+func validateHttpUrl(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %v", s, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q: must be an absolute http(s) url", s)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package xmpay
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	yamlPath := writeConfig(t, "xmpay.yaml", `
+api_url: http://127.0.0.1:8080
+access_id: 0123456789abcdef
+access_key: fedcba9876543210
+in_id: "7"
+notify_in: http://merchant/in
+`)
+	jsonPath := writeConfig(t, "xmpay.json", `{"apiUrl":"http://127.0.0.1:8080","accessId":"0123456789abcdef","outId":"8"}`)
+
+	config, err := LoadConfig(yamlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.InId != "7" || config.InNotifyUrl != "http://merchant/in" || config.OutId != "" {
+		t.Errorf("yaml config = %+v", config)
+	}
+	if config, err = LoadConfig(jsonPath); err != nil || config.OutId != "8" {
+		t.Errorf("json config = %+v, err = %v", config, err)
+	}
+
+	// 环境变量覆盖文件中的配置
+	t.Setenv(EnvPrefix+"IN_ID", "9")
+	if config, err = LoadConfig(yamlPath); err != nil || config.InId != "9" {
+		t.Errorf("env override: %+v, %v", config, err)
+	}
+
+	// 校验失败时同时返回配置
+	t.Setenv(EnvPrefix+"ACCESS_ID", "short")
+	config, err = LoadConfig(yamlPath)
+	if config == nil || err == nil || !strings.Contains(err.Error(), "access_id") {
+		t.Errorf("invalid config: %+v, %v", config, err)
+	}
+
+	if _, err = LoadConfig(writeConfig(t, "bad.yaml", "api_url: [")); err == nil {
+		t.Error("malformed yaml accepted")
+	}
+	if _, err = LoadConfig(filepath.Join(t.TempDir(), "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file, err = %v", err)
+	}
+}
+
+func TestLoadConfigFromReader(t *testing.T) {
+	config, err := LoadConfigFromReader(strings.NewReader(`{"apiUrl":"127.0.0.1:9090","accessId":"0123456789abcdef","inId":"7"}`), ConfigJSON)
+	if err != nil || config.ApiUrl != "127.0.0.1:9090" {
+		t.Errorf("config = %+v, err = %v", config, err)
+	}
+	if _, err = LoadConfigFromReader(strings.NewReader(""), "toml"); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv(EnvPrefix+"API_URLS", " 127.0.0.1:1, ,127.0.0.1:2 ")
+	t.Setenv(EnvPrefix+"ACCESS_KEY", "")
+	t.Setenv(EnvPrefix+"MERCHANT_ID", "M001")
+
+	config := testConfig("http://127.0.0.1:1")
+	config.ApplyEnv()
+	if !equalStrings(config.ApiUrls, []string{"127.0.0.1:1", "127.0.0.1:2"}) {
+		t.Errorf("api urls = %q", config.ApiUrls)
+	}
+	// 已设置为空的变量覆盖为空，未设置的变量保留原值
+	if config.AccessKey != "" || config.MerchantId != "M001" || config.AccessId != testAccessId {
+		t.Errorf("config = %+v", config)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		errs   []string // 错误信息须包含的字段，为空表示校验通过
+	}{
+		{"valid", func(c *Config) {}, nil},
+		{"receive only", func(c *Config) { c.OutId, c.OutNotifyUrl = "", "" }, nil},
+		{"payout only", func(c *Config) { c.InId, c.InNotifyUrl = "", "" }, nil},
+		{"grpc address", func(c *Config) { c.ApiUrl = "127.0.0.1:9090" }, nil},
+		{"multiple endpoints", func(c *Config) { c.ApiUrls = []string{"127.0.0.1:1", "http://127.0.0.1:2"} }, nil},
+		{"no channel", func(c *Config) { c.InId, c.OutId, c.InNotifyUrl, c.OutNotifyUrl = "", "", "", "" }, []string{"in_id or out_id"}},
+		{"notify without channel", func(c *Config) { c.OutId = "" }, []string{"out_id: required when notify_out"}},
+		{"invalid channel", func(c *Config) { c.InId = "abc" }, []string{"in_id: must be a positive integer"}},
+		{"missing api url", func(c *Config) { c.ApiUrl = "" }, []string{"api_url: required"}},
+		{"resolver endpoint", func(c *Config) { c.ApiUrls = []string{"dns:///gateway:9090"} }, []string{"api_urls[0]"}},
+		{"load balance", func(c *Config) { c.LoadBalance = "random" }, []string{"load_balance"}},
+		{"notify url", func(c *Config) { c.InNotifyUrl = "/notify" }, []string{"notify_in"}},
+		// 一次返回所有问题
+		{"multiple", func(c *Config) { c.AccessId, c.AccessKey = "short", "short" }, []string{"access_id", "access_key"}},
+	}
+	for _, tt := range tests {
+		config := testConfig("http://127.0.0.1:1")
+		tt.modify(config)
+		err := config.Validate()
+		if len(tt.errs) == 0 {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: no error", tt.name)
+			continue
+		}
+		for _, want := range tt.errs {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: err = %v, want %q", tt.name, err, want)
+			}
+		}
+	}
+}
+
+func TestCreateWithoutChannel(t *testing.T) {
+	var requests int
+	c := newReplyClient(t, &struct{}{}, &requests)
+	c.setConfig(&Config{ApiUrl: c.config().ApiUrl, InId: "7"})
+
+	// 仅收款商户未指定通道时不发送付款请求
+	if _, err := c.CreateOut(&OutParam{OrderParam: OrderParam{OrderNo: "M1", Amount: 100}}); !errors.Is(err, ErrChannelId) {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := c.CreateOut(&OutParam{OrderParam: OrderParam{OrderNo: "M2", Amount: 100, Pid: 3}}); err != nil {
+		t.Errorf("explicit pid: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
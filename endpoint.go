@@ -0,0 +1,316 @@
+package xmpay
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/balancer/leastrequest"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+// 多网关地址的负载均衡策略
+const (
+	BalanceRoundRobin   = "round_robin"
+	BalanceLeastLatency = "least_latency"
+)
+
+// Endpoint 网关地址状态快照
+type Endpoint struct {
+	Addr     string
+	Latency  time.Duration // 成功请求耗时的滑动平均
+	Failures int           // 连续传输错误次数
+	Ejected  bool          // 是否已被摘除
+}
+
+type endpoint struct {
+	addr         string
+	latency      time.Duration
+	failures     int
+	ejectedUntil time.Time
+}
+
+func (e *endpoint) ejected(now time.Time) bool {
+	return now.Before(e.ejectedUntil)
+}
+
+// EndpointPool 网关地址池，按轮询或最低延迟选择地址，
+// 连续传输错误的地址被摘除一段时间（被动异常剔除），也可定期探测（主动健康检查）
+type EndpointPool struct {
+	Strategy    string        // BalanceRoundRobin 或 BalanceLeastLatency
+	MaxFailures int           // 连续传输错误次数达到后摘除
+	EjectTime   time.Duration // 摘除时长，到期后重新参与选择
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+	next      int
+	healthy   []string
+	onChange  func(healthy []string)
+}
+
+// NewEndpointPool 创建地址池，strategy 为空时使用轮询
+func NewEndpointPool(addrs []string, strategy string) *EndpointPool {
+	if strategy == "" {
+		strategy = BalanceRoundRobin
+	}
+	p := &EndpointPool{
+		Strategy:    strategy,
+		MaxFailures: 3,
+		EjectTime:   30 * time.Second,
+	}
+	for _, addr := range addrs {
+		p.endpoints = append(p.endpoints, &endpoint{addr: addr})
+	}
+	p.healthy = addrs
+	return p
+}
+
+// Pick 选择一个地址，所有地址均被摘除时选择最早恢复的地址
+func (p *EndpointPool) Pick() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+
+	var best *endpoint
+	switch p.Strategy {
+	case BalanceLeastLatency:
+		for _, e := range p.endpoints {
+			if e.ejected(now) {
+				continue
+			}
+			// 没有延迟数据的地址优先，保证每个地址都会被尝试
+			if best == nil || e.latency < best.latency {
+				best = e
+			}
+		}
+	default:
+		for i := 0; i < len(p.endpoints); i++ {
+			e := p.endpoints[(p.next+i)%len(p.endpoints)]
+			if !e.ejected(now) {
+				best = e
+				p.next = (p.next + i + 1) % len(p.endpoints)
+				break
+			}
+		}
+	}
+
+	if best == nil {
+		for _, e := range p.endpoints {
+			if best == nil || e.ejectedUntil.Before(best.ejectedUntil) {
+				best = e
+			}
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.addr
+}
+
+// Report 记录一次请求结果，仅传输层错误计入失败次数
+func (p *EndpointPool) Report(addr string, latency time.Duration, err error) {
+	p.mu.Lock()
+	e := p.find(addr)
+	if e == nil {
+		p.mu.Unlock()
+		return
+	}
+	if err != nil && IsTransportError(err) {
+		e.failures++
+		if p.MaxFailures > 0 && e.failures >= p.MaxFailures {
+			e.ejectedUntil = time.Now().Add(p.EjectTime)
+			e.failures = 0
+		}
+	} else {
+		e.failures = 0
+		e.ejectedUntil = time.Time{}
+		if e.latency == 0 {
+			e.latency = latency
+		} else {
+			e.latency = (e.latency*4 + latency) / 5
+		}
+	}
+	changed := p.refresh()
+	p.mu.Unlock()
+	changed()
+}
+
+// Endpoints 返回所有地址的状态
+func (p *EndpointPool) Endpoints() []Endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	list := make([]Endpoint, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		list = append(list, Endpoint{Addr: e.addr, Latency: e.latency, Failures: e.failures, Ejected: e.ejected(now)})
+	}
+	return list
+}
+
+// Probe 每隔 interval 使用 check 探测所有地址直到 ctx 结束，探测失败的地址被摘除，成功后恢复
+func (p *EndpointPool) Probe(ctx context.Context, interval time.Duration, check func(ctx context.Context, addr string) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		p.probe(ctx, check)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *EndpointPool) probe(ctx context.Context, check func(ctx context.Context, addr string) error) {
+	p.mu.Lock()
+	addrs := make([]string, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		addrs = append(addrs, e.addr)
+	}
+	p.mu.Unlock()
+
+	for _, addr := range addrs {
+		start := time.Now()
+		err := check(ctx, addr)
+		if ctx.Err() != nil {
+			return
+		}
+
+		p.mu.Lock()
+		if e := p.find(addr); e != nil {
+			if err != nil {
+				e.ejectedUntil = time.Now().Add(p.EjectTime)
+			} else {
+				e.ejectedUntil = time.Time{}
+				if e.latency == 0 {
+					e.latency = time.Since(start)
+				}
+			}
+		}
+		changed := p.refresh()
+		p.mu.Unlock()
+		changed()
+	}
+}
+
+// notify 设置可用地址变化时的回调，gRPC 客户端据此更新解析结果
+func (p *EndpointPool) notify(fn func(healthy []string)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onChange = fn
+}
+
+func (p *EndpointPool) find(addr string) *endpoint {
+	for _, e := range p.endpoints {
+		if e.addr == addr {
+			return e
+		}
+	}
+	return nil
+}
+
+// refresh 重新计算可用地址，变化时返回通知函数，须在释放锁后调用
+func (p *EndpointPool) refresh() func() {
+	now := time.Now()
+	var healthy []string
+	for _, e := range p.endpoints {
+		if !e.ejected(now) {
+			healthy = append(healthy, e.addr)
+		}
+	}
+	// 全部被摘除时保留所有地址，避免无地址可用
+	if len(healthy) == 0 {
+		for _, e := range p.endpoints {
+			healthy = append(healthy, e.addr)
+		}
+	}
+	if equalStrings(healthy, p.healthy) || p.onChange == nil {
+		p.healthy = healthy
+		return func() {}
+	}
+	p.healthy = healthy
+	fn := p.onChange
+	return func() {
+		fn(healthy)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// grpcScheme 多地址 gRPC 客户端使用的解析器 scheme
+const grpcScheme = "xmpay"
+
+// grpcDialOptions 使用手动解析器提供地址列表，可用地址变化时更新解析结果。
+// round_robin 对应 gRPC round_robin 策略，least_latency 使用 least_request 策略近似
+func (p *EndpointPool) grpcDialOptions(dialOpts []grpc.DialOption) (string, []grpc.DialOption) {
+	r := manual.NewBuilderWithScheme(grpcScheme)
+	r.InitialState(resolver.State{Addresses: resolverAddresses(p.healthy)})
+	p.notify(func(healthy []string) {
+		r.UpdateState(resolver.State{Addresses: resolverAddresses(healthy)})
+	})
+
+	policy := `{"round_robin":{}}`
+	if p.Strategy == BalanceLeastLatency {
+		policy = `{"least_request_experimental":{"choiceCount":2}}`
+	}
+
+	// 记录连接的远端地址与配置地址的对应关系，用于按调用结果统计各地址；
+	// 连接失败时调用不会到达该地址，由拨号器直接计入失败
+	var remotes sync.Map
+	dialer := func(ctx context.Context, addr string) (net.Conn, error) {
+		start := time.Now()
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+		if err != nil {
+			if ctx.Err() == nil {
+				p.Report(addr, time.Since(start), &TransportError{Transport: TransportGrpc, Err: err, unsent: true})
+			}
+			return nil, err
+		}
+		remotes.Store(conn.RemoteAddr().String(), addr)
+		return conn, nil
+	}
+	report := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var pr peer.Peer
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Peer(&pr))...)
+		if pr.Addr != nil {
+			if addr, ok := remotes.Load(pr.Addr.String()); ok {
+				var cause error
+				if err != nil {
+					cause = grpcError(err)
+				}
+				p.Report(addr.(string), time.Since(start), cause)
+			}
+		}
+		return err
+	}
+
+	return grpcScheme + ":///gateway", append(dialOpts,
+		grpc.WithResolvers(r),
+		grpc.WithContextDialer(dialer),
+		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig":[`+policy+`]}`),
+		grpc.WithChainUnaryInterceptor(report),
+	)
+}
+
+func resolverAddresses(addrs []string) []resolver.Address {
+	list := make([]resolver.Address, 0, len(addrs))
+	for _, addr := range addrs {
+		list = append(list, resolver.Address{Addr: addr})
+	}
+	return list
+}
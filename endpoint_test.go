@@ -0,0 +1,75 @@
+package xmpay
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"google.golang.org/grpc"
+)
+
+func TestValidateEndpoints(t *testing.T) {
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"127.0.0.1:9000", true},
+		{"https://pay.example.com", true},
+		{"dns:///pay.example.com:443", false},
+		{"unix:///tmp/xmpay.sock", false},
+		{"unix:/tmp/xmpay.sock", false},
+		{"passthrough:///127.0.0.1:9000", false},
+	}
+	for _, tt := range tests {
+		config := testConfig("")
+		config.ApiUrls = []string{"127.0.0.1:9001", tt.url}
+		err := config.Validate()
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: err = %v", tt.url, err)
+		}
+		if err != nil && !strings.Contains(err.Error(), "api_urls[1]") {
+			t.Errorf("%s: err = %v, want api_urls[1]", tt.url, err)
+		}
+	}
+}
+
+func TestGrpcEndpointDialFailure(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	pb.RegisterPayServiceServer(server, pb.UnimplementedPayServiceServer{})
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	down := closed.Addr().String()
+	_ = closed.Close()
+
+	config := testConfig("")
+	config.ApiUrls = []string{lis.Addr().String(), down}
+	c, err := NewGrpcClient(config, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// 调用只会到达可连接的地址，无法连接的地址由拨号失败计入
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		_, _ = c.Balance()
+		for _, e := range c.Endpoints() {
+			if e.Addr == down && (e.Failures > 0 || e.Ejected) {
+				return
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Errorf("dial failure not reported: %+v", c.Endpoints())
+}
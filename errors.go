@@ -0,0 +1,120 @@
+package xmpay
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ApiError 网关返回的业务错误（PayRpcResp.Code 非 200，或 InvalidArgument、PermissionDenied 等
+// 与传输无关的 gRPC 状态码，此时 Code 为对应的 HTTP 状态码），重试或切换传输方式不会改变结果
+type ApiError struct {
+	Code    int32
+	Message string
+
+	err error // 原始 gRPC 状态错误，status.Code 可据此取得状态码
+}
+
+func (e *ApiError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("gateway error: code %d", e.Code)
+	}
+	return e.Message
+}
+
+func (e *ApiError) Unwrap() error {
+	return e.err
+}
+
+// TransportError 网络、HTTP 状态码或 gRPC 调用层面的错误，请求可能未到达网关
+type TransportError struct {
+	Transport string
+	Err       error
+
+	unsent bool // 请求确定未离开客户端（连接未建立或请求头未写出）
+}
+
+func (e *TransportError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// StatusError 网关返回的非 200 HTTP 状态码
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("network error: (%d)", e.StatusCode)
+}
+
+// 传输方式
+const (
+	TransportGrpc = "grpc"
+	TransportHttp = "http"
+)
+
+// IsApiError 是否为网关业务错误
+func IsApiError(err error) bool {
+	var e *ApiError
+	return errors.As(err, &e)
+}
+
+// IsTransportError 是否为传输层错误
+func IsTransportError(err error) bool {
+	var e *TransportError
+	return errors.As(err, &e)
+}
+
+func apiError(code int32, message string) error {
+	return &ApiError{Code: code, Message: message}
+}
+
+// grpcStatusCodes 与传输无关的 gRPC 状态码及对应的 HTTP 状态码，其余状态码视为传输层错误
+var grpcStatusCodes = map[codes.Code]int32{
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.FailedPrecondition: http.StatusPreconditionFailed,
+	codes.Unimplemented:      http.StatusNotImplemented,
+}
+
+func grpcError(err error) error {
+	if errors.Is(err, ErrRateLimited) {
+		return err
+	}
+	if st, ok := status.FromError(err); ok {
+		if code, ok := grpcStatusCodes[st.Code()]; ok {
+			return &ApiError{Code: code, Message: st.Message(), err: err}
+		}
+	}
+	return &TransportError{Transport: TransportGrpc, Err: err}
+}
+
+func httpError(err error) error {
+	return &TransportError{Transport: TransportHttp, Err: err}
+}
+
+// unsentError sent 为 false 时将传输层错误标记为请求未发出
+func unsentError(err error, sent bool) error {
+	var e *TransportError
+	if !sent && errors.As(err, &e) {
+		e.unsent = true
+	}
+	return err
+}
+
+// requestUnsent 请求是否确定未离开客户端，此时换用其他传输方式重发不会重复下单
+func requestUnsent(err error) bool {
+	var e *TransportError
+	return errors.As(err, &e) && e.unsent
+}
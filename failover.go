@@ -0,0 +1,283 @@
+package xmpay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/stats"
+)
+
+var (
+	// ErrFailoverClient 故障切换客户端需要两个非 nil 的客户端
+	ErrFailoverClient = errors.New("failover client requires non-nil grpc and http clients")
+	// ErrOrderExists 下单请求可能已到达网关，查询确认订单已存在，未重发
+	ErrOrderExists = errors.New("order already exists at gateway")
+)
+
+// sentKey 请求是否已发出的 context 键
+type sentKey struct{}
+
+// withSentFlag 返回带发出标记的 context，gRPC 写出请求头或 HTTP 写出请求头后标记为 true
+func withSentFlag(ctx context.Context) (context.Context, *atomic.Bool) {
+	sent := new(atomic.Bool)
+	ctx = context.WithValue(ctx, sentKey{}, sent)
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteHeaders: func() { sent.Store(true) },
+	}), sent
+}
+
+// sentHandler 在 gRPC 请求头写出时设置 context 中的发出标记
+type sentHandler struct{}
+
+func (sentHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context { return ctx }
+
+func (sentHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	if _, ok := s.(*stats.OutHeader); !ok {
+		return
+	}
+	if sent, ok := ctx.Value(sentKey{}).(*atomic.Bool); ok {
+		sent.Store(true)
+	}
+}
+
+func (sentHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context { return ctx }
+
+func (sentHandler) HandleConn(context.Context, stats.ConnStats) {}
+
+// transportHealth 传输方式健康状态，连续传输错误达到阈值后在冷却期内视为不健康
+type transportHealth struct {
+	failures  int
+	downUntil time.Time
+}
+
+// FailoverClient 在 gRPC 与 HTTP 之间自动切换的客户端。
+// 仅传输层错误（TransportError）会切换到另一种传输方式重试，网关业务错误（ApiError）直接返回。
+// 下单请求只在确定未发出（连接未建立）时直接切换；请求可能已到达网关时，先用另一种传输方式按商户订单号
+// 查询，订单不存在（404）才重发，已存在时付款单返回查询结果，收款单返回 ErrOrderExists，虚拟账户不重发
+type FailoverClient struct {
+	clients map[string]PayClient
+	log     *logrus.Entry
+
+	Primary          string        // 首选传输方式，TransportGrpc 或 TransportHttp
+	FailureThreshold int           // 连续传输错误次数达到阈值后标记为不健康
+	Cooldown         time.Duration // 不健康状态持续时间，结束后重新尝试首选传输方式
+
+	mu     sync.Mutex
+	health map[string]*transportHealth
+}
+
+// NewFailoverClient 创建故障切换客户端，默认首选 gRPC，任一客户端为 nil（包括 nil 指针）时返回 ErrFailoverClient
+func NewFailoverClient(grpc, http PayClient, log *logrus.Entry) (*FailoverClient, error) {
+	if isNilClient(grpc) || isNilClient(http) {
+		return nil, ErrFailoverClient
+	}
+	if log == nil {
+		log = logrus.WithField("model", "FailoverClient")
+	}
+	return &FailoverClient{
+		clients: map[string]PayClient{
+			TransportGrpc: grpc,
+			TransportHttp: http,
+		},
+		log:              log,
+		Primary:          TransportGrpc,
+		FailureThreshold: 3,
+		Cooldown:         30 * time.Second,
+		health: map[string]*transportHealth{
+			TransportGrpc: {},
+			TransportHttp: {},
+		},
+	}, nil
+}
+
+// isNilClient 客户端是否为 nil 或包装了 nil 指针
+func isNilClient(c PayClient) bool {
+	if c == nil {
+		return true
+	}
+	v := reflect.ValueOf(c)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+// Healthy 传输方式当前是否健康
+func (f *FailoverClient) Healthy(transport string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	h, ok := f.health[transport]
+	return ok && !time.Now().Before(h.downUntil)
+}
+
+// order 返回本次调用的传输方式顺序，首选方式不健康且备选健康时优先使用备选
+func (f *FailoverClient) order() []string {
+	primary, secondary := TransportGrpc, TransportHttp
+	if f.Primary == TransportHttp {
+		primary, secondary = secondary, primary
+	}
+	if !f.Healthy(primary) && f.Healthy(secondary) {
+		return []string{secondary, primary}
+	}
+	return []string{primary, secondary}
+}
+
+func (f *FailoverClient) report(transport string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	h := f.health[transport]
+	if !IsTransportError(err) {
+		h.failures = 0
+		return
+	}
+	h.failures++
+	if f.FailureThreshold > 0 && h.failures >= f.FailureThreshold {
+		h.downUntil = time.Now().Add(f.Cooldown)
+		h.failures = 0
+		f.log.Warnf("transport %s marked unhealthy for %s", transport, f.Cooldown)
+	}
+}
+
+// failover 依次使用各传输方式调用，仅在传输层错误时切换
+func failover[T any](f *FailoverClient, op string, call func(c PayClient) (T, error)) (T, error) {
+	var (
+		result T
+		err    error
+	)
+	for i, transport := range f.order() {
+		client := f.clients[transport]
+		if i > 0 {
+			f.log.Warnf("%s failed over to %s, err: %v", op, transport, err)
+		}
+		result, err = call(client)
+		f.report(transport, err)
+		if !IsTransportError(err) {
+			return result, err
+		}
+	}
+	if err == nil {
+		err = fmt.Errorf("%s: no transport available", op)
+	}
+	return result, err
+}
+
+// failoverCreate 下单专用的故障切换：请求确定未发出时直接切换；可能已发出时先 lookup 查询，
+// 订单不存在才重发，lookup 为 nil 或查询失败时返回原错误
+func failoverCreate[T any](f *FailoverClient, op string, call, lookup func(c PayClient) (T, error)) (T, error) {
+	var (
+		result T
+		err    error
+		sent   bool // 之前的请求可能已到达网关
+	)
+	for i, transport := range f.order() {
+		client := f.clients[transport]
+		if sent {
+			if lookup == nil {
+				return result, err
+			}
+			found, lookupErr := lookup(client)
+			if lookupErr == nil || errors.Is(lookupErr, ErrOrderExists) {
+				f.log.Warnf("%s may have reached gateway, order exists, err: %v", op, err)
+				return found, lookupErr
+			}
+			if !orderNotExist(lookupErr) {
+				f.log.Errorf("%s may have reached gateway, lookup failed: %v, err: %v", op, lookupErr, err)
+				return result, err
+			}
+		}
+		if i > 0 {
+			f.log.Warnf("%s failed over to %s, err: %v", op, transport, err)
+		}
+		result, err = call(client)
+		f.report(transport, err)
+		if !IsTransportError(err) {
+			return result, err
+		}
+		sent = sent || !requestUnsent(err)
+	}
+	return result, err
+}
+
+// orderNotExist 查询结果是否表明网关上不存在该订单
+func orderNotExist(err error) bool {
+	var e *ApiError
+	return errors.Is(err, ErrOrderNotFound) || errors.As(err, &e) && e.Code == http.StatusNotFound
+}
+
+func (f *FailoverClient) CreateVirtual(param *OrderParam) (*pb.VirtualResp, error) {
+	return failoverCreate(f, CreateVirtual, func(c PayClient) (*pb.VirtualResp, error) {
+		return c.CreateVirtual(param)
+	}, nil)
+}
+
+func (f *FailoverClient) CreateReceive(param *ReceiveParam) (*pb.ReceiveResp, error) {
+	return failoverCreate(f, CreateReceive, func(c PayClient) (*pb.ReceiveResp, error) {
+		return c.CreateReceive(param)
+	}, func(c PayClient) (*pb.ReceiveResp, error) {
+		// 查询结果不含付款页面地址，无法还原下单响应
+		if _, err := lookupOrder(c.QueryReceive, param.OrderNo); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s", ErrOrderExists, param.OrderNo)
+	})
+}
+
+func (f *FailoverClient) QueryReceive(orderNo, trxNo string) (*pb.OrderQueryResp, error) {
+	return failover(f, QueryReceive, func(c PayClient) (*pb.OrderQueryResp, error) {
+		return c.QueryReceive(orderNo, trxNo)
+	})
+}
+
+func (f *FailoverClient) CreateOut(param *OutParam) (*pb.OutResp, error) {
+	return failoverCreate(f, CreateOut, func(c PayClient) (*pb.OutResp, error) {
+		return c.CreateOut(param)
+	}, func(c PayClient) (*pb.OutResp, error) {
+		order, err := lookupOrder(c.QueryOut, param.OrderNo)
+		if err != nil {
+			return nil, err
+		}
+		return &pb.OutResp{OrderNo: order.OrderNo, MerchantNo: order.MerchantNo}, nil
+	})
+}
+
+// lookupOrder 按商户订单号查询，订单号为空时无法确认
+func lookupOrder(query func(orderNo, trxNo string) (*pb.OrderQueryResp, error), merchantNo string) (*pb.OrderQueryResp, error) {
+	if merchantNo == "" {
+		return nil, errors.New("order no is empty, cannot confirm order state")
+	}
+	return query(merchantNo, "")
+}
+
+func (f *FailoverClient) QueryOut(orderNo, trxNo string) (*pb.OrderQueryResp, error) {
+	return failover(f, QueryOut, func(c PayClient) (*pb.OrderQueryResp, error) {
+		return c.QueryOut(orderNo, trxNo)
+	})
+}
+
+func (f *FailoverClient) Channel(orderType pb.ORDER_TYPE) ([]*pb.ChannelQueryResp, error) {
+	return failover(f, Channel, func(c PayClient) ([]*pb.ChannelQueryResp, error) {
+		return c.Channel(orderType)
+	})
+}
+
+func (f *FailoverClient) Balance() (*pb.MerchantBalanceResp, error) {
+	return failover(f, Balance, func(c PayClient) (*pb.MerchantBalanceResp, error) {
+		return c.Balance()
+	})
+}
+
+// Close 关闭底层客户端
+func (f *FailoverClient) Close() error {
+	for _, c := range f.clients {
+		closeClient(c)
+	}
+	return nil
+}
+
+var _ PayClient = (*FailoverClient)(nil)
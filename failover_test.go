@@ -0,0 +1,188 @@
+package xmpay
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// stubClient 按预设错误返回的 PayClient 桩，记录各方法的调用次数
+type stubClient struct {
+	PayClient
+	createErr error
+	queryErr  error
+	query     *pb.OrderQueryResp
+	creates   int
+	queries   int
+}
+
+func (s *stubClient) CreateOut(param *OutParam) (*pb.OutResp, error) {
+	s.creates++
+	if s.createErr != nil {
+		return nil, s.createErr
+	}
+	return &pb.OutResp{OrderNo: "T-" + param.OrderNo, MerchantNo: param.OrderNo}, nil
+}
+
+func (s *stubClient) CreateVirtual(param *OrderParam) (*pb.VirtualResp, error) {
+	s.creates++
+	if s.createErr != nil {
+		return nil, s.createErr
+	}
+	return &pb.VirtualResp{OrderNo: "T-" + param.OrderNo}, nil
+}
+
+func (s *stubClient) QueryOut(orderNo, trxNo string) (*pb.OrderQueryResp, error) {
+	s.queries++
+	return s.query, s.queryErr
+}
+
+func newTestFailover(t *testing.T, grpc, http *stubClient) *FailoverClient {
+	t.Helper()
+	f, err := NewFailoverClient(grpc, http, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestFailoverCreate(t *testing.T) {
+	unsent := unsentError(httpError(errors.New("connection refused")), false)
+	timeout := unsentError(grpcError(status.Error(codes.DeadlineExceeded, "deadline exceeded")), true)
+	notFound := apiError(http.StatusNotFound, "order not found")
+
+	tests := []struct {
+		name          string
+		primaryErr    error
+		query         *pb.OrderQueryResp
+		queryErr      error
+		wantErr       error
+		wantOrderNo   string
+		wantCreates   int // 备选传输方式的下单次数
+		wantQueries   int
+		wantTransport bool
+	}{
+		{name: "unsent fails over", primaryErr: unsent, wantOrderNo: "T-M1", wantCreates: 1},
+		{name: "sent and order exists", primaryErr: timeout, query: &pb.OrderQueryResp{OrderNo: "T-G", MerchantNo: "M1"},
+			wantOrderNo: "T-G", wantQueries: 1},
+		{name: "sent and order not found", primaryErr: timeout, queryErr: notFound, wantOrderNo: "T-M1", wantCreates: 1, wantQueries: 1},
+		{name: "sent and lookup failed", primaryErr: timeout, queryErr: httpError(errors.New("eof")), wantQueries: 1, wantTransport: true},
+		{name: "api error not retried", primaryErr: apiError(http.StatusBadRequest, "bad"), wantErr: &ApiError{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &stubClient{createErr: tt.primaryErr}
+			secondary := &stubClient{query: tt.query, queryErr: tt.queryErr}
+			f := newTestFailover(t, primary, secondary)
+			resp, err := f.CreateOut(&OutParam{OrderParam: OrderParam{OrderNo: "M1"}})
+
+			switch {
+			case tt.wantTransport:
+				if !errors.Is(err, tt.primaryErr) {
+					t.Errorf("err = %v, want original %v", err, tt.primaryErr)
+				}
+			case tt.wantErr != nil:
+				if !IsApiError(err) {
+					t.Errorf("err = %v, want api error", err)
+				}
+			case err != nil:
+				t.Fatalf("unexpected err: %v", err)
+			case resp.OrderNo != tt.wantOrderNo:
+				t.Errorf("order no = %q, want %q", resp.OrderNo, tt.wantOrderNo)
+			}
+			if primary.creates != 1 {
+				t.Errorf("primary creates = %d, want 1", primary.creates)
+			}
+			if secondary.creates != tt.wantCreates || secondary.queries != tt.wantQueries {
+				t.Errorf("secondary creates = %d, queries = %d, want %d, %d",
+					secondary.creates, secondary.queries, tt.wantCreates, tt.wantQueries)
+			}
+		})
+	}
+}
+
+func TestFailoverVirtualNotResent(t *testing.T) {
+	primary := &stubClient{createErr: unsentError(grpcError(status.Error(codes.Unavailable, "reset")), true)}
+	secondary := &stubClient{}
+	f := newTestFailover(t, primary, secondary)
+	if _, err := f.CreateVirtual(&OrderParam{OrderNo: "M1"}); !IsTransportError(err) {
+		t.Fatalf("err = %v, want transport error", err)
+	}
+	if secondary.creates != 0 {
+		t.Errorf("virtual account resent %d times", secondary.creates)
+	}
+}
+
+func TestNewFailoverClientNil(t *testing.T) {
+	var grpcClient *GrpcClient
+	if _, err := NewFailoverClient(grpcClient, &stubClient{}, nil); !errors.Is(err, ErrFailoverClient) {
+		t.Errorf("typed nil: err = %v", err)
+	}
+	if _, err := NewFailoverClient(&stubClient{}, nil, nil); !errors.Is(err, ErrFailoverClient) {
+		t.Errorf("nil: err = %v", err)
+	}
+}
+
+func TestGrpcErrorClassification(t *testing.T) {
+	tests := []struct {
+		code      codes.Code
+		transport bool
+	}{
+		{codes.Unavailable, true},
+		{codes.DeadlineExceeded, true},
+		{codes.Internal, true},
+		{codes.InvalidArgument, false},
+		{codes.PermissionDenied, false},
+		{codes.Unauthenticated, false},
+		{codes.NotFound, false},
+		{codes.Unimplemented, false},
+	}
+	for _, tt := range tests {
+		err := grpcError(status.Error(tt.code, tt.code.String()))
+		if IsTransportError(err) != tt.transport || IsApiError(err) == tt.transport {
+			t.Errorf("%s: transport = %v, api = %v", tt.code, IsTransportError(err), IsApiError(err))
+		}
+		if status.Code(err) != tt.code {
+			t.Errorf("%s: status code lost, got %s", tt.code, status.Code(err))
+		}
+	}
+}
+
+func TestRequestUnsent(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := lis.Addr().String()
+	_ = lis.Close()
+
+	hc := NewHttpClient(testConfig("http://"+closed), testLogger())
+	if _, err = hc.Balance(); !requestUnsent(err) {
+		t.Errorf("http connection refused: unsent = false, err: %v", err)
+	}
+	gc, err := NewGrpcClient(testConfig(closed), testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gc.Close()
+	if _, err = gc.Balance(); !requestUnsent(err) {
+		t.Errorf("grpc connection refused: unsent = false, err: %v", err)
+	}
+
+	// 读取请求后断开连接，请求已发出
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		_ = conn.Close()
+	}))
+	defer server.Close()
+	hc = NewHttpClient(testConfig(server.URL), testLogger())
+	if _, err = hc.Balance(); !IsTransportError(err) || requestUnsent(err) {
+		t.Errorf("http hang up: transport = %v, unsent = %v, err: %v", IsTransportError(err), requestUnsent(err), err)
+	}
+}
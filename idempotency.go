@@ -0,0 +1,175 @@
+package xmpay
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrIdempotencyConflict 同一业务键的请求内容与首次请求不同
+	ErrIdempotencyConflict = errors.New("idempotency key reused with a different request")
+	// ErrIdempotencyPending 同一业务键的请求正在其他实例处理，稍后重试将返回其结果
+	ErrIdempotencyPending = errors.New("idempotency key in progress")
+)
+
+// IdempotencyStore 幂等记录存储，同一业务键重复下单时返回首次的响应。
+// data 为客户端编码的记录（含请求摘要和响应），存储只需原样保存
+type IdempotencyStore interface {
+	// Claim 原子地占用业务键：已有记录时返回记录；被其他请求占用时 data 为 nil、claimed 为 false；
+	// 否则写入处理中标记并返回 claimed 为 true。多实例共享的存储应使用 SETNX 等原子操作，
+	// 并为处理中标记设置过期时间，避免实例崩溃后业务键永久占用
+	Claim(key string) (data []byte, claimed bool, err error)
+	// Save 保存业务键对应的响应数据，替换处理中标记
+	Save(key string, data []byte) error
+	// Release 删除处理中标记，下单失败时调用，同一业务键可再次提交
+	Release(key string) error
+}
+
+type idempotencyItem struct {
+	data     []byte // 为 nil 时表示处理中
+	expireAt time.Time
+}
+
+// MemoryIdempotencyStore 基于内存的幂等存储，仅适用于单实例部署
+type MemoryIdempotencyStore struct {
+	ttl   time.Duration
+	mu    sync.Mutex
+	items map[string]idempotencyItem
+}
+
+// NewMemoryIdempotencyStore 创建内存幂等存储，ttl 小于等于0时记录永不过期
+func NewMemoryIdempotencyStore(ttl time.Duration) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		ttl:   ttl,
+		items: make(map[string]idempotencyItem),
+	}
+}
+
+func (s *MemoryIdempotencyStore) Claim(key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[key]
+	if ok && !item.expireAt.IsZero() && time.Now().After(item.expireAt) {
+		delete(s.items, key)
+		ok = false
+	}
+	if ok {
+		return item.data, false, nil
+	}
+	s.items[key] = idempotencyItem{}
+	return nil, true, nil
+}
+
+func (s *MemoryIdempotencyStore) Save(key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item := idempotencyItem{data: data}
+	if s.ttl > 0 {
+		item.expireAt = time.Now().Add(s.ttl)
+	}
+	s.items[key] = item
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if item, ok := s.items[key]; ok && item.data == nil {
+		delete(s.items, key)
+	}
+	return nil
+}
+
+// idempotencyKey 返回下单的业务键，未指定时使用商户订单号
+func idempotencyKey(param *OrderParam) string {
+	if param.IdempotencyKey != "" {
+		return param.IdempotencyKey
+	}
+	return param.OrderNo
+}
+
+// idempotencyRecord 幂等记录，Hash 为首次请求内容的摘要
+type idempotencyRecord struct {
+	Hash string          `json:"hash"`
+	Data json.RawMessage `json:"data"`
+}
+
+// requestHash 请求内容的 SHA-256 摘要，调用方需先清除业务键和自动生成的单号
+func requestHash(req interface{}) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// idempotent 在同一业务键上串行执行下单，已有记录且请求内容一致时直接返回首次响应，
+// 请求内容不同时返回 ErrIdempotencyConflict，其他实例处理中时返回 ErrIdempotencyPending。
+// req 为参与比较的请求内容，result 为指向响应指针的指针，与 json.Unmarshal 的参数一致
+func (c *PayClientImpl) idempotent(scope, key string, req, result interface{}, create func() error) error {
+	if c.idempotency == nil || key == "" {
+		return create()
+	}
+	key = scope + ":" + key
+	hash, err := requestHash(req)
+	if err != nil {
+		return err
+	}
+
+	unlock := c.locks.lock(key)
+	defer unlock()
+
+	data, claimed, err := c.idempotency.Claim(key)
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		var record idempotencyRecord
+		if err = json.Unmarshal(data, &record); err != nil {
+			return err
+		}
+		if record.Hash != hash {
+			return fmt.Errorf("%w: %s", ErrIdempotencyConflict, key)
+		}
+		c.log.Debugf("idempotency hit: %s", key)
+		return json.Unmarshal(record.Data, result)
+	}
+	if !claimed {
+		return fmt.Errorf("%w: %s", ErrIdempotencyPending, key)
+	}
+
+	if err = create(); err != nil {
+		if releaseErr := c.idempotency.Release(key); releaseErr != nil {
+			c.log.Errorf("idempotency release failed, key: %s, err: %v", key, releaseErr)
+		}
+		return err
+	}
+	resp, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	if data, err = json.Marshal(&idempotencyRecord{Hash: hash, Data: resp}); err != nil {
+		return err
+	}
+	if err = c.idempotency.Save(key, data); err != nil {
+		// 订单已创建成功，保存失败只记录日志
+		c.log.Errorf("idempotency save failed, key: %s, err: %v", key, err)
+	}
+	return nil
+}
+
+// fillOrderNo 未指定订单号时使用生成器生成
+func (c *PayClientImpl) fillOrderNo(param *OrderParam) {
+	if param.OrderNo == "" && c.orderNo != nil {
+		param.OrderNo = c.orderNo.Next()
+	}
+}
@@ -0,0 +1,102 @@
+package xmpay
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+func TestIdempotentReplay(t *testing.T) {
+	store := NewMemoryIdempotencyStore(0)
+	var requests int
+	c := newReplyClient(t, &pb.ReceiveResp{OrderNo: "T1", MerchantNo: "M1"}, &requests, WithIdempotencyStore(store))
+
+	param := &ReceiveParam{OrderParam: OrderParam{OrderNo: "M1", Amount: 100, Uid: "u1"}}
+	first, err := c.CreateReceive(param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := c.CreateReceive(&ReceiveParam{OrderParam: OrderParam{OrderNo: "M1", Amount: 100, Uid: "u1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 || again.OrderNo != first.OrderNo {
+		t.Errorf("requests = %d, replay = %v", requests, again)
+	}
+
+	// 同一业务键、不同的请求内容
+	_, err = c.CreateReceive(&ReceiveParam{OrderParam: OrderParam{OrderNo: "M1", Amount: 200, Uid: "u1"}})
+	if !errors.Is(err, ErrIdempotencyConflict) {
+		t.Errorf("err = %v, want conflict", err)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
+
+func TestIdempotentGeneratedOrderNo(t *testing.T) {
+	gen, err := NewOrderNoGenerator("M", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests int
+	c := newReplyClient(t, &pb.OutResp{OrderNo: "T1"}, &requests,
+		WithIdempotencyStore(NewMemoryIdempotencyStore(0)), WithOrderNoGenerator(gen))
+
+	// 首次调用写回生成的订单号，使用同一参数重试仍视为同一请求
+	param := &OutParam{OrderParam: OrderParam{Amount: 100, IdempotencyKey: "biz-1"}, BankNo: "6222"}
+	if _, err = c.CreateOut(param); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.CreateOut(param); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+	param.BankNo = "6223"
+	if _, err = c.CreateOut(param); !errors.Is(err, ErrIdempotencyConflict) {
+		t.Errorf("err = %v, want conflict", err)
+	}
+}
+
+func TestIdempotentSharedStore(t *testing.T) {
+	store := NewMemoryIdempotencyStore(0)
+	var requests int
+	c := newReplyClient(t, &pb.OutResp{OrderNo: "T1"}, &requests, WithIdempotencyStore(store))
+	param := &OutParam{OrderParam: OrderParam{OrderNo: "M1", Amount: 100}, BankNo: "6222"}
+
+	// 另一实例已占用业务键且尚未完成，不重复下单
+	if _, claimed, _ := store.Claim(CreateOut + ":M1"); !claimed {
+		t.Fatal("claim failed")
+	}
+	if _, err := c.CreateOut(param); !errors.Is(err, ErrIdempotencyPending) {
+		t.Errorf("err = %v, want pending", err)
+	}
+	if requests != 0 {
+		t.Fatalf("requests = %d, want 0", requests)
+	}
+
+	// 另一实例下单失败释放业务键后可再次提交
+	_ = store.Release(CreateOut + ":M1")
+	if _, err := c.CreateOut(param); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
+
+func TestIdempotentReleaseOnError(t *testing.T) {
+	store := NewMemoryIdempotencyStore(0)
+	c := NewHttpClient(testConfig("http://127.0.0.1:1"), testLogger(), WithIdempotencyStore(store))
+	param := &OutParam{OrderParam: OrderParam{OrderNo: "M1", Amount: 100}}
+	if _, err := c.CreateOut(param); err == nil {
+		t.Fatal("expected error")
+	}
+	// 下单失败不保留处理中标记
+	if data, claimed, _ := store.Claim(CreateOut + ":M1"); data != nil || !claimed {
+		t.Errorf("key still held, data = %s, claimed = %v", data, claimed)
+	}
+}
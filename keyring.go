@@ -0,0 +1,144 @@
+package xmpay
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+var ErrDecryptKey = errors.New("no key could decrypt the data")
+
+// keyring 主密钥和仍被接受的旧密钥，请求使用主密钥加密，解密依次尝试
+type keyring struct {
+	primary  *credentials
+	previous []*credentials
+}
+
+func (r *keyring) all() []*credentials {
+	return append([]*credentials{r.primary}, r.previous...)
+}
+
+// rotate 返回以 next 为主密钥的密钥环，原主密钥和旧密钥依次保留为旧密钥，
+// 去除与 next 相同、重复和空的密钥，最多保留 maxPreviousKeys 个
+func (r *keyring) rotate(next *credentials) *keyring {
+	rotated := &keyring{primary: next}
+	if r == nil {
+		return rotated
+	}
+	seen := map[Credentials]bool{{AccessId: next.accessId, AccessKey: next.accessKey}: true}
+	for _, cred := range r.all() {
+		k := Credentials{AccessId: cred.accessId, AccessKey: cred.accessKey}
+		if k.AccessId == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		if rotated.previous = append(rotated.previous, cred); len(rotated.previous) == maxPreviousKeys {
+			break
+		}
+	}
+	return rotated
+}
+
+// credentials 返回当前主密钥
+func (c *PayClientImpl) credentials() *credentials {
+	if r := c.keys.Load(); r != nil {
+		return r.primary
+	}
+	return nil
+}
+
+// setCredentials 使用配置中的密钥初始化，密钥非法时在首次请求时报错
+func (c *PayClientImpl) setCredentials(accessId, accessKey string) {
+	c.keys.Store(&keyring{primary: &credentials{
+		accessId:  accessId,
+		accessKey: accessKey,
+		aes:       NewAES([]byte(accessId), []byte(accessKey)),
+	}})
+}
+
+// SetKeys 原子替换密钥，primary 用于请求加密，previous 为轮换期间仍接受的旧密钥，
+// 解密时按 primary、previous 的顺序尝试
+func (c *PayClientImpl) SetKeys(primary Credentials, previous ...Credentials) error {
+	r := &keyring{}
+	var err error
+	if r.primary, err = newCredentials(primary.AccessId, primary.AccessKey); err != nil {
+		return err
+	}
+	for _, p := range previous {
+		cred, err := newCredentials(p.AccessId, p.AccessKey)
+		if err != nil {
+			return err
+		}
+		r.previous = append(r.previous, cred)
+	}
+	c.keys.Store(r)
+	c.log.Infof("keys replaced, primary: %s, previous: %d", maskSecret(r.primary.accessId), len(r.previous))
+	return nil
+}
+
+// maxPreviousKeys 轮换时最多保留的旧密钥数
+const maxPreviousKeys = 3
+
+// mergeKeys 返回轮换为 primary 后保留的旧密钥，按 old 的顺序去重并去除 primary 和空密钥
+func mergeKeys(primary Credentials, old ...Credentials) []Credentials {
+	var previous []Credentials
+	for _, k := range old {
+		if k.AccessId == "" || k == primary || containsKey(previous, k) {
+			continue
+		}
+		if previous = append(previous, k); len(previous) == maxPreviousKeys {
+			break
+		}
+	}
+	return previous
+}
+
+func containsKey(keys []Credentials, k Credentials) bool {
+	for _, key := range keys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Keys 返回当前主密钥和旧密钥
+func (c *PayClientImpl) Keys() (Credentials, []Credentials) {
+	r := c.keys.Load()
+	previous := make([]Credentials, 0, len(r.previous))
+	for _, p := range r.previous {
+		previous = append(previous, Credentials{AccessId: p.accessId, AccessKey: p.accessKey})
+	}
+	return Credentials{AccessId: r.primary.accessId, AccessKey: r.primary.accessKey}, previous
+}
+
+// DecryptKey 依次使用主密钥和旧密钥解密，返回明文和成功的密钥序号（0 为主密钥，i 为第 i 个旧密钥）。
+// 密文须能以合法填充解密为 JSON 才视为成功，避免旧密钥误解出乱码
+func (c *PayClientImpl) DecryptKey(body []byte) ([]byte, int, error) {
+	return c.decryptWith(c.keys.Load(), "", body)
+}
+
+// decryptWith 使用密钥环解密，appKey 非空时只尝试 AccessId 与之相同的密钥
+func (c *PayClientImpl) decryptWith(r *keyring, appKey string, body []byte) ([]byte, int, error) {
+	var lastErr error
+	for i, cred := range r.all() {
+		if appKey != "" && cred.accessId != appKey {
+			continue
+		}
+		data, err := cred.aes.Decrypt(body)
+		if err == nil && !json.Valid(data) {
+			err = errors.New("decrypted data is not json")
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if i > 0 {
+			c.log.Warnf("data decrypted with previous key %d (%s)", i, maskSecret(cred.accessId))
+		}
+		return data, i, nil
+	}
+	if lastErr == nil {
+		return nil, -1, ErrCallbackAppKey
+	}
+	return nil, -1, errors.Join(ErrDecryptKey, lastErr)
+}
@@ -0,0 +1,131 @@
+package xmpay
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+var (
+	keyA = Credentials{AccessId: "aaaaaaaaaaaaaaaa", AccessKey: "1111111111111111"}
+	keyB = Credentials{AccessId: "bbbbbbbbbbbbbbbb", AccessKey: "2222222222222222"}
+	keyC = Credentials{AccessId: "cccccccccccccccc", AccessKey: "3333333333333333"}
+	keyD = Credentials{AccessId: "dddddddddddddddd", AccessKey: "4444444444444444"}
+)
+
+func TestSetKeys(t *testing.T) {
+	c := NewHttpClient(testConfig("http://127.0.0.1:1"), testLogger())
+	if err := c.SetKeys(keyA, keyB, keyC); err != nil {
+		t.Fatal(err)
+	}
+	primary, previous := c.Keys()
+	if primary != keyA || len(previous) != 2 || previous[0] != keyB || previous[1] != keyC {
+		t.Fatalf("keys = %v, %v", primary, previous)
+	}
+
+	// 非法密钥不替换当前密钥
+	for _, invalid := range [][]Credentials{
+		{{AccessId: "short", AccessKey: keyA.AccessKey}},
+		{keyA, {AccessId: keyB.AccessId, AccessKey: "short"}},
+	} {
+		if err := c.SetKeys(invalid[0], invalid[1:]...); err == nil {
+			t.Errorf("invalid keys accepted: %v", invalid)
+		}
+	}
+	if primary, _ = c.Keys(); primary != keyA {
+		t.Errorf("primary = %v", primary)
+	}
+}
+
+func TestDecryptKeyOrder(t *testing.T) {
+	c := NewHttpClient(testConfig("http://127.0.0.1:1"), testLogger())
+	if err := c.SetKeys(keyA, keyB, keyC); err != nil {
+		t.Fatal(err)
+	}
+	for want, key := range []Credentials{keyA, keyB, keyC} {
+		var p pb.PayRpcParam
+		body := callbackBody(t, key.AccessId, key.AccessKey, &pb.CallbackParam{MerchantNo: "M1"})
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Fatal(err)
+		}
+		if _, index, err := c.DecryptKey([]byte(p.Data)); err != nil || index != want {
+			t.Errorf("%s: index = %d, err = %v, want %d", key.AccessId, index, err, want)
+		}
+	}
+
+	// 不属于密钥环的密钥无法解密
+	body := callbackBody(t, keyD.AccessId, keyD.AccessKey, &pb.CallbackParam{MerchantNo: "M1"})
+	if _, err := c.ParseCallback(body); err == nil {
+		t.Error("callback with unknown key parsed")
+	}
+}
+
+func TestDecryptWithAppKey(t *testing.T) {
+	c := NewHttpClient(testConfig("http://127.0.0.1:1"), testLogger())
+	if err := c.SetKeys(keyA, keyB); err != nil {
+		t.Fatal(err)
+	}
+	var p pb.PayRpcParam
+	if err := json.Unmarshal(callbackBody(t, keyB.AccessId, keyB.AccessKey, &pb.CallbackParam{}), &p); err != nil {
+		t.Fatal(err)
+	}
+	// 只尝试 AccessId 与 app_key 相同的密钥
+	if _, index, err := c.decryptWith(c.keys.Load(), keyB.AccessId, []byte(p.Data)); err != nil || index != 1 {
+		t.Errorf("index = %d, err = %v", index, err)
+	}
+	if _, _, err := c.decryptWith(c.keys.Load(), keyA.AccessId, []byte(p.Data)); !errors.Is(err, ErrDecryptKey) {
+		t.Errorf("wrong key, err = %v", err)
+	}
+	if _, _, err := c.decryptWith(c.keys.Load(), keyD.AccessId, []byte(p.Data)); !errors.Is(err, ErrCallbackAppKey) {
+		t.Errorf("unknown app key, err = %v", err)
+	}
+}
+
+func TestRefreshCredentialsKeepsPreviousKeys(t *testing.T) {
+	secrets := &testSecrets{cred: keyA}
+	c, err := NewHttpClientE(testConfig("http://127.0.0.1:1"), testLogger(), WithSecretProvider(secrets))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// SetKeys 设置的旧密钥在提供者轮换后仍保留
+	if err = c.SetKeys(keyA, keyB); err != nil {
+		t.Fatal(err)
+	}
+	secrets.cred = keyC
+	if err = c.RefreshCredentials(); err != nil {
+		t.Fatal(err)
+	}
+	primary, previous := c.Keys()
+	if primary != keyC || len(previous) != 2 || previous[0] != keyA || previous[1] != keyB {
+		t.Fatalf("keys = %v, %v", primary, previous)
+	}
+
+	// 轮换回旧密钥时不重复保留，旧密钥数量有上限
+	secrets.cred = keyB
+	_ = c.RefreshCredentials()
+	if primary, previous = c.Keys(); primary != keyB || len(previous) != 2 || previous[0] != keyC || previous[1] != keyA {
+		t.Fatalf("keys = %v, %v", primary, previous)
+	}
+	if err = c.SetKeys(keyA, keyB, keyC); err != nil {
+		t.Fatal(err)
+	}
+	secrets.cred = keyD
+	_ = c.RefreshCredentials()
+	if _, previous = c.Keys(); len(previous) != maxPreviousKeys || previous[0] != keyA {
+		t.Errorf("previous = %v", previous)
+	}
+}
+
+func TestRefreshCredentialsDropsEmptyConfigKey(t *testing.T) {
+	config := testConfig("http://127.0.0.1:1")
+	config.AccessId, config.AccessKey = "", ""
+	c, err := NewHttpClientE(config, testLogger(), WithSecretProvider(StaticSecretProvider(keyA)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if primary, previous := c.Keys(); primary != keyA || len(previous) != 0 {
+		t.Errorf("keys = %v, %v", primary, previous)
+	}
+}
@@ -0,0 +1,166 @@
+package xmpay
+
+import (
+	"errors"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+// ListOrders 订单列表查询的 HTTP 路径，同时作为限流的操作名
+const ListOrders = "/gateway/api/order/list"
+
+// DefaultListLimit 订单列表默认每页数量
+const DefaultListLimit = 100
+
+// ErrListCursor 网关返回的下一页游标与当前页相同，继续翻页会陷入循环
+var ErrListCursor = errors.New("list orders cursor did not advance")
+
+// ListParam 订单列表查询参数
+type ListParam struct {
+	OrderType pb.ORDER_TYPE     // 订单类型，ALL 表示全部
+	Status    []pb.ORDER_STATUS // 订单状态，为空表示全部
+	Start     time.Time         // 创建时间起（包含）
+	End       time.Time         // 创建时间止（不包含），零值表示不限
+	Pid       int32             // 通道ID，0 表示全部
+	Cursor    string            // 分页游标，为空时从第一页开始
+	Limit     int32             // 每页数量，0 时使用 DefaultListLimit
+}
+
+// request 按客户端的时间戳单位转换时间范围，单位为自动识别且设置了时间范围时返回 ErrTimeUnitAuto
+func (p *ListParam) request(times TimeSettings) (*pb.ListOrdersParam, error) {
+	limit := p.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	start, err := times.FormatTimestamp(p.Start)
+	if err != nil {
+		return nil, err
+	}
+	end, err := times.FormatTimestamp(p.End)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListOrdersParam{
+		OrderType: p.OrderType,
+		Status:    p.Status,
+		StartTime: start,
+		EndTime:   end,
+		Pid:       p.Pid,
+		Cursor:    p.Cursor,
+		Limit:     limit,
+	}, nil
+}
+
+// OrderLister 分页查询订单列表，HttpClient 与 GrpcClient 均已实现
+type OrderLister interface {
+	ListOrders(param *ListParam) (*pb.ListOrdersResp, error)
+}
+
+var (
+	_ OrderLister = (*HttpClient)(nil)
+	_ OrderLister = (*GrpcClient)(nil)
+)
+
+// ListOrders 查询一页订单，NextCursor 为空表示没有更多
+func (c *GrpcClient) ListOrders(param *ListParam) (*pb.ListOrdersResp, error) {
+	req, err := param.request(c.times)
+	if err != nil {
+		return nil, err
+	}
+	return call(c, opListOrders, req)
+}
+
+// Orders 返回遍历所有分页的订单迭代器
+func (c *GrpcClient) Orders(param *ListParam) *OrderIterator {
+	return NewOrderIterator(c, param)
+}
+
+// ListOrders 查询一页订单，NextCursor 为空表示没有更多
+func (c *HttpClient) ListOrders(param *ListParam) (*pb.ListOrdersResp, error) {
+	req, err := param.request(c.times)
+	if err != nil {
+		return nil, err
+	}
+	return call(c, opListOrders, req)
+}
+
+// Orders 返回遍历所有分页的订单迭代器
+func (c *HttpClient) Orders(param *ListParam) *OrderIterator {
+	return NewOrderIterator(c, param)
+}
+
+// OrderIterator 订单列表迭代器，按需逐页查询
+//
+//	it := client.Orders(param)
+//	for it.Next() {
+//		order := it.Order()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type OrderIterator struct {
+	lister OrderLister
+	param  ListParam
+
+	page  []*pb.OrderListItem
+	index int
+	next  string // 下一页游标
+	last  bool   // 当前页是否为最后一页
+	order *pb.OrderListItem
+	err   error
+}
+
+// NewOrderIterator 创建订单列表迭代器，从 param.Cursor 所在页开始
+func NewOrderIterator(lister OrderLister, param *ListParam) *OrderIterator {
+	return &OrderIterator{lister: lister, param: *param, next: param.Cursor}
+}
+
+// Next 移动到下一个订单，没有更多订单或查询失败时返回 false
+func (it *OrderIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for it.index >= len(it.page) {
+		if it.last {
+			it.order = nil
+			return false
+		}
+		if !it.fetch() {
+			return false
+		}
+	}
+	it.order = it.page[it.index]
+	it.index++
+	return true
+}
+
+func (it *OrderIterator) fetch() bool {
+	it.param.Cursor = it.next
+	resp, err := it.lister.ListOrders(&it.param)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	if resp.NextCursor != "" && resp.NextCursor == it.param.Cursor {
+		it.err = ErrListCursor
+		return false
+	}
+	it.page, it.index = resp.Orders, 0
+	it.next, it.last = resp.NextCursor, resp.NextCursor == ""
+	return true
+}
+
+// Order 返回当前订单
+func (it *OrderIterator) Order() *pb.OrderListItem {
+	return it.order
+}
+
+// Cursor 返回当前页的游标，中断后以该游标重新创建迭代器可从当前页继续
+func (it *OrderIterator) Cursor() string {
+	return it.param.Cursor
+}
+
+// Err 返回迭代过程中的错误
+func (it *OrderIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,162 @@
+package xmpay
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+// listPage 网关按游标返回的一页订单
+type listPage struct {
+	orders []string
+	next   string
+}
+
+// listGateway 按游标返回 pages 的订单列表网关，failing 中的游标返回业务错误
+type listGateway struct {
+	mu       sync.Mutex
+	pages    map[string]listPage
+	failing  map[string]bool
+	requests []*pb.ListOrdersParam
+}
+
+func newListClient(t *testing.T, g *listGateway, opts ...Option) *HttpClient {
+	t.Helper()
+	aes := NewAES([]byte(testAccessId), []byte(testAccessKey))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var param pb.PayRpcParam
+		var req pb.ListOrdersParam
+		_ = json.NewDecoder(r.Body).Decode(&param)
+		data, err := aes.Decrypt([]byte(param.Data))
+		if err == nil {
+			err = json.Unmarshal(data, &req)
+		}
+		if err != nil || r.URL.Path != ListOrders {
+			_ = json.NewEncoder(w).Encode(&pb.PayRpcResp{Code: http.StatusBadRequest, Message: "invalid param"})
+			return
+		}
+
+		g.mu.Lock()
+		g.requests = append(g.requests, &req)
+		page, ok := g.pages[req.Cursor]
+		failing := g.failing[req.Cursor]
+		g.mu.Unlock()
+		if !ok || failing {
+			_ = json.NewEncoder(w).Encode(&pb.PayRpcResp{Code: http.StatusBadRequest, Message: "invalid cursor"})
+			return
+		}
+		resp := &pb.ListOrdersResp{NextCursor: page.next}
+		for _, no := range page.orders {
+			resp.Orders = append(resp.Orders, &pb.OrderListItem{MerchantNo: no})
+		}
+		data, _ = json.Marshal(resp)
+		encrypted, _ := aes.Encrypt(data)
+		_ = json.NewEncoder(w).Encode(&pb.PayRpcResp{Code: http.StatusOK, Data: encrypted})
+	}))
+	t.Cleanup(server.Close)
+	return NewHttpClient(testConfig(server.URL), testLogger(), opts...)
+}
+
+func collect(it *OrderIterator) []string {
+	var nos []string
+	for it.Next() {
+		nos = append(nos, it.Order().MerchantNo)
+	}
+	return nos
+}
+
+func equalOrders(got []string, want ...string) bool {
+	return equalStrings(got, want)
+}
+
+func TestOrderIteratorPagination(t *testing.T) {
+	g := &listGateway{pages: map[string]listPage{
+		"":   {orders: []string{"M1", "M2"}, next: "c1"},
+		"c1": {orders: nil, next: "c2"}, // 空页不结束遍历
+		"c2": {orders: []string{"M3"}},
+	}}
+	c := newListClient(t, g)
+
+	it := c.Orders(&ListParam{OrderType: pb.ORDER_TYPE_OUT, Limit: 2})
+	if got := collect(it); !equalOrders(got, "M1", "M2", "M3") || it.Err() != nil {
+		t.Fatalf("orders = %v, err = %v", got, it.Err())
+	}
+	if len(g.requests) != 3 || g.requests[0].Limit != 2 || g.requests[0].OrderType != pb.ORDER_TYPE_OUT {
+		t.Fatalf("requests = %v", g.requests)
+	}
+	// 到达末页后不再请求
+	if it.Next() || it.Order() != nil || len(g.requests) != 3 {
+		t.Errorf("next after end: order = %v, requests = %d", it.Order(), len(g.requests))
+	}
+
+	// 默认每页数量
+	if _, err := c.ListOrders(&ListParam{}); err != nil || g.requests[3].Limit != DefaultListLimit {
+		t.Errorf("limit = %d, err = %v", g.requests[3].Limit, err)
+	}
+}
+
+func TestOrderIteratorResume(t *testing.T) {
+	g := &listGateway{
+		pages: map[string]listPage{
+			"":   {orders: []string{"M1"}, next: "c1"},
+			"c1": {orders: []string{"M2"}, next: "c2"},
+			"c2": {orders: []string{"M3"}},
+		},
+		failing: map[string]bool{"c1": true},
+	}
+	c := newListClient(t, g)
+
+	it := c.Orders(&ListParam{})
+	if got := collect(it); !equalOrders(got, "M1") || !IsApiError(it.Err()) {
+		t.Fatalf("orders = %v, err = %v", got, it.Err())
+	}
+	// 出错后迭代器停止，游标指向失败的页
+	if it.Next() || it.Cursor() != "c1" {
+		t.Fatalf("cursor = %q", it.Cursor())
+	}
+
+	g.mu.Lock()
+	g.failing = nil
+	g.mu.Unlock()
+	resumed := c.Orders(&ListParam{Cursor: it.Cursor()})
+	if got := collect(resumed); !equalOrders(got, "M2", "M3") || resumed.Err() != nil {
+		t.Errorf("resumed orders = %v, err = %v", got, resumed.Err())
+	}
+}
+
+func TestOrderIteratorCursorLoop(t *testing.T) {
+	g := &listGateway{pages: map[string]listPage{
+		"":   {orders: []string{"M1"}, next: "c1"},
+		"c1": {orders: []string{"M2"}, next: "c1"},
+	}}
+	it := newListClient(t, g).Orders(&ListParam{})
+	if got := collect(it); !equalOrders(got, "M1") || !errors.Is(it.Err(), ErrListCursor) {
+		t.Errorf("orders = %v, err = %v", got, it.Err())
+	}
+	if len(g.requests) != 2 {
+		t.Errorf("requests = %d, want 2", len(g.requests))
+	}
+}
+
+func TestListOrdersTimeRange(t *testing.T) {
+	g := &listGateway{pages: map[string]listPage{"": {}}}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, Location())
+	param := &ListParam{Start: start, End: start.Add(24 * time.Hour)}
+
+	// 未设置时间戳单位时不发送请求
+	if _, err := newListClient(t, g).ListOrders(param); !errors.Is(err, ErrTimeUnitAuto) || len(g.requests) != 0 {
+		t.Fatalf("err = %v, requests = %d", err, len(g.requests))
+	}
+	if _, err := newListClient(t, g, WithTimeUnit(TimeUnitSecond)).ListOrders(param); err != nil {
+		t.Fatal(err)
+	}
+	if req := g.requests[0]; req.StartTime != start.Unix() || req.EndTime != start.Add(24*time.Hour).Unix() {
+		t.Errorf("time range = %d, %d", req.StartTime, req.EndTime)
+	}
+}
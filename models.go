@@ -1,8 +1,10 @@
 package xmpay
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
 	"github.com/sirupsen/logrus"
@@ -10,25 +12,33 @@ import (
 )
 
 type Config struct {
-	ApiUrl       string `yaml:"api_url" json:"apiUrl" comment:"API地址"`
-	AccessId     string `yaml:"access_id" json:"accessId" comment:"accessId"`
-	AccessKey    string `yaml:"access_key" json:"accessKey" comment:"accessKey"`
-	InId         string `yaml:"in_id" json:"inId" comment:"收款通道ID"`
-	OutId        string `yaml:"out_id" json:"outId" comment:"代付通道ID"`
-	InNotifyUrl  string `yaml:"notify_in" json:"inNotifyUrl" comment:"收款回调地址"`
-	OutNotifyUrl string `yaml:"notify_out" json:"outNotifyUrl" comment:"代付回调地址"`
+	ApiUrl       string   `yaml:"api_url" json:"apiUrl" comment:"API地址"`
+	ApiUrls      []string `yaml:"api_urls" json:"apiUrls,omitempty" comment:"多个API地址，设置后忽略ApiUrl"`
+	LoadBalance  string   `yaml:"load_balance" json:"loadBalance,omitempty" comment:"多地址负载均衡策略：round_robin|least_latency"`
+	MerchantId   string   `yaml:"merchant_id" json:"merchantId,omitempty" comment:"商户标识，多商户注册表按此路由，为空时使用AccessId"`
+	AccessId     string   `yaml:"access_id" json:"accessId" comment:"accessId"`
+	AccessKey    string   `yaml:"access_key" json:"accessKey" comment:"accessKey"`
+	InId         string   `yaml:"in_id" json:"inId" comment:"收款通道ID"`
+	OutId        string   `yaml:"out_id" json:"outId" comment:"代付通道ID"`
+	InNotifyUrl  string   `yaml:"notify_in" json:"inNotifyUrl" comment:"收款回调地址"`
+	OutNotifyUrl string   `yaml:"notify_out" json:"outNotifyUrl" comment:"代付回调地址"`
 }
 
 type PayClient interface {
 	CreateVirtual(param *OrderParam) (*pb.VirtualResp, error)
-	CreateReceive(param *OrderParam) (*pb.ReceiveResp, error)
+	CreateReceive(param *ReceiveParam) (*pb.ReceiveResp, error)
 	QueryReceive(orderNo, trxNo string) (*pb.OrderQueryResp, error)
 	CreateOut(param *OutParam) (*pb.OutResp, error)
 	QueryOut(orderNo, trxNo string) (*pb.OrderQueryResp, error)
-	Channel() (*pb.ChannelQueryResp, error)
+	Channel(orderType pb.ORDER_TYPE) ([]*pb.ChannelQueryResp, error)
 	Balance() (*pb.MerchantBalanceResp, error)
 }
 
+var (
+	_ PayClient = (*HttpClient)(nil)
+	_ PayClient = (*GrpcClient)(nil)
+)
+
 type OrderParam struct {
 	OrderNo   string `json:"orderNo" validate:"required" comment:"订单号"`
 	Ip        string `json:"ip" validate:"required" comment:"用户IP地址"`
@@ -42,6 +52,8 @@ type OrderParam struct {
 	Amount    int64  `json:"amount" validate:"required" comment:"交易金额（分）"`
 	Subject   string `json:"subject" comment:"商品标题"`
 	Body      string `json:"body" comment:"商品描述"`
+
+	IdempotencyKey string `json:"idempotencyKey,omitempty" comment:"幂等业务键，为空时使用订单号"`
 }
 type ReceiveParam struct {
 	OrderParam
@@ -56,26 +68,59 @@ type OutParam struct {
 }
 
 type PayClientImpl struct {
+	// Deprecated: 创建时传入的配置，热更新后不会更新，使用 CurrentConfig 读取当前配置
 	*Config
-	aes      *AES
-	log      *logrus.Entry
-	accessId string
+
+	log     *logrus.Entry
+	conf    atomic.Pointer[Config]
+	keys    atomic.Pointer[keyring]
+	secrets SecretProvider
+	ctx     context.Context
+	times   TimeSettings
+
+	idempotency IdempotencyStore
+	orderNo     *OrderNoGenerator
+	locks       keyedMutex
+	orders      OrderStore
+	limiter     *RateLimiter
+	batchFanOut int
+	noBatch     atomic.Int64 // 发现网关不支持批量查询的时间（纳秒），0 表示支持
 }
 
 type GrpcClient struct {
 	PayClientImpl
-	conn   *grpc.ClientConn
-	client pb.PayServiceClient
+	conn      *grpc.ClientConn
+	client    pb.PayServiceClient
+	endpoints *EndpointPool
 }
 
 type HttpClient struct {
 	PayClientImpl
-	apiUrl string
-	client *http.Client
+	endpoints *EndpointPool
+	client    *http.Client
+}
+
+// CurrentConfig 返回当前生效的配置，热更新后与创建时传入的 Config 不同
+func (c *PayClientImpl) CurrentConfig() *Config {
+	return c.config()
+}
+
+// Times 返回客户端的时间戳单位和业务时区设置
+func (c *PayClientImpl) Times() TimeSettings {
+	return c.times
+}
+
+func (c *PayClientImpl) config() *Config {
+	return c.conf.Load()
+}
+
+// setConfig 替换通知地址、默认通道等无需重连即可生效的配置
+func (c *PayClientImpl) setConfig(config *Config) {
+	c.conf.Store(config)
 }
 
 func (c *PayClientImpl) Decrypt(body []byte) string {
-	decrypt, err := c.aes.Decrypt(body)
+	decrypt, _, err := c.DecryptKey(body)
 	if err != nil {
 		return ""
 	}
@@ -85,8 +130,9 @@ func (c *PayClientImpl) Decrypt(body []byte) string {
 
 func (c *PayClientImpl) encrypt(param interface{}) *pb.PayRpcParam {
 
+	cred := c.credentials()
 	result := &pb.PayRpcParam{
-		AppKey: c.accessId,
+		AppKey: cred.accessId,
 	}
 
 	if param == nil {
@@ -94,7 +140,7 @@ func (c *PayClientImpl) encrypt(param interface{}) *pb.PayRpcParam {
 	}
 	marshal, _ := json.Marshal(param)
 
-	encrypt, err := c.aes.Encrypt(marshal)
+	encrypt, err := cred.aes.Encrypt(marshal)
 	if err != nil {
 		return nil
 	}
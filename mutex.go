@@ -0,0 +1,40 @@
+package xmpay
+
+import "sync"
+
+// keyedMutex 按键加锁，不同键之间互不阻塞
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	ref int
+}
+
+// lock 锁定指定键，返回解锁函数
+func (m *keyedMutex) lock(key string) func() {
+	m.mu.Lock()
+	if m.locks == nil {
+		m.locks = make(map[string]*keyedLock)
+	}
+	l, ok := m.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		m.locks[key] = l
+	}
+	l.ref++
+	m.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		m.mu.Lock()
+		l.ref--
+		if l.ref == 0 {
+			delete(m.locks, key)
+		}
+		m.mu.Unlock()
+	}
+}
@@ -0,0 +1,75 @@
+package xmpay
+
+import (
+	"context"
+	"time"
+)
+
+// Option 客户端可选配置
+type Option func(c *PayClientImpl)
+
+// WithIdempotencyStore 设置幂等存储，CreateReceive/CreateOut 重复提交时返回首次响应
+func WithIdempotencyStore(store IdempotencyStore) Option {
+	return func(c *PayClientImpl) {
+		c.idempotency = store
+	}
+}
+
+// WithOrderNoGenerator 设置订单号生成器，请求未指定订单号时自动生成
+func WithOrderNoGenerator(gen *OrderNoGenerator) Option {
+	return func(c *PayClientImpl) {
+		c.orderNo = gen
+	}
+}
+
+// WithOrderStore 设置本地订单存储，下单、查询和解析回调时自动写入
+func WithOrderStore(store OrderStore) Option {
+	return func(c *PayClientImpl) {
+		c.orders = store
+	}
+}
+
+// WithSecretProvider 设置密钥提供者，创建客户端时优先使用其返回的 AccessId/AccessKey，
+// 可通过 RefreshCredentials 或 WatchCredentials 轮换密钥
+func WithSecretProvider(provider SecretProvider) Option {
+	return func(c *PayClientImpl) {
+		c.secrets = provider
+	}
+}
+
+// WithRateLimiter 设置客户端限流，多个商户客户端可共用同一个限流器
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *PayClientImpl) {
+		c.limiter = limiter
+	}
+}
+
+// WithBatchQueryConcurrency 设置网关不支持批量查询时逐个查询的并发数，默认 DefaultBatchQueryFanOut
+func WithBatchQueryConcurrency(n int) Option {
+	return func(c *PayClientImpl) {
+		c.batchFanOut = n
+	}
+}
+
+// WithContext 设置请求的上下文，ctx 结束后限流等待和进行中的请求立即返回，
+// 通常传入服务关闭时取消的上下文
+func WithContext(ctx context.Context) Option {
+	return func(c *PayClientImpl) {
+		c.ctx = ctx
+	}
+}
+
+// WithTimeUnit 设置网关时间戳单位，默认 TimeUnitAuto 按数值大小识别；
+// 需要向网关发送时间（如订单列表的时间范围）时须设置为秒或毫秒
+func WithTimeUnit(unit TimeUnit) Option {
+	return func(c *PayClientImpl) {
+		c.times.Unit = unit
+	}
+}
+
+// WithLocation 设置业务时区，客户端返回的时间均位于该时区，默认 DefaultTimezone
+func WithLocation(loc *time.Location) Option {
+	return func(c *PayClientImpl) {
+		c.times.Location = loc
+	}
+}
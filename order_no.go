@@ -0,0 +1,90 @@
+package xmpay
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	orderNoTimeLayout = "20060102150405"
+	// orderNoFixedLen 时间戳(14位) + 毫秒(3位) + 序号(3位)
+	orderNoFixedLen = len(orderNoTimeLayout) + 6
+	// orderNoMinRandom 随机段最少位数，保证多实例间不冲突
+	orderNoMinRandom = 4
+	// DefaultOrderNoLength 默认订单号长度
+	DefaultOrderNoLength = 32
+)
+
+// OrderNoGenerator 商户订单号生成器
+// 订单号格式：前缀 + yyyyMMddHHmmss + 毫秒 + 序号 + 随机数，同一生成器内按时间递增
+type OrderNoGenerator struct {
+	prefix string
+	length int
+
+	mu     sync.Mutex
+	lastMs int64
+	seq    int64
+}
+
+// NewOrderNoGenerator 创建订单号生成器，length 为订单号总长度，小于等于0时使用默认长度
+func NewOrderNoGenerator(prefix string, length int) (*OrderNoGenerator, error) {
+	if length <= 0 {
+		length = DefaultOrderNoLength
+	}
+	if min := len(prefix) + orderNoFixedLen + orderNoMinRandom; length < min {
+		return nil, fmt.Errorf("order no length %d too short, need at least %d", length, min)
+	}
+	if strings.ContainsAny(prefix, " \t\r\n") {
+		return nil, errors.New("order no prefix contains whitespace")
+	}
+	return &OrderNoGenerator{prefix: prefix, length: length}, nil
+}
+
+// Next 生成下一个订单号
+func (g *OrderNoGenerator) Next() string {
+	g.mu.Lock()
+	ms := time.Now().UnixMilli()
+	if ms <= g.lastMs {
+		// 同一毫秒内或时钟回拨时沿用上次时间，序号递增
+		ms = g.lastMs
+		g.seq++
+		if g.seq > 999 {
+			ms++
+			g.seq = 0
+		}
+	} else {
+		g.seq = 0
+	}
+	g.lastMs = ms
+	seq := g.seq
+	g.mu.Unlock()
+
+	var b strings.Builder
+	b.Grow(g.length)
+	b.WriteString(g.prefix)
+	b.WriteString(time.UnixMilli(ms).Format(orderNoTimeLayout))
+	fmt.Fprintf(&b, "%03d%03d", ms%1000, seq)
+	b.WriteString(randomDigits(g.length - b.Len()))
+	return b.String()
+}
+
+func randomDigits(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	buf := make([]byte, n)
+	ten := big.NewInt(10)
+	for i := range buf {
+		d, err := rand.Int(rand.Reader, ten)
+		if err != nil {
+			d = big.NewInt(time.Now().UnixNano() % 10)
+		}
+		buf[i] = byte('0' + d.Int64())
+	}
+	return string(buf)
+}
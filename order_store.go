@@ -0,0 +1,266 @@
+package xmpay
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+// 订单记录来源
+const (
+	OrderSourceCreate   = "create"
+	OrderSourceQuery    = "query"
+	OrderSourceCallback = "callback"
+	OrderSourceWatch    = "watch"
+	OrderSourceCancel   = "cancel"
+)
+
+var (
+	ErrOrderNotFound   = errors.New("order not found")
+	ErrOrderMerchantNo = errors.New("order record requires merchant no")
+)
+
+// OrderRecord 本地订单记录，以商户订单号为唯一标识
+type OrderRecord struct {
+	MerchantNo string          `json:"merchantNo"` // 商户订单号
+	OrderNo    string          `json:"orderNo"`    // 平台订单号
+	Type       pb.ORDER_TYPE   `json:"type"`
+	Pid        int32           `json:"pid"`
+	Uid        string          `json:"uid"`
+	Amount     int64           `json:"amount"`     // 订单金额（分）
+	RealAmount int64           `json:"realAmount"` // 实际金额（分）
+	Fee        int64           `json:"fee"`        // 平台手续费（分）
+	Status     pb.ORDER_STATUS `json:"status"`
+	Remark     string          `json:"remark"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	UpdatedAt  time.Time       `json:"updatedAt"`
+}
+
+// OrderEvent 订单状态变更记录
+type OrderEvent struct {
+	MerchantNo string          `json:"merchantNo"`
+	Status     pb.ORDER_STATUS `json:"status"`
+	Fee        int64           `json:"fee"`
+	Source     string          `json:"source"`
+	Remark     string          `json:"remark"`
+	At         time.Time       `json:"at"`
+}
+
+// OrderStore 本地订单存储，客户端在下单、查询和回调时自动写入
+type OrderStore interface {
+	// Save 合并保存订单最新状态，状态变化时追加一条状态变更记录
+	Save(update *OrderRecord, source string) error
+	// Get 查询订单最新状态
+	Get(merchantNo string) (*OrderRecord, error)
+	// History 查询订单状态变更记录，按时间正序
+	History(merchantNo string) ([]*OrderEvent, error)
+}
+
+// mergeOrder 将 update 中的非零字段合并到 rec，返回状态是否变化
+// 已到终态的订单不会被非终态覆盖，避免乱序的查询或回调导致状态回退
+func mergeOrder(rec, update *OrderRecord, exists bool) bool {
+	if update.OrderNo != "" {
+		rec.OrderNo = update.OrderNo
+	}
+	if update.Type != pb.ORDER_TYPE_ALL {
+		rec.Type = update.Type
+	}
+	if update.Pid != 0 {
+		rec.Pid = update.Pid
+	}
+	if update.Uid != "" {
+		rec.Uid = update.Uid
+	}
+	if update.Amount != 0 {
+		rec.Amount = update.Amount
+	}
+	if update.RealAmount != 0 {
+		rec.RealAmount = update.RealAmount
+	}
+	if update.Fee != 0 {
+		rec.Fee = update.Fee
+	}
+	if update.Remark != "" {
+		rec.Remark = update.Remark
+	}
+	mergeTimes(rec, update)
+
+	if !exists {
+		rec.Status = update.Status
+		return true
+	}
+	if update.Status == rec.Status || (isFinalStatus(rec.Status) && !isFinalStatus(update.Status)) {
+		return false
+	}
+	rec.Status = update.Status
+	return true
+}
+
+// mergeTimes 合并订单时间。创建时间为本地首次写入的时间，位于更新时间的时区；查询或回调先于下单记录写入时
+// 网关的更新时间不代表订单创建时间；更新时间只前进，乱序到达的查询或回调不回退更新时间
+func mergeTimes(rec, update *OrderRecord) {
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now().In(update.UpdatedAt.Location())
+	}
+	if update.UpdatedAt.After(rec.UpdatedAt) {
+		rec.UpdatedAt = update.UpdatedAt
+	}
+}
+
+// withUpdateTime 返回更新时间已填充的副本，查询和回调使用网关时间，其余使用当前时间
+func withUpdateTime(update *OrderRecord) *OrderRecord {
+	u := *update
+	if u.UpdatedAt.IsZero() {
+		u.UpdatedAt = Now()
+	}
+	return &u
+}
+
+// MemoryOrderStore 基于内存的订单存储
+type MemoryOrderStore struct {
+	mu      sync.RWMutex
+	orders  map[string]*OrderRecord
+	history map[string][]*OrderEvent
+}
+
+func NewMemoryOrderStore() *MemoryOrderStore {
+	return &MemoryOrderStore{
+		orders:  make(map[string]*OrderRecord),
+		history: make(map[string][]*OrderEvent),
+	}
+}
+
+func (s *MemoryOrderStore) Save(update *OrderRecord, source string) error {
+	if update.MerchantNo == "" {
+		return ErrOrderMerchantNo
+	}
+	update = withUpdateTime(update)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, exists := s.orders[update.MerchantNo]
+	if !exists {
+		rec = &OrderRecord{MerchantNo: update.MerchantNo}
+		s.orders[update.MerchantNo] = rec
+	}
+	if mergeOrder(rec, update, exists) {
+		s.history[rec.MerchantNo] = append(s.history[rec.MerchantNo], &OrderEvent{
+			MerchantNo: rec.MerchantNo,
+			Status:     rec.Status,
+			Fee:        rec.Fee,
+			Source:     source,
+			Remark:     update.Remark,
+			At:         update.UpdatedAt,
+		})
+	}
+	return nil
+}
+
+func (s *MemoryOrderStore) Get(merchantNo string) (*OrderRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rec, ok := s.orders[merchantNo]
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+	r := *rec
+	return &r, nil
+}
+
+func (s *MemoryOrderStore) History(merchantNo string) ([]*OrderEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events, ok := s.history[merchantNo]
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+	result := make([]*OrderEvent, 0, len(events))
+	for _, e := range events {
+		ev := *e
+		result = append(result, &ev)
+	}
+	return result, nil
+}
+
+// recordOrder 写入本地订单存储，失败只记录日志不影响调用结果
+func (c *PayClientImpl) recordOrder(rec *OrderRecord, source string) {
+	if c.orders == nil || rec == nil || rec.MerchantNo == "" {
+		return
+	}
+	if rec.UpdatedAt.IsZero() {
+		rec.UpdatedAt = c.times.Now()
+	}
+	if err := c.orders.Save(rec, source); err != nil {
+		c.log.Errorf("order store save failed, merchantNo: %s, err: %v", rec.MerchantNo, err)
+	}
+}
+
+func orderFromCreate(orderType pb.ORDER_TYPE, param *OrderParam, pid int32, orderNo string) *OrderRecord {
+	return &OrderRecord{
+		MerchantNo: param.OrderNo,
+		OrderNo:    orderNo,
+		Type:       orderType,
+		Pid:        pid,
+		Uid:        param.Uid,
+		Amount:     param.Amount,
+		Status:     pb.ORDER_STATUS_WAIT,
+	}
+}
+
+func orderFromQuery(times TimeSettings, orderType pb.ORDER_TYPE, resp *pb.OrderQueryResp) *OrderRecord {
+	if resp == nil {
+		return nil
+	}
+	return &OrderRecord{
+		MerchantNo: resp.MerchantNo,
+		OrderNo:    resp.OrderNo,
+		Type:       orderType,
+		Amount:     resp.Amount,
+		Fee:        resp.Fee,
+		Status:     resp.Status,
+		UpdatedAt:  times.ParseTimestamp(resp.GetUpdateTime()),
+	}
+}
+
+func orderFromEvent(times TimeSettings, event *pb.OrderStatusEvent) *OrderRecord {
+	return &OrderRecord{
+		MerchantNo: event.MerchantNo,
+		OrderNo:    event.OrderNo,
+		Type:       event.OrderType,
+		Amount:     event.Amount,
+		Fee:        event.Fee,
+		Status:     event.Status,
+		UpdatedAt:  times.ParseTimestamp(event.UpdateTime),
+	}
+}
+
+// orderFromCallback 退款回调记录为以退款单号为键的退款单
+func orderFromCallback(times TimeSettings, cb *pb.CallbackParam) *OrderRecord {
+	if cb.RefundNo != "" {
+		return &OrderRecord{
+			MerchantNo: cb.RefundNo,
+			OrderNo:    cb.OrderNo,
+			Type:       pb.ORDER_TYPE_REFUND,
+			Uid:        cb.Uid,
+			Amount:     cb.RefundAmount,
+			Status:     cb.Status,
+			Remark:     cb.Remark,
+			UpdatedAt:  times.ParseTimestamp(cb.GetFinishTime()),
+		}
+	}
+	return &OrderRecord{
+		MerchantNo: cb.MerchantNo,
+		OrderNo:    cb.OrderNo,
+		Uid:        cb.Uid,
+		RealAmount: cb.RealAmount,
+		Fee:        cb.Fee,
+		Status:     cb.Status,
+		Remark:     cb.Remark,
+		UpdatedAt:  times.ParseTimestamp(cb.GetFinishTime()),
+	}
+}
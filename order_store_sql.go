@@ -0,0 +1,193 @@
+package xmpay
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+const orderColumns = "merchant_no, order_no, type, pid, uid, amount, real_amount, fee, status, remark, created_at, updated_at"
+
+// errOrderConflict 订单在读取后被并发修改，条件更新未命中
+var errOrderConflict = errors.New("order updated concurrently")
+
+// orderSaveAttempts 并发冲突时保存订单的最大尝试次数
+const orderSaveAttempts = 5
+
+// SQLOrderStore 基于 database/sql 的订单存储，状态变更记录保存在 <table>_history 表。
+// 更新以 version 列做条件更新，并发写入同一订单时重新读取后合并，终态不会被覆盖
+type SQLOrderStore struct {
+	db          *sql.DB
+	table       string
+	history     string
+	placeholder Placeholder
+}
+
+// NewSQLOrderStore 创建 SQL 订单存储，table 为空时使用 xmpay_order
+func NewSQLOrderStore(db *sql.DB, table string, placeholder Placeholder) *SQLOrderStore {
+	if table == "" {
+		table = "xmpay_order"
+	}
+	return &SQLOrderStore{db: db, table: table, history: table + "_history", placeholder: placeholder}
+}
+
+// CreateTable 创建订单表和状态变更记录表
+func (s *SQLOrderStore) CreateTable() error {
+	_, err := s.db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	merchant_no VARCHAR(64) NOT NULL PRIMARY KEY,
+	order_no VARCHAR(64) NOT NULL DEFAULT '',
+	type INTEGER NOT NULL DEFAULT 0,
+	pid INTEGER NOT NULL DEFAULT 0,
+	uid VARCHAR(64) NOT NULL DEFAULT '',
+	amount BIGINT NOT NULL DEFAULT 0,
+	real_amount BIGINT NOT NULL DEFAULT 0,
+	fee BIGINT NOT NULL DEFAULT 0,
+	status INTEGER NOT NULL DEFAULT 0,
+	remark VARCHAR(255) NOT NULL DEFAULT '',
+	version BIGINT NOT NULL DEFAULT 0,
+	created_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL
+)`, s.table))
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	merchant_no VARCHAR(64) NOT NULL,
+	seq BIGINT NOT NULL,
+	status INTEGER NOT NULL,
+	fee BIGINT NOT NULL DEFAULT 0,
+	source VARCHAR(16) NOT NULL,
+	remark VARCHAR(255) NOT NULL DEFAULT '',
+	created_at BIGINT NOT NULL,
+	PRIMARY KEY (merchant_no, seq)
+)`, s.history))
+	return err
+}
+
+func (s *SQLOrderStore) Save(update *OrderRecord, source string) error {
+	if update.MerchantNo == "" {
+		return ErrOrderMerchantNo
+	}
+	update = withUpdateTime(update)
+
+	var err error
+	for i := 0; i < orderSaveAttempts; i++ {
+		// 并发插入同一订单时主键冲突、并发更新时条件更新未命中，重新读取后重试
+		if err = s.save(update, source); !isUniqueViolation(err) && !errors.Is(err, errOrderConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+func (s *SQLOrderStore) save(update *OrderRecord, source string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	rec := &OrderRecord{MerchantNo: update.MerchantNo}
+	exists := true
+	var version int64
+	row := tx.QueryRow(s.rebind("SELECT "+orderColumns+", version FROM %s WHERE merchant_no = ?", s.table), update.MerchantNo)
+	if err = scanOrder(row, rec, &version); err == sql.ErrNoRows {
+		exists = false
+	} else if err != nil {
+		return err
+	}
+
+	changed := mergeOrder(rec, update, exists)
+	if exists {
+		var res sql.Result
+		res, err = tx.Exec(s.rebind("UPDATE %s SET order_no = ?, type = ?, pid = ?, uid = ?, amount = ?, real_amount = ?, fee = ?, status = ?, remark = ?, updated_at = ?, version = ? WHERE merchant_no = ? AND version = ?", s.table),
+			rec.OrderNo, int32(rec.Type), rec.Pid, rec.Uid, rec.Amount, rec.RealAmount, rec.Fee, int32(rec.Status), rec.Remark, rec.UpdatedAt.UnixMilli(), version+1, rec.MerchantNo, version)
+		if err == nil {
+			if n, _ := res.RowsAffected(); n != 1 {
+				return errOrderConflict
+			}
+		}
+	} else {
+		_, err = tx.Exec(s.rebind("INSERT INTO %s ("+orderColumns+", version) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1)", s.table),
+			rec.MerchantNo, rec.OrderNo, int32(rec.Type), rec.Pid, rec.Uid, rec.Amount, rec.RealAmount, rec.Fee, int32(rec.Status), rec.Remark, rec.CreatedAt.UnixMilli(), rec.UpdatedAt.UnixMilli())
+	}
+	if err != nil {
+		return err
+	}
+	if changed {
+		// version 在同一订单内唯一递增，作为变更记录的序号
+		_, err = tx.Exec(s.rebind("INSERT INTO %s (merchant_no, seq, status, fee, source, remark, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)", s.history),
+			rec.MerchantNo, version+1, int32(rec.Status), rec.Fee, source, update.Remark, update.UpdatedAt.UnixMilli())
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLOrderStore) Get(merchantNo string) (*OrderRecord, error) {
+	rec := &OrderRecord{}
+	row := s.db.QueryRow(s.rebind("SELECT "+orderColumns+" FROM %s WHERE merchant_no = ?", s.table), merchantNo)
+	if err := scanOrder(row, rec); err == sql.ErrNoRows {
+		return nil, ErrOrderNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+func (s *SQLOrderStore) History(merchantNo string) ([]*OrderEvent, error) {
+	rows, err := s.db.Query(s.rebind("SELECT merchant_no, status, fee, source, remark, created_at FROM %s WHERE merchant_no = ? ORDER BY seq", s.history), merchantNo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*OrderEvent
+	for rows.Next() {
+		var (
+			e      OrderEvent
+			status int32
+			at     int64
+		)
+		if err = rows.Scan(&e.MerchantNo, &status, &e.Fee, &e.Source, &e.Remark, &at); err != nil {
+			return nil, err
+		}
+		e.Status = pb.ORDER_STATUS(status)
+		e.At = unixMilli(at)
+		events = append(events, &e)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, ErrOrderNotFound
+	}
+	return events, nil
+}
+
+func (s *SQLOrderStore) rebind(query, table string) string {
+	return s.placeholder.rebind(fmt.Sprintf(query, table))
+}
+
+// scanOrder 读取 orderColumns 列，extra 接收其后追加的列
+func scanOrder(row *sql.Row, rec *OrderRecord, extra ...interface{}) error {
+	var (
+		orderType, status    int32
+		createdAt, updatedAt int64
+	)
+	dest := []interface{}{&rec.MerchantNo, &rec.OrderNo, &orderType, &rec.Pid, &rec.Uid, &rec.Amount, &rec.RealAmount, &rec.Fee, &status, &rec.Remark, &createdAt, &updatedAt}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return err
+	}
+	rec.Type = pb.ORDER_TYPE(orderType)
+	rec.Status = pb.ORDER_STATUS(status)
+	rec.CreatedAt = unixMilli(createdAt)
+	rec.UpdatedAt = unixMilli(updatedAt)
+	return nil
+}
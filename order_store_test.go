@@ -0,0 +1,76 @@
+package xmpay
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+func TestOrderStoreTimes(t *testing.T) {
+	store := NewMemoryOrderStore()
+	gatewayTime := time.Now().Add(-time.Hour)
+
+	// 查询先于下单记录写入，创建时间为本地写入时间而非网关更新时间
+	before := time.Now()
+	if err := store.Save(&OrderRecord{MerchantNo: "M1", Status: pb.ORDER_STATUS_PROCESSING, UpdatedAt: gatewayTime}, OrderSourceQuery); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := store.Get("M1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.CreatedAt.Before(before) {
+		t.Errorf("created at = %v, want local insert time", rec.CreatedAt)
+	}
+	if !rec.UpdatedAt.Equal(gatewayTime) {
+		t.Errorf("updated at = %v, want %v", rec.UpdatedAt, gatewayTime)
+	}
+	createdAt := rec.CreatedAt
+
+	// 乱序到达的旧回调不回退更新时间，也不改变创建时间
+	older := gatewayTime.Add(-time.Minute)
+	if err = store.Save(&OrderRecord{MerchantNo: "M1", Status: pb.ORDER_STATUS_PROCESSING, UpdatedAt: older}, OrderSourceCallback); err != nil {
+		t.Fatal(err)
+	}
+	if rec, _ = store.Get("M1"); !rec.UpdatedAt.Equal(gatewayTime) || !rec.CreatedAt.Equal(createdAt) {
+		t.Errorf("times = %v, %v", rec.CreatedAt, rec.UpdatedAt)
+	}
+
+	newer := gatewayTime.Add(time.Minute)
+	if err = store.Save(&OrderRecord{MerchantNo: "M1", Status: pb.ORDER_STATUS_SUCCESS, UpdatedAt: newer}, OrderSourceCallback); err != nil {
+		t.Fatal(err)
+	}
+	if rec, _ = store.Get("M1"); !rec.UpdatedAt.Equal(newer) || rec.Status != pb.ORDER_STATUS_SUCCESS {
+		t.Errorf("record = %+v", rec)
+	}
+}
+
+// sqlStateError 模拟实现 SQLState() 的驱动错误
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "driver error " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func TestIsUniqueViolation(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("Error 1062 (23000): Duplicate entry 'M1' for key 'PRIMARY'"), true},
+		{errors.New(`pq: duplicate key value violates unique constraint "xmpay_order_pkey"`), true},
+		{errors.New("UNIQUE constraint failed: xmpay_order.merchant_no"), true},
+		{fmt.Errorf("save: %w", sqlStateError("23505")), true},
+		{sqlStateError("40001"), false},
+		{errors.New("driver: bad connection"), false},
+		{errors.New("context deadline exceeded"), false},
+	}
+	for _, tt := range tests {
+		if got := isUniqueViolation(tt.err); got != tt.want {
+			t.Errorf("%v: got %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
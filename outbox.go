@@ -0,0 +1,402 @@
+package xmpay
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"github.com/sirupsen/logrus"
+)
+
+// OutboxState 发件箱记录状态
+type OutboxState string
+
+const (
+	OutboxPending  OutboxState = "pending"  // 待发送
+	OutboxSending  OutboxState = "sending"  // 发送中
+	OutboxSent     OutboxState = "sent"     // 已提交网关或发送结果未知，等待查询或回调确认
+	OutboxFailed   OutboxState = "failed"   // 网关确认拒绝或不存在该订单，不再重试
+	OutboxFinished OutboxState = "finished" // 已获得订单终态
+)
+
+var (
+	ErrOutboxNotFound = errors.New("outbox entry not found")
+	ErrOutboxOrderNo  = errors.New("outbox entry requires order no")
+)
+
+// OutboxEntry 发件箱中的一笔付款请求，以商户订单号为唯一标识
+type OutboxEntry struct {
+	OrderNo   string          `json:"orderNo"`
+	Param     *OutParam       `json:"param"`
+	State     OutboxState     `json:"state"`
+	Attempts  int             `json:"attempts"`
+	Resp      *pb.OutResp     `json:"resp,omitempty"`
+	LastError string          `json:"lastError,omitempty"`
+	Status    pb.ORDER_STATUS `json:"status"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// Outbox 付款发件箱，先持久化付款意图再由 OutboxWorker 提交网关
+type Outbox interface {
+	// Enqueue 持久化付款请求，订单号已存在时返回已有记录
+	Enqueue(param *OutParam) (*OutboxEntry, error)
+	// Claim 领取最多 limit 条待发送记录（包括租约过期的发送中记录），标记为发送中并增加尝试次数
+	Claim(limit int, lease time.Duration) ([]*OutboxEntry, error)
+	// MarkSent 记录网关受理响应
+	MarkSent(orderNo string, resp *pb.OutResp) error
+	// MarkUnknown 记录结果未知的发送错误，标记为已提交，由查询或回调确认
+	MarkUnknown(orderNo string, cause error) error
+	// MarkFailed 记录发送错误，retry 为 true 时重新排队，否则标记为失败
+	MarkFailed(orderNo string, cause error, retry bool) error
+	// Unresolved 返回已提交网关或发送结果未知、尚未获得终态的记录
+	Unresolved(limit int) ([]*OutboxEntry, error)
+	// Resolve 记录回调或查询得到的订单状态，终态时标记为完成
+	Resolve(orderNo string, status pb.ORDER_STATUS) error
+	// Get 查询记录
+	Get(orderNo string) (*OutboxEntry, error)
+}
+
+// OutClient 发件箱发送付款所需的客户端方法，HttpClient 与 GrpcClient 均已实现
+type OutClient interface {
+	CreateOut(param *OutParam) (*pb.OutResp, error)
+	QueryOut(orderNo, trxNo string) (*pb.OrderQueryResp, error)
+}
+
+// isFinalStatus 订单是否已到终态
+func isFinalStatus(status pb.ORDER_STATUS) bool {
+	switch status {
+	case pb.ORDER_STATUS_SUCCESS, pb.ORDER_STATUS_FAILURE, pb.ORDER_STATUS_REFUNDED, pb.ORDER_STATUS_CANCELED:
+		return true
+	}
+	return false
+}
+
+// MemoryOutbox 基于内存的发件箱，进程退出后记录丢失，仅用于测试或单机场景
+type MemoryOutbox struct {
+	mu      sync.Mutex
+	entries map[string]*OutboxEntry
+}
+
+func NewMemoryOutbox() *MemoryOutbox {
+	return &MemoryOutbox{entries: make(map[string]*OutboxEntry)}
+}
+
+func (o *MemoryOutbox) Enqueue(param *OutParam) (*OutboxEntry, error) {
+	if param == nil || param.OrderNo == "" {
+		return nil, ErrOutboxOrderNo
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if e, ok := o.entries[param.OrderNo]; ok {
+		return e.clone(), nil
+	}
+	p := *param
+	now := time.Now()
+	e := &OutboxEntry{
+		OrderNo:   param.OrderNo,
+		Param:     &p,
+		State:     OutboxPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	o.entries[e.OrderNo] = e
+	return e.clone(), nil
+}
+
+func (o *MemoryOutbox) Claim(limit int, lease time.Duration) ([]*OutboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := time.Now()
+	var list []*OutboxEntry
+	for _, e := range o.entries {
+		if e.State == OutboxPending || (e.State == OutboxSending && now.Sub(e.UpdatedAt) > lease) {
+			list = append(list, e)
+		}
+	}
+	sortOutbox(list)
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	result := make([]*OutboxEntry, 0, len(list))
+	for _, e := range list {
+		e.State = OutboxSending
+		e.Attempts++
+		e.UpdatedAt = now
+		result = append(result, e.clone())
+	}
+	return result, nil
+}
+
+func (o *MemoryOutbox) MarkSent(orderNo string, resp *pb.OutResp) error {
+	return o.update(orderNo, func(e *OutboxEntry) {
+		if e.State == OutboxFinished {
+			return
+		}
+		e.State = OutboxSent
+		e.Resp = resp
+		e.LastError = ""
+	})
+}
+
+func (o *MemoryOutbox) MarkUnknown(orderNo string, cause error) error {
+	return o.update(orderNo, func(e *OutboxEntry) {
+		if e.State == OutboxFinished {
+			return
+		}
+		e.State = OutboxSent
+		if cause != nil {
+			e.LastError = cause.Error()
+		}
+	})
+}
+
+func (o *MemoryOutbox) MarkFailed(orderNo string, cause error, retry bool) error {
+	return o.update(orderNo, func(e *OutboxEntry) {
+		if e.State == OutboxFinished {
+			return
+		}
+		e.State = OutboxFailed
+		if retry {
+			e.State = OutboxPending
+		}
+		if cause != nil {
+			e.LastError = cause.Error()
+		}
+	})
+}
+
+func (o *MemoryOutbox) Unresolved(limit int) ([]*OutboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var list []*OutboxEntry
+	for _, e := range o.entries {
+		if e.State == OutboxSent {
+			list = append(list, e)
+		}
+	}
+	sortOutbox(list)
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	result := make([]*OutboxEntry, 0, len(list))
+	for _, e := range list {
+		result = append(result, e.clone())
+	}
+	return result, nil
+}
+
+func (o *MemoryOutbox) Resolve(orderNo string, status pb.ORDER_STATUS) error {
+	return o.update(orderNo, func(e *OutboxEntry) {
+		if e.State == OutboxFinished {
+			return
+		}
+		e.Status = status
+		if isFinalStatus(status) {
+			e.State = OutboxFinished
+		} else if e.State != OutboxSending {
+			e.State = OutboxSent
+		}
+	})
+}
+
+func (o *MemoryOutbox) Get(orderNo string) (*OutboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	e, ok := o.entries[orderNo]
+	if !ok {
+		return nil, ErrOutboxNotFound
+	}
+	return e.clone(), nil
+}
+
+func (o *MemoryOutbox) update(orderNo string, fn func(e *OutboxEntry)) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	e, ok := o.entries[orderNo]
+	if !ok {
+		return ErrOutboxNotFound
+	}
+	fn(e)
+	e.UpdatedAt = time.Now()
+	return nil
+}
+
+func (e *OutboxEntry) clone() *OutboxEntry {
+	c := *e
+	return &c
+}
+
+func sortOutbox(list []*OutboxEntry) {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+}
+
+// OutboxWorker 将发件箱中的付款提交到网关，并轮询未到终态的订单
+type OutboxWorker struct {
+	outbox Outbox
+	client OutClient
+	log    *logrus.Entry
+
+	Interval    time.Duration // 轮询间隔
+	BatchSize   int           // 每轮处理的最大记录数
+	Lease       time.Duration // 发送中记录的租约，超时后视为 worker 崩溃重新领取
+	MaxAttempts int           // 最大发送次数，请求确定未发出或网关确认不存在该订单且超过次数后标记为失败
+}
+
+// NewOutboxWorker 创建发件箱 worker
+func NewOutboxWorker(outbox Outbox, client OutClient, log *logrus.Entry) *OutboxWorker {
+	if log == nil {
+		log = logrus.WithField("model", "OutboxWorker")
+	}
+	return &OutboxWorker{
+		outbox:      outbox,
+		client:      client,
+		log:         log,
+		Interval:    5 * time.Second,
+		BatchSize:   50,
+		Lease:       2 * time.Minute,
+		MaxAttempts: 5,
+	}
+}
+
+// Run 循环处理发件箱直到 ctx 结束
+func (w *OutboxWorker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.RunOnce(); err != nil {
+			w.log.Errorf("outbox run failed, err: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 发送一批待发送记录，并查询一批未到终态的订单
+func (w *OutboxWorker) RunOnce() error {
+	entries, err := w.outbox.Claim(w.BatchSize, w.Lease)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		w.send(e)
+	}
+
+	entries, err = w.outbox.Unresolved(w.BatchSize)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		w.poll(e)
+	}
+	return nil
+}
+
+// HandleCallback 使用付款回调更新发件箱记录的订单状态
+func (w *OutboxWorker) HandleCallback(cb *pb.CallbackParam) error {
+	err := w.outbox.Resolve(cb.MerchantNo, cb.Status)
+	if errors.Is(err, ErrOutboxNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (w *OutboxWorker) send(e *OutboxEntry) {
+	if e.Attempts > 1 {
+		// 上次发送结果未知，先查询网关，仅在确认不存在该订单时重新下单，避免重复付款
+		resp, err := w.client.QueryOut(e.OrderNo, "")
+		if err == nil {
+			w.found(e, resp)
+			return
+		}
+		if !orderNotExist(err) {
+			w.log.Errorf("outbox query before resend failed, orderNo: %s, err: %v", e.OrderNo, err)
+			w.record(e.OrderNo, w.outbox.MarkUnknown(e.OrderNo, err))
+			return
+		}
+	}
+
+	resp, err := w.client.CreateOut(e.Param)
+	if err == nil {
+		w.record(e.OrderNo, w.outbox.MarkSent(e.OrderNo, resp))
+		return
+	}
+	w.log.Errorf("outbox send failed, orderNo: %s, attempts: %d, err: %v", e.OrderNo, e.Attempts, err)
+	switch {
+	case IsApiError(err):
+		// 业务错误可能是重复下单，确认网关上不存在该订单才标记为失败
+		w.confirm(e, err)
+	case requestUnsent(err):
+		w.record(e.OrderNo, w.outbox.MarkFailed(e.OrderNo, err, w.canRetry(e)))
+	default:
+		// 请求可能已到达网关，等待查询或回调确认
+		w.record(e.OrderNo, w.outbox.MarkUnknown(e.OrderNo, err))
+	}
+}
+
+// confirm 网关拒绝下单后查询订单，存在时按查询结果记录，确认不存在时标记为失败
+func (w *OutboxWorker) confirm(e *OutboxEntry, cause error) {
+	resp, err := w.client.QueryOut(e.OrderNo, "")
+	switch {
+	case err == nil:
+		w.found(e, resp)
+	case orderNotExist(err):
+		w.record(e.OrderNo, w.outbox.MarkFailed(e.OrderNo, cause, false))
+	default:
+		w.record(e.OrderNo, w.outbox.MarkUnknown(e.OrderNo, cause))
+	}
+}
+
+// found 记录查询到的订单
+func (w *OutboxWorker) found(e *OutboxEntry, resp *pb.OrderQueryResp) {
+	w.record(e.OrderNo, w.outbox.MarkSent(e.OrderNo, &pb.OutResp{OrderNo: resp.OrderNo, MerchantNo: resp.MerchantNo}))
+	w.record(e.OrderNo, w.outbox.Resolve(e.OrderNo, resp.Status))
+}
+
+func (w *OutboxWorker) canRetry(e *OutboxEntry) bool {
+	return w.MaxAttempts <= 0 || e.Attempts < w.MaxAttempts
+}
+
+func (w *OutboxWorker) poll(e *OutboxEntry) {
+	trxNo := ""
+	if e.Resp != nil {
+		trxNo = e.Resp.OrderNo
+	}
+	resp, err := w.client.QueryOut(e.OrderNo, trxNo)
+	if err != nil {
+		if e.Resp == nil && orderNotExist(err) {
+			// 发送结果未知的请求未到达网关，重新排队，下次发送前仍会先查询
+			w.record(e.OrderNo, w.outbox.MarkFailed(e.OrderNo, err, w.canRetry(e)))
+			return
+		}
+		w.log.Errorf("outbox query failed, orderNo: %s, err: %v", e.OrderNo, err)
+		return
+	}
+	if e.Resp == nil {
+		w.found(e, resp)
+		return
+	}
+	if resp.Status != e.Status {
+		w.record(e.OrderNo, w.outbox.Resolve(e.OrderNo, resp.Status))
+	}
+}
+
+func (w *OutboxWorker) record(orderNo string, err error) {
+	if err != nil {
+		w.log.Errorf("outbox update failed, orderNo: %s, err: %v", orderNo, err)
+	}
+}
@@ -0,0 +1,241 @@
+package xmpay
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+const outboxColumns = "order_no, param, state, attempts, resp, last_error, status, created_at, updated_at"
+
+// SQLOutbox 基于 database/sql 的发件箱，时间以毫秒时间戳保存
+//
+// 表结构见 CreateTable，多个 worker 通过条件更新领取记录，可同时运行
+type SQLOutbox struct {
+	db          *sql.DB
+	table       string
+	placeholder Placeholder
+}
+
+// NewSQLOutbox 创建 SQL 发件箱，table 为空时使用 xmpay_outbox
+func NewSQLOutbox(db *sql.DB, table string, placeholder Placeholder) *SQLOutbox {
+	if table == "" {
+		table = "xmpay_outbox"
+	}
+	return &SQLOutbox{db: db, table: table, placeholder: placeholder}
+}
+
+// CreateTable 创建发件箱表
+func (o *SQLOutbox) CreateTable() error {
+	_, err := o.db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	order_no VARCHAR(64) NOT NULL PRIMARY KEY,
+	param TEXT NOT NULL,
+	state VARCHAR(16) NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	resp TEXT,
+	last_error TEXT,
+	status INTEGER NOT NULL DEFAULT 0,
+	created_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL
+)`, o.table))
+	return err
+}
+
+func (o *SQLOutbox) Enqueue(param *OutParam) (*OutboxEntry, error) {
+	return o.enqueue(o.db, param)
+}
+
+// EnqueueTx 在调用方的事务中写入付款请求，与业务数据一起提交或回滚，
+// 避免业务已提交而付款请求未写入。事务提交前 worker 不可见该记录
+func (o *SQLOutbox) EnqueueTx(tx *sql.Tx, param *OutParam) (*OutboxEntry, error) {
+	return o.enqueue(tx, param)
+}
+
+func (o *SQLOutbox) enqueue(db sqlExecutor, param *OutParam) (*OutboxEntry, error) {
+	if param == nil || param.OrderNo == "" {
+		return nil, ErrOutboxOrderNo
+	}
+	if e, err := o.get(db, param.OrderNo); err == nil {
+		return e, nil
+	} else if !errors.Is(err, ErrOutboxNotFound) {
+		return nil, err
+	}
+
+	data, err := json.Marshal(param)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UnixMilli()
+	_, err = db.Exec(o.rebind("INSERT INTO %s (order_no, param, state, attempts, resp, last_error, status, created_at, updated_at) VALUES (?, ?, ?, 0, '', '', 0, ?, ?)"),
+		param.OrderNo, string(data), OutboxPending, now, now)
+	if err != nil {
+		// 并发写入同一订单号时主键冲突，返回已有记录
+		if e, getErr := o.get(db, param.OrderNo); getErr == nil {
+			return e, nil
+		}
+		return nil, err
+	}
+	return o.get(db, param.OrderNo)
+}
+
+func (o *SQLOutbox) Claim(limit int, lease time.Duration) ([]*OutboxEntry, error) {
+	now := time.Now().UnixMilli()
+	entries, err := o.query("SELECT "+outboxColumns+" FROM %s WHERE state = ? OR (state = ? AND updated_at < ?) ORDER BY created_at LIMIT ?",
+		OutboxPending, OutboxSending, now-lease.Milliseconds(), limitOrAll(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make([]*OutboxEntry, 0, len(entries))
+	for _, e := range entries {
+		res, err := o.exec("UPDATE %s SET state = ?, attempts = attempts + 1, updated_at = ? WHERE order_no = ? AND state = ? AND attempts = ? AND updated_at = ?",
+			OutboxSending, now, e.OrderNo, e.State, e.Attempts, e.UpdatedAt.UnixMilli())
+		if err != nil {
+			return claimed, err
+		}
+		if n, _ := res.RowsAffected(); n != 1 {
+			// 已被其他 worker 领取
+			continue
+		}
+		e.State = OutboxSending
+		e.Attempts++
+		e.UpdatedAt = time.UnixMilli(now)
+		claimed = append(claimed, e)
+	}
+	return claimed, nil
+}
+
+func (o *SQLOutbox) MarkSent(orderNo string, resp *pb.OutResp) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return o.update("UPDATE %s SET state = ?, resp = ?, last_error = '', updated_at = ? WHERE order_no = ? AND state <> ?",
+		orderNo, OutboxSent, string(data), time.Now().UnixMilli(), orderNo, OutboxFinished)
+}
+
+func (o *SQLOutbox) MarkUnknown(orderNo string, cause error) error {
+	msg := ""
+	if cause != nil {
+		msg = cause.Error()
+	}
+	return o.update("UPDATE %s SET state = ?, last_error = ?, updated_at = ? WHERE order_no = ? AND state <> ?",
+		orderNo, OutboxSent, msg, time.Now().UnixMilli(), orderNo, OutboxFinished)
+}
+
+func (o *SQLOutbox) MarkFailed(orderNo string, cause error, retry bool) error {
+	state := OutboxFailed
+	if retry {
+		state = OutboxPending
+	}
+	msg := ""
+	if cause != nil {
+		msg = cause.Error()
+	}
+	return o.update("UPDATE %s SET state = ?, last_error = ?, updated_at = ? WHERE order_no = ? AND state <> ?",
+		orderNo, state, msg, time.Now().UnixMilli(), orderNo, OutboxFinished)
+}
+
+func (o *SQLOutbox) Unresolved(limit int) ([]*OutboxEntry, error) {
+	return o.query("SELECT "+outboxColumns+" FROM %s WHERE state = ? ORDER BY created_at LIMIT ?", OutboxSent, limitOrAll(limit))
+}
+
+func (o *SQLOutbox) Resolve(orderNo string, status pb.ORDER_STATUS) error {
+	now := time.Now().UnixMilli()
+	if isFinalStatus(status) {
+		return o.update("UPDATE %s SET state = ?, status = ?, updated_at = ? WHERE order_no = ? AND state <> ?",
+			orderNo, OutboxFinished, int32(status), now, orderNo, OutboxFinished)
+	}
+	// 与 MemoryOutbox 一致：非终态时除发送中的记录外均标记为已提交
+	return o.update("UPDATE %s SET state = CASE WHEN state = ? THEN state ELSE ? END, status = ?, updated_at = ? WHERE order_no = ? AND state <> ?",
+		orderNo, OutboxSending, OutboxSent, int32(status), now, orderNo, OutboxFinished)
+}
+
+func (o *SQLOutbox) Get(orderNo string) (*OutboxEntry, error) {
+	return o.get(o.db, orderNo)
+}
+
+func (o *SQLOutbox) get(db sqlExecutor, orderNo string) (*OutboxEntry, error) {
+	entries, err := o.queryWith(db, "SELECT "+outboxColumns+" FROM %s WHERE order_no = ?", orderNo)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrOutboxNotFound
+	}
+	return entries[0], nil
+}
+
+func (o *SQLOutbox) rebind(query string) string {
+	return o.placeholder.rebind(fmt.Sprintf(query, o.table))
+}
+
+func (o *SQLOutbox) exec(query string, args ...interface{}) (sql.Result, error) {
+	return o.db.Exec(o.rebind(query), args...)
+}
+
+// update 执行更新，未命中任何记录时区分记录不存在与已完成
+func (o *SQLOutbox) update(query, orderNo string, args ...interface{}) error {
+	res, err := o.exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if _, err = o.Get(orderNo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *SQLOutbox) query(query string, args ...interface{}) ([]*OutboxEntry, error) {
+	return o.queryWith(o.db, query, args...)
+}
+
+func (o *SQLOutbox) queryWith(db sqlExecutor, query string, args ...interface{}) ([]*OutboxEntry, error) {
+	rows, err := db.Query(o.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*OutboxEntry
+	for rows.Next() {
+		var (
+			e                    OutboxEntry
+			param                string
+			resp, lastErr        sql.NullString
+			status               int32
+			createdAt, updatedAt int64
+		)
+		if err = rows.Scan(&e.OrderNo, &param, &e.State, &e.Attempts, &resp, &lastErr, &status, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(param), &e.Param); err != nil {
+			return nil, err
+		}
+		if resp.String != "" {
+			if err = json.Unmarshal([]byte(resp.String), &e.Resp); err != nil {
+				return nil, err
+			}
+		}
+		e.LastError = lastErr.String
+		e.Status = pb.ORDER_STATUS(status)
+		e.CreatedAt = unixMilli(createdAt)
+		e.UpdatedAt = unixMilli(updatedAt)
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+// limitOrAll LIMIT 参数，小于等于0时不限制
+func limitOrAll(limit int) int {
+	if limit <= 0 {
+		return 1<<31 - 1
+	}
+	return limit
+}
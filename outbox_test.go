@@ -0,0 +1,199 @@
+package xmpay
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+// outStub 按脚本返回结果的付款客户端，未设置脚本时查询返回订单不存在
+type outStub struct {
+	creates, queries int
+	create           []error
+	query            []error
+	status           pb.ORDER_STATUS
+}
+
+func (c *outStub) CreateOut(param *OutParam) (*pb.OutResp, error) {
+	c.creates++
+	if err := popError(&c.create); err != nil {
+		return nil, err
+	}
+	return &pb.OutResp{OrderNo: "T" + param.OrderNo, MerchantNo: param.OrderNo}, nil
+}
+
+func (c *outStub) QueryOut(orderNo, trxNo string) (*pb.OrderQueryResp, error) {
+	c.queries++
+	if len(c.query) == 0 {
+		return nil, apiError(http.StatusNotFound, "order not found")
+	}
+	if err := popError(&c.query); err != nil {
+		return nil, err
+	}
+	return &pb.OrderQueryResp{OrderNo: "T" + orderNo, MerchantNo: orderNo, Status: c.status}, nil
+}
+
+func popError(errs *[]error) error {
+	if len(*errs) == 0 {
+		return nil
+	}
+	err := (*errs)[0]
+	*errs = (*errs)[1:]
+	return err
+}
+
+var (
+	errTimeout = &TransportError{Transport: TransportHttp, Err: errors.New("timeout")}
+	errNotSent = &TransportError{Transport: TransportHttp, Err: errors.New("connection refused"), unsent: true}
+	errMissing = apiError(http.StatusNotFound, "order not found")
+)
+
+func newOutboxWorker(t *testing.T, client *outStub) (*MemoryOutbox, *OutboxWorker) {
+	t.Helper()
+	outbox := NewMemoryOutbox()
+	if _, err := outbox.Enqueue(&OutParam{OrderParam: OrderParam{OrderNo: "M1", Amount: 100}}); err != nil {
+		t.Fatal(err)
+	}
+	return outbox, NewOutboxWorker(outbox, client, testLogger())
+}
+
+func outboxState(t *testing.T, outbox Outbox) *OutboxEntry {
+	t.Helper()
+	e, err := outbox.Get("M1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestOutboxQueryBeforeCreate(t *testing.T) {
+	// 下单超时，查询失败、查询不存在后才重新下单
+	client := &outStub{create: []error{errTimeout}, query: []error{errTimeout, errMissing, errMissing}}
+	outbox, w := newOutboxWorker(t, client)
+
+	// 第一轮：下单超时记为已提交，轮询查询失败，保持未确认
+	if err := w.RunOnce(); err != nil {
+		t.Fatal(err)
+	}
+	if e := outboxState(t, outbox); e.State != OutboxSent || e.LastError == "" {
+		t.Fatalf("entry = %+v", e)
+	}
+	// 第二轮：轮询确认订单不存在，重新排队
+	_ = w.RunOnce()
+	if e := outboxState(t, outbox); e.State != OutboxPending {
+		t.Fatalf("entry = %+v", e)
+	}
+	// 第三轮：发送前再次确认不存在后下单
+	_ = w.RunOnce()
+	e := outboxState(t, outbox)
+	if e.State != OutboxSent || e.Resp == nil || client.creates != 2 {
+		t.Fatalf("entry = %+v, creates = %d", e, client.creates)
+	}
+}
+
+func TestOutboxResendQueryFailed(t *testing.T) {
+	client := &outStub{query: []error{errTimeout}}
+	outbox, w := newOutboxWorker(t, client)
+	// 模拟上次发送中崩溃的记录
+	outbox.entries["M1"].Attempts = 1
+	outbox.entries["M1"].State = OutboxSending
+	outbox.entries["M1"].UpdatedAt = time.Now().Add(-time.Hour)
+
+	entries, _ := outbox.Claim(0, time.Minute)
+	w.send(entries[0])
+	if client.creates != 0 {
+		t.Fatalf("created without confirming, creates = %d", client.creates)
+	}
+	if e := outboxState(t, outbox); e.State != OutboxSent {
+		t.Errorf("state = %s, want %s", e.State, OutboxSent)
+	}
+}
+
+func TestOutboxResendFound(t *testing.T) {
+	client := &outStub{query: []error{nil}, status: pb.ORDER_STATUS_SUCCESS}
+	outbox, w := newOutboxWorker(t, client)
+	outbox.entries["M1"].Attempts = 1
+
+	_ = w.RunOnce()
+	if e := outboxState(t, outbox); e.State != OutboxFinished || e.Status != pb.ORDER_STATUS_SUCCESS || client.creates != 0 {
+		t.Errorf("entry = %+v, creates = %d", e, client.creates)
+	}
+}
+
+func TestOutboxApiError(t *testing.T) {
+	// 重复下单：订单已存在，不标记为失败
+	client := &outStub{create: []error{apiError(http.StatusConflict, "duplicate order")}, query: []error{nil}, status: pb.ORDER_STATUS_PROCESSING}
+	outbox, w := newOutboxWorker(t, client)
+	_ = w.RunOnce()
+	if e := outboxState(t, outbox); e.State != OutboxSent || e.Resp == nil {
+		t.Errorf("duplicate: entry = %+v", e)
+	}
+
+	// 网关拒绝且订单不存在，标记为失败
+	client = &outStub{create: []error{apiError(http.StatusBadRequest, "invalid bank no")}}
+	outbox, w = newOutboxWorker(t, client)
+	_ = w.RunOnce()
+	if e := outboxState(t, outbox); e.State != OutboxFailed {
+		t.Errorf("rejected: entry = %+v", e)
+	}
+}
+
+func TestOutboxUnsentMaxAttempts(t *testing.T) {
+	client := &outStub{create: []error{errNotSent, errNotSent}}
+	outbox, w := newOutboxWorker(t, client)
+	w.MaxAttempts = 2
+
+	_ = w.RunOnce()
+	if e := outboxState(t, outbox); e.State != OutboxPending {
+		t.Fatalf("entry = %+v", e)
+	}
+	_ = w.RunOnce()
+	if e := outboxState(t, outbox); e.State != OutboxFailed || client.creates != 2 {
+		t.Errorf("entry = %+v, creates = %d", e, client.creates)
+	}
+}
+
+func TestOutboxClaimLease(t *testing.T) {
+	outbox := NewMemoryOutbox()
+	_, _ = outbox.Enqueue(&OutParam{OrderParam: OrderParam{OrderNo: "M1"}})
+
+	if entries, _ := outbox.Claim(0, time.Minute); len(entries) != 1 || entries[0].Attempts != 1 {
+		t.Fatalf("entries = %+v", entries)
+	}
+	// 租约内不会被其他 worker 重复领取
+	if entries, _ := outbox.Claim(0, time.Minute); len(entries) != 0 {
+		t.Fatalf("claimed during lease: %+v", entries)
+	}
+	// 租约过期视为 worker 崩溃，重新领取
+	outbox.entries["M1"].UpdatedAt = time.Now().Add(-2 * time.Minute)
+	if entries, _ := outbox.Claim(0, time.Minute); len(entries) != 1 || entries[0].Attempts != 2 {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestOutboxHandleCallback(t *testing.T) {
+	outbox, w := newOutboxWorker(t, &outStub{})
+	_ = outbox.MarkSent("M1", &pb.OutResp{OrderNo: "T1"})
+
+	if err := w.HandleCallback(&pb.CallbackParam{MerchantNo: "M1", Status: pb.ORDER_STATUS_PROCESSING}); err != nil {
+		t.Fatal(err)
+	}
+	if e := outboxState(t, outbox); e.State != OutboxSent || e.Status != pb.ORDER_STATUS_PROCESSING {
+		t.Fatalf("entry = %+v", e)
+	}
+	if err := w.HandleCallback(&pb.CallbackParam{MerchantNo: "M1", Status: pb.ORDER_STATUS_SUCCESS}); err != nil {
+		t.Fatal(err)
+	}
+	// 终态后不再被旧回调改变
+	_ = w.HandleCallback(&pb.CallbackParam{MerchantNo: "M1", Status: pb.ORDER_STATUS_PROCESSING})
+	if e := outboxState(t, outbox); e.State != OutboxFinished || e.Status != pb.ORDER_STATUS_SUCCESS {
+		t.Errorf("entry = %+v", e)
+	}
+	// 不属于发件箱的回调忽略
+	if err := w.HandleCallback(&pb.CallbackParam{MerchantNo: "OTHER", Status: pb.ORDER_STATUS_SUCCESS}); err != nil {
+		t.Errorf("err = %v", err)
+	}
+}
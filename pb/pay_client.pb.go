@@ -29,6 +29,9 @@ const (
 	ORDER_STATUS_ABNORMAL   ORDER_STATUS = 2  //异常
 	ORDER_STATUS_FAILURE    ORDER_STATUS = 9  //失败
 	ORDER_STATUS_SUCCESS    ORDER_STATUS = 10 //成功
+	ORDER_STATUS_REFUNDING  ORDER_STATUS = 11 //退款中
+	ORDER_STATUS_REFUNDED   ORDER_STATUS = 12 //已退款
+	ORDER_STATUS_CANCELED   ORDER_STATUS = 13 //已取消
 )
 
 // Enum value maps for ORDER_STATUS.
@@ -39,6 +42,9 @@ var (
 		2:  "ABNORMAL",
 		9:  "FAILURE",
 		10: "SUCCESS",
+		11: "REFUNDING",
+		12: "REFUNDED",
+		13: "CANCELED",
 	}
 	ORDER_STATUS_value = map[string]int32{
 		"WAIT":       0,
@@ -46,6 +52,9 @@ var (
 		"ABNORMAL":   2,
 		"FAILURE":    9,
 		"SUCCESS":    10,
+		"REFUNDING":  11,
+		"REFUNDED":   12,
+		"CANCELED":   13,
 	}
 )
 
@@ -83,6 +92,7 @@ const (
 	ORDER_TYPE_RECEIVE ORDER_TYPE = 1
 	ORDER_TYPE_OUT     ORDER_TYPE = 2
 	ORDER_TYPE_VIRTUAL ORDER_TYPE = 3
+	ORDER_TYPE_REFUND  ORDER_TYPE = 4
 )
 
 // Enum value maps for ORDER_TYPE.
@@ -92,12 +102,14 @@ var (
 		1: "RECEIVE",
 		2: "OUT",
 		3: "VIRTUAL",
+		4: "REFUND",
 	}
 	ORDER_TYPE_value = map[string]int32{
 		"ALL":     0,
 		"RECEIVE": 1,
 		"OUT":     2,
 		"VIRTUAL": 3,
+		"REFUND":  4,
 	}
 )
 
@@ -1002,14 +1014,16 @@ func (x *OrderQueryResp) GetUpdateTime() int64 {
 
 type CallbackParam struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	OrderNo       string                 `protobuf:"bytes,1,opt,name=order_no,json=orderNo,proto3" json:"order_no,omitempty"`           //订单号
-	MerchantNo    string                 `protobuf:"bytes,2,opt,name=merchant_no,json=merchantNo,proto3" json:"merchant_no,omitempty"`  //商户订单号
-	RealAmount    int64                  `protobuf:"varint,4,opt,name=real_amount,json=realAmount,proto3" json:"real_amount,omitempty"` //实际金额
-	Fee           int64                  `protobuf:"varint,5,opt,name=fee,proto3" json:"fee,omitempty"`                                 //平台手续费
-	Status        ORDER_STATUS           `protobuf:"varint,6,opt,name=status,proto3,enum=pb.ORDER_STATUS" json:"status,omitempty"`      //订单状态
-	Remark        string                 `protobuf:"bytes,7,opt,name=remark,proto3" json:"remark,omitempty"`                            //备注
-	FinishTime    int64                  `protobuf:"varint,8,opt,name=finish_time,json=finishTime,proto3" json:"finish_time,omitempty"` //结束时间
-	Uid           string                 `protobuf:"bytes,9,opt,name=uid,proto3" json:"uid,omitempty"`                                  //商户用户ID
+	OrderNo       string                 `protobuf:"bytes,1,opt,name=order_no,json=orderNo,proto3" json:"order_no,omitempty"`                  //订单号
+	MerchantNo    string                 `protobuf:"bytes,2,opt,name=merchant_no,json=merchantNo,proto3" json:"merchant_no,omitempty"`         //商户订单号
+	RealAmount    int64                  `protobuf:"varint,4,opt,name=real_amount,json=realAmount,proto3" json:"real_amount,omitempty"`        //实际金额
+	Fee           int64                  `protobuf:"varint,5,opt,name=fee,proto3" json:"fee,omitempty"`                                        //平台手续费
+	Status        ORDER_STATUS           `protobuf:"varint,6,opt,name=status,proto3,enum=pb.ORDER_STATUS" json:"status,omitempty"`             //订单状态
+	Remark        string                 `protobuf:"bytes,7,opt,name=remark,proto3" json:"remark,omitempty"`                                   //备注
+	FinishTime    int64                  `protobuf:"varint,8,opt,name=finish_time,json=finishTime,proto3" json:"finish_time,omitempty"`        //结束时间
+	Uid           string                 `protobuf:"bytes,9,opt,name=uid,proto3" json:"uid,omitempty"`                                         //商户用户ID
+	RefundNo      string                 `protobuf:"bytes,10,opt,name=refund_no,json=refundNo,proto3" json:"refund_no,omitempty"`              //商户退款单号，退款回调时非空，merchant_no 为原收款单号
+	RefundAmount  int64                  `protobuf:"varint,11,opt,name=refund_amount,json=refundAmount,proto3" json:"refund_amount,omitempty"` //退款金额
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1100,6 +1114,20 @@ func (x *CallbackParam) GetUid() string {
 	return ""
 }
 
+func (x *CallbackParam) GetRefundNo() string {
+	if x != nil {
+		return x.RefundNo
+	}
+	return ""
+}
+
+func (x *CallbackParam) GetRefundAmount() int64 {
+	if x != nil {
+		return x.RefundAmount
+	}
+	return 0
+}
+
 type ChannelQueryParam struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	OrderType     ORDER_TYPE             `protobuf:"varint,1,opt,name=order_type,json=orderType,proto3,enum=pb.ORDER_TYPE" json:"order_type,omitempty"`
@@ -1374,149 +1402,1128 @@ func (x *MerchantBalanceResp) GetSettlement() int64 {
 	return 0
 }
 
-var File_pay_client_proto protoreflect.FileDescriptor
+// 订单状态订阅参数
+type WatchOrdersParam struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderType     ORDER_TYPE             `protobuf:"varint,1,opt,name=order_type,json=orderType,proto3,enum=pb.ORDER_TYPE" json:"order_type,omitempty"` //订单类型，ALL 表示全部
+	MerchantNos   []string               `protobuf:"bytes,2,rep,name=merchant_nos,json=merchantNos,proto3" json:"merchant_nos,omitempty"`               //商户订单号，为空时订阅全部订单
+	Cursor        string                 `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`                                            //从该游标之后继续推送，为空时只推送订阅之后的变更
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_pay_client_proto_rawDesc = "" +
-	"\n" +
-	"\x10pay_client.proto\x12\x02pb\"P\n" +
-	"\fpay_rpc_resp\x12\x12\n" +
-	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12\x12\n" +
-	"\x04data\x18\x03 \x01(\tR\x04data\"<\n" +
-	"\rpay_rpc_param\x12\x17\n" +
-	"\aapp_key\x18\x01 \x01(\tR\x06appKey\x12\x12\n" +
-	"\x04data\x18\x02 \x01(\tR\x04data\"\xd4\x01\n" +
-	"\rvirtual_param\x12\x19\n" +
-	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x10\n" +
-	"\x03uid\x18\x03 \x01(\tR\x03uid\x12\x0e\n" +
-	"\x02ip\x18\x04 \x01(\tR\x02ip\x12\x14\n" +
-	"\x05email\x18\x05 \x01(\tR\x05email\x12\x14\n" +
-	"\x05phone\x18\x06 \x01(\tR\x05phone\x12\x12\n" +
-	"\x04name\x18\a \x01(\tR\x04name\x12\x10\n" +
-	"\x03pid\x18\n" +
-	" \x01(\x05R\x03pid\x12\x15\n" +
-	"\x06id_num\x18\v \x01(\tR\x05idNum\x12\x1d\n" +
-	"\n" +
-	"notify_url\x18\f \x01(\tR\tnotifyUrl\"\xa5\x01\n" +
-	"\fvirtual_resp\x12\x19\n" +
-	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x1f\n" +
-	"\vmerchant_no\x18\x02 \x01(\tR\n" +
-	"merchantNo\x12!\n" +
-	"\faccount_name\x18\x03 \x01(\tR\vaccountName\x12\x1d\n" +
-	"\n" +
-	"account_no\x18\x04 \x01(\tR\taccountNo\x12\x17\n" +
-	"\apay_url\x18\x05 \x01(\tR\x06payUrl\"\xcd\x02\n" +
-	"\rreceive_param\x12\x19\n" +
-	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x16\n" +
-	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x10\n" +
-	"\x03uid\x18\x03 \x01(\tR\x03uid\x12\x0e\n" +
-	"\x02ip\x18\x04 \x01(\tR\x02ip\x12\x14\n" +
-	"\x05email\x18\x05 \x01(\tR\x05email\x12\x14\n" +
-	"\x05phone\x18\x06 \x01(\tR\x05phone\x12\x12\n" +
-	"\x04name\x18\a \x01(\tR\x04name\x12\x12\n" +
-	"\x04mode\x18\b \x01(\tR\x04mode\x12\x10\n" +
-	"\x03pid\x18\t \x01(\x05R\x03pid\x12\x15\n" +
-	"\x06id_num\x18\n" +
-	" \x01(\tR\x05idNum\x12\x1d\n" +
-	"\n" +
-	"notify_url\x18\v \x01(\tR\tnotifyUrl\x12\x1d\n" +
-	"\n" +
-	"return_url\x18\f \x01(\tR\treturnUrl\x12\x18\n" +
-	"\asubject\x18\x0e \x01(\tR\asubject\x12\x12\n" +
-	"\x04body\x18\x0f \x01(\tR\x04body\"c\n" +
-	"\freceive_resp\x12\x19\n" +
-	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x1f\n" +
-	"\vmerchant_no\x18\x02 \x01(\tR\n" +
-	"merchantNo\x12\x17\n" +
-	"\apay_url\x18\x03 \x01(\tR\x06payUrl\"\xfd\x02\n" +
-	"\tout_param\x12\x19\n" +
-	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x16\n" +
-	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x10\n" +
-	"\x03uid\x18\x03 \x01(\tR\x03uid\x12\x0e\n" +
-	"\x02ip\x18\x04 \x01(\tR\x02ip\x12\x14\n" +
-	"\x05email\x18\x05 \x01(\tR\x05email\x12\x14\n" +
-	"\x05phone\x18\x06 \x01(\tR\x05phone\x12\x12\n" +
-	"\x04name\x18\a \x01(\tR\x04name\x12\x15\n" +
-	"\x06id_num\x18\b \x01(\tR\x05idNum\x12\x10\n" +
-	"\x03pid\x18\t \x01(\x05R\x03pid\x12\x17\n" +
-	"\abank_no\x18\n" +
-	" \x01(\tR\x06bankNo\x12\x1b\n" +
-	"\tbank_code\x18\v \x01(\tR\bbankCode\x12\x1b\n" +
-	"\tbank_name\x18\f \x01(\tR\bbankName\x12\x12\n" +
-	"\x04mode\x18\r \x01(\tR\x04mode\x12\x1d\n" +
-	"\n" +
-	"notify_url\x18\x0e \x01(\tR\tnotifyUrl\x12\x18\n" +
-	"\asubject\x18\x0f \x01(\tR\asubject\x12\x12\n" +
-	"\x04body\x18\x10 \x01(\tR\x04body\"F\n" +
-	"\bout_resp\x12\x19\n" +
-	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x1f\n" +
-	"\vmerchant_no\x18\x02 \x01(\tR\n" +
-	"merchantNo\"O\n" +
-	"\x11order_query_param\x12\x19\n" +
-	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x1f\n" +
-	"\vmerchant_no\x18\x02 \x01(\tR\n" +
-	"merchantNo\"\xda\x01\n" +
-	"\x10order_query_resp\x12\x19\n" +
-	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x1f\n" +
-	"\vmerchant_no\x18\x02 \x01(\tR\n" +
-	"merchantNo\x12\x15\n" +
-	"\x06pay_no\x18\x03 \x01(\tR\x05payNo\x12\x16\n" +
-	"\x06amount\x18\x04 \x01(\x03R\x06amount\x12\x10\n" +
-	"\x03fee\x18\x05 \x01(\x03R\x03fee\x12(\n" +
-	"\x06status\x18\x06 \x01(\x0e2\x10.pb.ORDER_STATUSR\x06status\x12\x1f\n" +
-	"\vupdate_time\x18\a \x01(\x03R\n" +
-	"updateTime\"\xf4\x01\n" +
-	"\x0ecallback_param\x12\x19\n" +
-	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x1f\n" +
-	"\vmerchant_no\x18\x02 \x01(\tR\n" +
-	"merchantNo\x12\x1f\n" +
-	"\vreal_amount\x18\x04 \x01(\x03R\n" +
-	"realAmount\x12\x10\n" +
-	"\x03fee\x18\x05 \x01(\x03R\x03fee\x12(\n" +
-	"\x06status\x18\x06 \x01(\x0e2\x10.pb.ORDER_STATUSR\x06status\x12\x16\n" +
-	"\x06remark\x18\a \x01(\tR\x06remark\x12\x1f\n" +
-	"\vfinish_time\x18\b \x01(\x03R\n" +
-	"finishTime\x12\x10\n" +
-	"\x03uid\x18\t \x01(\tR\x03uid\"D\n" +
-	"\x13channel_query_param\x12-\n" +
-	"\n" +
-	"order_type\x18\x01 \x01(\x0e2\x0e.pb.ORDER_TYPER\torderType\"\x8f\x02\n" +
-	"\x12channel_query_resp\x12\x18\n" +
-	"\achannel\x18\x01 \x01(\x03R\achannel\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
-	"\x04type\x18\x03 \x01(\x05R\x04type\x12\x16\n" +
-	"\x06status\x18\x04 \x01(\x05R\x06status\x12\x10\n" +
-	"\x03pid\x18\x05 \x01(\x05R\x03pid\x12\x1d\n" +
-	"\n" +
-	"single_min\x18\x06 \x01(\x03R\tsingleMin\x12\x1d\n" +
-	"\n" +
-	"single_max\x18\a \x01(\x03R\tsingleMax\x12\x17\n" +
-	"\aday_max\x18\b \x01(\x03R\x06dayMax\x126\n" +
-	"\rwithdraw_mode\x18\t \x03(\v2\x11.pb.withdraw_modeR\fwithdrawMode\"7\n" +
-	"\rwithdraw_mode\x12\x12\n" +
-	"\x04code\x18\x01 \x01(\tR\x04code\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\"\x7f\n" +
-	"\x15merchant_balance_resp\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
-	"\x05total\x18\x02 \x01(\x03R\x05total\x12\x1c\n" +
-	"\tavailable\x18\x03 \x01(\x03R\tavailable\x12\x1e\n" +
-	"\n" +
-	"settlement\x18\x04 \x01(\x03R\n" +
-	"settlement*P\n" +
-	"\fORDER_STATUS\x12\b\n" +
-	"\x04WAIT\x10\x00\x12\x0e\n" +
-	"\n" +
-	"PROCESSING\x10\x01\x12\f\n" +
-	"\bABNORMAL\x10\x02\x12\v\n" +
-	"\aFAILURE\x10\t\x12\v\n" +
-	"\aSUCCESS\x10\n" +
-	"*8\n" +
-	"\n" +
-	"ORDER_TYPE\x12\a\n" +
-	"\x03ALL\x10\x00\x12\v\n" +
-	"\aRECEIVE\x10\x01\x12\a\n" +
-	"\x03OUT\x10\x02\x12\v\n" +
-	"\aVIRTUAL\x10\x032\xf8\x02\n" +
+func (x *WatchOrdersParam) Reset() {
+	*x = WatchOrdersParam{}
+	mi := &file_pay_client_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchOrdersParam) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchOrdersParam) ProtoMessage() {}
+
+func (x *WatchOrdersParam) ProtoReflect() protoreflect.Message {
+	mi := &file_pay_client_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchOrdersParam.ProtoReflect.Descriptor instead.
+func (*WatchOrdersParam) Descriptor() ([]byte, []int) {
+	return file_pay_client_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *WatchOrdersParam) GetOrderType() ORDER_TYPE {
+	if x != nil {
+		return x.OrderType
+	}
+	return ORDER_TYPE_ALL
+}
+
+func (x *WatchOrdersParam) GetMerchantNos() []string {
+	if x != nil {
+		return x.MerchantNos
+	}
+	return nil
+}
+
+func (x *WatchOrdersParam) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+// 订单状态变更事件
+type OrderStatusEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cursor        string                 `protobuf:"bytes,1,opt,name=cursor,proto3" json:"cursor,omitempty"`                                            //事件游标，重连时用于续传
+	OrderType     ORDER_TYPE             `protobuf:"varint,2,opt,name=order_type,json=orderType,proto3,enum=pb.ORDER_TYPE" json:"order_type,omitempty"` //订单类型
+	OrderNo       string                 `protobuf:"bytes,3,opt,name=order_no,json=orderNo,proto3" json:"order_no,omitempty"`                           //订单号
+	MerchantNo    string                 `protobuf:"bytes,4,opt,name=merchant_no,json=merchantNo,proto3" json:"merchant_no,omitempty"`                  //商户订单号
+	Amount        int64                  `protobuf:"varint,5,opt,name=amount,proto3" json:"amount,omitempty"`                                           //金额
+	Fee           int64                  `protobuf:"varint,6,opt,name=fee,proto3" json:"fee,omitempty"`                                                 //手续费
+	Status        ORDER_STATUS           `protobuf:"varint,7,opt,name=status,proto3,enum=pb.ORDER_STATUS" json:"status,omitempty"`                      //状态
+	UpdateTime    int64                  `protobuf:"varint,8,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`                 //更新时间
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderStatusEvent) Reset() {
+	*x = OrderStatusEvent{}
+	mi := &file_pay_client_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderStatusEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderStatusEvent) ProtoMessage() {}
+
+func (x *OrderStatusEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_pay_client_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderStatusEvent.ProtoReflect.Descriptor instead.
+func (*OrderStatusEvent) Descriptor() ([]byte, []int) {
+	return file_pay_client_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *OrderStatusEvent) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *OrderStatusEvent) GetOrderType() ORDER_TYPE {
+	if x != nil {
+		return x.OrderType
+	}
+	return ORDER_TYPE_ALL
+}
+
+func (x *OrderStatusEvent) GetOrderNo() string {
+	if x != nil {
+		return x.OrderNo
+	}
+	return ""
+}
+
+func (x *OrderStatusEvent) GetMerchantNo() string {
+	if x != nil {
+		return x.MerchantNo
+	}
+	return ""
+}
+
+func (x *OrderStatusEvent) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *OrderStatusEvent) GetFee() int64 {
+	if x != nil {
+		return x.Fee
+	}
+	return 0
+}
+
+func (x *OrderStatusEvent) GetStatus() ORDER_STATUS {
+	if x != nil {
+		return x.Status
+	}
+	return ORDER_STATUS_WAIT
+}
+
+func (x *OrderStatusEvent) GetUpdateTime() int64 {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return 0
+}
+
+// 批量订单查询参数
+type BatchQueryParam struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderType     ORDER_TYPE             `protobuf:"varint,1,opt,name=order_type,json=orderType,proto3,enum=pb.ORDER_TYPE" json:"order_type,omitempty"` //订单类型，RECEIVE 或 OUT
+	MerchantNos   []string               `protobuf:"bytes,2,rep,name=merchant_nos,json=merchantNos,proto3" json:"merchant_nos,omitempty"`               //商户订单号
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchQueryParam) Reset() {
+	*x = BatchQueryParam{}
+	mi := &file_pay_client_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchQueryParam) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchQueryParam) ProtoMessage() {}
+
+func (x *BatchQueryParam) ProtoReflect() protoreflect.Message {
+	mi := &file_pay_client_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchQueryParam.ProtoReflect.Descriptor instead.
+func (*BatchQueryParam) Descriptor() ([]byte, []int) {
+	return file_pay_client_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *BatchQueryParam) GetOrderType() ORDER_TYPE {
+	if x != nil {
+		return x.OrderType
+	}
+	return ORDER_TYPE_ALL
+}
+
+func (x *BatchQueryParam) GetMerchantNos() []string {
+	if x != nil {
+		return x.MerchantNos
+	}
+	return nil
+}
+
+// 批量订单查询中单个订单的结果
+type BatchQueryItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MerchantNo    string                 `protobuf:"bytes,1,opt,name=merchant_no,json=merchantNo,proto3" json:"merchant_no,omitempty"` //商户订单号
+	Code          int32                  `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`                              //200 表示成功
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`                         //错误信息
+	Order         *OrderQueryResp        `protobuf:"bytes,4,opt,name=order,proto3" json:"order,omitempty"`                             //订单
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchQueryItem) Reset() {
+	*x = BatchQueryItem{}
+	mi := &file_pay_client_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchQueryItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchQueryItem) ProtoMessage() {}
+
+func (x *BatchQueryItem) ProtoReflect() protoreflect.Message {
+	mi := &file_pay_client_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchQueryItem.ProtoReflect.Descriptor instead.
+func (*BatchQueryItem) Descriptor() ([]byte, []int) {
+	return file_pay_client_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *BatchQueryItem) GetMerchantNo() string {
+	if x != nil {
+		return x.MerchantNo
+	}
+	return ""
+}
+
+func (x *BatchQueryItem) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *BatchQueryItem) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *BatchQueryItem) GetOrder() *OrderQueryResp {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+// 批量订单查询响应结果
+type BatchQueryResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*BatchQueryItem      `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchQueryResp) Reset() {
+	*x = BatchQueryResp{}
+	mi := &file_pay_client_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchQueryResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchQueryResp) ProtoMessage() {}
+
+func (x *BatchQueryResp) ProtoReflect() protoreflect.Message {
+	mi := &file_pay_client_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchQueryResp.ProtoReflect.Descriptor instead.
+func (*BatchQueryResp) Descriptor() ([]byte, []int) {
+	return file_pay_client_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *BatchQueryResp) GetItems() []*BatchQueryItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// 退款请求参数
+type RefundParam struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderNo       string                 `protobuf:"bytes,1,opt,name=order_no,json=orderNo,proto3" json:"order_no,omitempty"`          //原收款单订单号
+	MerchantNo    string                 `protobuf:"bytes,2,opt,name=merchant_no,json=merchantNo,proto3" json:"merchant_no,omitempty"` //原收款单商户订单号
+	RefundNo      string                 `protobuf:"bytes,3,opt,name=refund_no,json=refundNo,proto3" json:"refund_no,omitempty"`       //商户退款单号
+	Amount        int64                  `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`                          //退款金额（分）
+	Reason        string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`                           //退款原因
+	NotifyUrl     string                 `protobuf:"bytes,6,opt,name=notify_url,json=notifyUrl,proto3" json:"notify_url,omitempty"`    //退款回调地址
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefundParam) Reset() {
+	*x = RefundParam{}
+	mi := &file_pay_client_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundParam) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundParam) ProtoMessage() {}
+
+func (x *RefundParam) ProtoReflect() protoreflect.Message {
+	mi := &file_pay_client_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundParam.ProtoReflect.Descriptor instead.
+func (*RefundParam) Descriptor() ([]byte, []int) {
+	return file_pay_client_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RefundParam) GetOrderNo() string {
+	if x != nil {
+		return x.OrderNo
+	}
+	return ""
+}
+
+func (x *RefundParam) GetMerchantNo() string {
+	if x != nil {
+		return x.MerchantNo
+	}
+	return ""
+}
+
+func (x *RefundParam) GetRefundNo() string {
+	if x != nil {
+		return x.RefundNo
+	}
+	return ""
+}
+
+func (x *RefundParam) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *RefundParam) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RefundParam) GetNotifyUrl() string {
+	if x != nil {
+		return x.NotifyUrl
+	}
+	return ""
+}
+
+// 退款响应结果
+type RefundResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderNo       string                 `protobuf:"bytes,1,opt,name=order_no,json=orderNo,proto3" json:"order_no,omitempty"`          //退款订单号
+	MerchantNo    string                 `protobuf:"bytes,2,opt,name=merchant_no,json=merchantNo,proto3" json:"merchant_no,omitempty"` //原收款单商户订单号
+	RefundNo      string                 `protobuf:"bytes,3,opt,name=refund_no,json=refundNo,proto3" json:"refund_no,omitempty"`       //商户退款单号
+	Amount        int64                  `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`                          //退款金额
+	Status        ORDER_STATUS           `protobuf:"varint,5,opt,name=status,proto3,enum=pb.ORDER_STATUS" json:"status,omitempty"`     //退款状态
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefundResp) Reset() {
+	*x = RefundResp{}
+	mi := &file_pay_client_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundResp) ProtoMessage() {}
+
+func (x *RefundResp) ProtoReflect() protoreflect.Message {
+	mi := &file_pay_client_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundResp.ProtoReflect.Descriptor instead.
+func (*RefundResp) Descriptor() ([]byte, []int) {
+	return file_pay_client_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RefundResp) GetOrderNo() string {
+	if x != nil {
+		return x.OrderNo
+	}
+	return ""
+}
+
+func (x *RefundResp) GetMerchantNo() string {
+	if x != nil {
+		return x.MerchantNo
+	}
+	return ""
+}
+
+func (x *RefundResp) GetRefundNo() string {
+	if x != nil {
+		return x.RefundNo
+	}
+	return ""
+}
+
+func (x *RefundResp) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *RefundResp) GetStatus() ORDER_STATUS {
+	if x != nil {
+		return x.Status
+	}
+	return ORDER_STATUS_WAIT
+}
+
+// 取消付款单请求参数
+type CancelParam struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderNo       string                 `protobuf:"bytes,1,opt,name=order_no,json=orderNo,proto3" json:"order_no,omitempty"`          //订单号
+	MerchantNo    string                 `protobuf:"bytes,2,opt,name=merchant_no,json=merchantNo,proto3" json:"merchant_no,omitempty"` //商户订单号
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`                           //取消原因
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelParam) Reset() {
+	*x = CancelParam{}
+	mi := &file_pay_client_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelParam) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelParam) ProtoMessage() {}
+
+func (x *CancelParam) ProtoReflect() protoreflect.Message {
+	mi := &file_pay_client_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelParam.ProtoReflect.Descriptor instead.
+func (*CancelParam) Descriptor() ([]byte, []int) {
+	return file_pay_client_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CancelParam) GetOrderNo() string {
+	if x != nil {
+		return x.OrderNo
+	}
+	return ""
+}
+
+func (x *CancelParam) GetMerchantNo() string {
+	if x != nil {
+		return x.MerchantNo
+	}
+	return ""
+}
+
+func (x *CancelParam) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// 取消付款单响应结果
+type CancelResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderNo       string                 `protobuf:"bytes,1,opt,name=order_no,json=orderNo,proto3" json:"order_no,omitempty"`          //订单号
+	MerchantNo    string                 `protobuf:"bytes,2,opt,name=merchant_no,json=merchantNo,proto3" json:"merchant_no,omitempty"` //商户订单号
+	Status        ORDER_STATUS           `protobuf:"varint,3,opt,name=status,proto3,enum=pb.ORDER_STATUS" json:"status,omitempty"`     //状态
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelResp) Reset() {
+	*x = CancelResp{}
+	mi := &file_pay_client_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelResp) ProtoMessage() {}
+
+func (x *CancelResp) ProtoReflect() protoreflect.Message {
+	mi := &file_pay_client_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelResp.ProtoReflect.Descriptor instead.
+func (*CancelResp) Descriptor() ([]byte, []int) {
+	return file_pay_client_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CancelResp) GetOrderNo() string {
+	if x != nil {
+		return x.OrderNo
+	}
+	return ""
+}
+
+func (x *CancelResp) GetMerchantNo() string {
+	if x != nil {
+		return x.MerchantNo
+	}
+	return ""
+}
+
+func (x *CancelResp) GetStatus() ORDER_STATUS {
+	if x != nil {
+		return x.Status
+	}
+	return ORDER_STATUS_WAIT
+}
+
+// 订单列表查询参数
+type ListOrdersParam struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderType     ORDER_TYPE             `protobuf:"varint,1,opt,name=order_type,json=orderType,proto3,enum=pb.ORDER_TYPE" json:"order_type,omitempty"` //订单类型，ALL 表示全部
+	Status        []ORDER_STATUS         `protobuf:"varint,2,rep,packed,name=status,proto3,enum=pb.ORDER_STATUS" json:"status,omitempty"`               //订单状态，为空表示全部
+	StartTime     int64                  `protobuf:"varint,3,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`                    //创建时间起（包含）
+	EndTime       int64                  `protobuf:"varint,4,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`                          //创建时间止（不包含），0 表示不限
+	Pid           int32                  `protobuf:"varint,5,opt,name=pid,proto3" json:"pid,omitempty"`                                                 //通道ID，0 表示全部
+	Cursor        string                 `protobuf:"bytes,6,opt,name=cursor,proto3" json:"cursor,omitempty"`                                            //分页游标，为空时从第一页开始
+	Limit         int32                  `protobuf:"varint,7,opt,name=limit,proto3" json:"limit,omitempty"`                                             //每页数量
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOrdersParam) Reset() {
+	*x = ListOrdersParam{}
+	mi := &file_pay_client_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOrdersParam) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOrdersParam) ProtoMessage() {}
+
+func (x *ListOrdersParam) ProtoReflect() protoreflect.Message {
+	mi := &file_pay_client_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOrdersParam.ProtoReflect.Descriptor instead.
+func (*ListOrdersParam) Descriptor() ([]byte, []int) {
+	return file_pay_client_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ListOrdersParam) GetOrderType() ORDER_TYPE {
+	if x != nil {
+		return x.OrderType
+	}
+	return ORDER_TYPE_ALL
+}
+
+func (x *ListOrdersParam) GetStatus() []ORDER_STATUS {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *ListOrdersParam) GetStartTime() int64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *ListOrdersParam) GetEndTime() int64 {
+	if x != nil {
+		return x.EndTime
+	}
+	return 0
+}
+
+func (x *ListOrdersParam) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *ListOrdersParam) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListOrdersParam) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// 订单列表中的订单
+type OrderListItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderNo       string                 `protobuf:"bytes,1,opt,name=order_no,json=orderNo,proto3" json:"order_no,omitempty"`                           //订单号
+	MerchantNo    string                 `protobuf:"bytes,2,opt,name=merchant_no,json=merchantNo,proto3" json:"merchant_no,omitempty"`                  //商户订单号
+	OrderType     ORDER_TYPE             `protobuf:"varint,3,opt,name=order_type,json=orderType,proto3,enum=pb.ORDER_TYPE" json:"order_type,omitempty"` //订单类型
+	Pid           int32                  `protobuf:"varint,4,opt,name=pid,proto3" json:"pid,omitempty"`                                                 //通道ID
+	Uid           string                 `protobuf:"bytes,5,opt,name=uid,proto3" json:"uid,omitempty"`                                                  //商户用户ID
+	Amount        int64                  `protobuf:"varint,6,opt,name=amount,proto3" json:"amount,omitempty"`                                           //订单金额
+	RealAmount    int64                  `protobuf:"varint,7,opt,name=real_amount,json=realAmount,proto3" json:"real_amount,omitempty"`                 //实际金额
+	Fee           int64                  `protobuf:"varint,8,opt,name=fee,proto3" json:"fee,omitempty"`                                                 //手续费
+	Status        ORDER_STATUS           `protobuf:"varint,9,opt,name=status,proto3,enum=pb.ORDER_STATUS" json:"status,omitempty"`                      //状态
+	CreateTime    int64                  `protobuf:"varint,10,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`                //创建时间
+	UpdateTime    int64                  `protobuf:"varint,11,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`                //更新时间
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderListItem) Reset() {
+	*x = OrderListItem{}
+	mi := &file_pay_client_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderListItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderListItem) ProtoMessage() {}
+
+func (x *OrderListItem) ProtoReflect() protoreflect.Message {
+	mi := &file_pay_client_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderListItem.ProtoReflect.Descriptor instead.
+func (*OrderListItem) Descriptor() ([]byte, []int) {
+	return file_pay_client_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *OrderListItem) GetOrderNo() string {
+	if x != nil {
+		return x.OrderNo
+	}
+	return ""
+}
+
+func (x *OrderListItem) GetMerchantNo() string {
+	if x != nil {
+		return x.MerchantNo
+	}
+	return ""
+}
+
+func (x *OrderListItem) GetOrderType() ORDER_TYPE {
+	if x != nil {
+		return x.OrderType
+	}
+	return ORDER_TYPE_ALL
+}
+
+func (x *OrderListItem) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *OrderListItem) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+func (x *OrderListItem) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *OrderListItem) GetRealAmount() int64 {
+	if x != nil {
+		return x.RealAmount
+	}
+	return 0
+}
+
+func (x *OrderListItem) GetFee() int64 {
+	if x != nil {
+		return x.Fee
+	}
+	return 0
+}
+
+func (x *OrderListItem) GetStatus() ORDER_STATUS {
+	if x != nil {
+		return x.Status
+	}
+	return ORDER_STATUS_WAIT
+}
+
+func (x *OrderListItem) GetCreateTime() int64 {
+	if x != nil {
+		return x.CreateTime
+	}
+	return 0
+}
+
+func (x *OrderListItem) GetUpdateTime() int64 {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return 0
+}
+
+// 订单列表响应结果
+type ListOrdersResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Orders        []*OrderListItem       `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` //下一页游标，为空表示没有更多
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOrdersResp) Reset() {
+	*x = ListOrdersResp{}
+	mi := &file_pay_client_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOrdersResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOrdersResp) ProtoMessage() {}
+
+func (x *ListOrdersResp) ProtoReflect() protoreflect.Message {
+	mi := &file_pay_client_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOrdersResp.ProtoReflect.Descriptor instead.
+func (*ListOrdersResp) Descriptor() ([]byte, []int) {
+	return file_pay_client_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListOrdersResp) GetOrders() []*OrderListItem {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+func (x *ListOrdersResp) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+var File_pay_client_proto protoreflect.FileDescriptor
+
+const file_pay_client_proto_rawDesc = "" +
+	"\n" +
+	"\x10pay_client.proto\x12\x02pb\"P\n" +
+	"\fpay_rpc_resp\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\tR\x04data\"<\n" +
+	"\rpay_rpc_param\x12\x17\n" +
+	"\aapp_key\x18\x01 \x01(\tR\x06appKey\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\tR\x04data\"\xd4\x01\n" +
+	"\rvirtual_param\x12\x19\n" +
+	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x10\n" +
+	"\x03uid\x18\x03 \x01(\tR\x03uid\x12\x0e\n" +
+	"\x02ip\x18\x04 \x01(\tR\x02ip\x12\x14\n" +
+	"\x05email\x18\x05 \x01(\tR\x05email\x12\x14\n" +
+	"\x05phone\x18\x06 \x01(\tR\x05phone\x12\x12\n" +
+	"\x04name\x18\a \x01(\tR\x04name\x12\x10\n" +
+	"\x03pid\x18\n" +
+	" \x01(\x05R\x03pid\x12\x15\n" +
+	"\x06id_num\x18\v \x01(\tR\x05idNum\x12\x1d\n" +
+	"\n" +
+	"notify_url\x18\f \x01(\tR\tnotifyUrl\"\xa5\x01\n" +
+	"\fvirtual_resp\x12\x19\n" +
+	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x1f\n" +
+	"\vmerchant_no\x18\x02 \x01(\tR\n" +
+	"merchantNo\x12!\n" +
+	"\faccount_name\x18\x03 \x01(\tR\vaccountName\x12\x1d\n" +
+	"\n" +
+	"account_no\x18\x04 \x01(\tR\taccountNo\x12\x17\n" +
+	"\apay_url\x18\x05 \x01(\tR\x06payUrl\"\xcd\x02\n" +
+	"\rreceive_param\x12\x19\n" +
+	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x10\n" +
+	"\x03uid\x18\x03 \x01(\tR\x03uid\x12\x0e\n" +
+	"\x02ip\x18\x04 \x01(\tR\x02ip\x12\x14\n" +
+	"\x05email\x18\x05 \x01(\tR\x05email\x12\x14\n" +
+	"\x05phone\x18\x06 \x01(\tR\x05phone\x12\x12\n" +
+	"\x04name\x18\a \x01(\tR\x04name\x12\x12\n" +
+	"\x04mode\x18\b \x01(\tR\x04mode\x12\x10\n" +
+	"\x03pid\x18\t \x01(\x05R\x03pid\x12\x15\n" +
+	"\x06id_num\x18\n" +
+	" \x01(\tR\x05idNum\x12\x1d\n" +
+	"\n" +
+	"notify_url\x18\v \x01(\tR\tnotifyUrl\x12\x1d\n" +
+	"\n" +
+	"return_url\x18\f \x01(\tR\treturnUrl\x12\x18\n" +
+	"\asubject\x18\x0e \x01(\tR\asubject\x12\x12\n" +
+	"\x04body\x18\x0f \x01(\tR\x04body\"c\n" +
+	"\freceive_resp\x12\x19\n" +
+	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x1f\n" +
+	"\vmerchant_no\x18\x02 \x01(\tR\n" +
+	"merchantNo\x12\x17\n" +
+	"\apay_url\x18\x03 \x01(\tR\x06payUrl\"\xfd\x02\n" +
+	"\tout_param\x12\x19\n" +
+	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x10\n" +
+	"\x03uid\x18\x03 \x01(\tR\x03uid\x12\x0e\n" +
+	"\x02ip\x18\x04 \x01(\tR\x02ip\x12\x14\n" +
+	"\x05email\x18\x05 \x01(\tR\x05email\x12\x14\n" +
+	"\x05phone\x18\x06 \x01(\tR\x05phone\x12\x12\n" +
+	"\x04name\x18\a \x01(\tR\x04name\x12\x15\n" +
+	"\x06id_num\x18\b \x01(\tR\x05idNum\x12\x10\n" +
+	"\x03pid\x18\t \x01(\x05R\x03pid\x12\x17\n" +
+	"\abank_no\x18\n" +
+	" \x01(\tR\x06bankNo\x12\x1b\n" +
+	"\tbank_code\x18\v \x01(\tR\bbankCode\x12\x1b\n" +
+	"\tbank_name\x18\f \x01(\tR\bbankName\x12\x12\n" +
+	"\x04mode\x18\r \x01(\tR\x04mode\x12\x1d\n" +
+	"\n" +
+	"notify_url\x18\x0e \x01(\tR\tnotifyUrl\x12\x18\n" +
+	"\asubject\x18\x0f \x01(\tR\asubject\x12\x12\n" +
+	"\x04body\x18\x10 \x01(\tR\x04body\"F\n" +
+	"\bout_resp\x12\x19\n" +
+	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x1f\n" +
+	"\vmerchant_no\x18\x02 \x01(\tR\n" +
+	"merchantNo\"O\n" +
+	"\x11order_query_param\x12\x19\n" +
+	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x1f\n" +
+	"\vmerchant_no\x18\x02 \x01(\tR\n" +
+	"merchantNo\"\xda\x01\n" +
+	"\x10order_query_resp\x12\x19\n" +
+	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x1f\n" +
+	"\vmerchant_no\x18\x02 \x01(\tR\n" +
+	"merchantNo\x12\x15\n" +
+	"\x06pay_no\x18\x03 \x01(\tR\x05payNo\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x03R\x06amount\x12\x10\n" +
+	"\x03fee\x18\x05 \x01(\x03R\x03fee\x12(\n" +
+	"\x06status\x18\x06 \x01(\x0e2\x10.pb.ORDER_STATUSR\x06status\x12\x1f\n" +
+	"\vupdate_time\x18\a \x01(\x03R\n" +
+	"updateTime\"\xb6\x02\n" +
+	"\x0ecallback_param\x12\x19\n" +
+	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x1f\n" +
+	"\vmerchant_no\x18\x02 \x01(\tR\n" +
+	"merchantNo\x12\x1f\n" +
+	"\vreal_amount\x18\x04 \x01(\x03R\n" +
+	"realAmount\x12\x10\n" +
+	"\x03fee\x18\x05 \x01(\x03R\x03fee\x12(\n" +
+	"\x06status\x18\x06 \x01(\x0e2\x10.pb.ORDER_STATUSR\x06status\x12\x16\n" +
+	"\x06remark\x18\a \x01(\tR\x06remark\x12\x1f\n" +
+	"\vfinish_time\x18\b \x01(\x03R\n" +
+	"finishTime\x12\x10\n" +
+	"\x03uid\x18\t \x01(\tR\x03uid\x12\x1b\n" +
+	"\trefund_no\x18\n" +
+	" \x01(\tR\brefundNo\x12#\n" +
+	"\rrefund_amount\x18\v \x01(\x03R\frefundAmount\"D\n" +
+	"\x13channel_query_param\x12-\n" +
+	"\n" +
+	"order_type\x18\x01 \x01(\x0e2\x0e.pb.ORDER_TYPER\torderType\"\x8f\x02\n" +
+	"\x12channel_query_resp\x12\x18\n" +
+	"\achannel\x18\x01 \x01(\x03R\achannel\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\x05R\x04type\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\x05R\x06status\x12\x10\n" +
+	"\x03pid\x18\x05 \x01(\x05R\x03pid\x12\x1d\n" +
+	"\n" +
+	"single_min\x18\x06 \x01(\x03R\tsingleMin\x12\x1d\n" +
+	"\n" +
+	"single_max\x18\a \x01(\x03R\tsingleMax\x12\x17\n" +
+	"\aday_max\x18\b \x01(\x03R\x06dayMax\x126\n" +
+	"\rwithdraw_mode\x18\t \x03(\v2\x11.pb.withdraw_modeR\fwithdrawMode\"7\n" +
+	"\rwithdraw_mode\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\x7f\n" +
+	"\x15merchant_balance_resp\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\x12\x1c\n" +
+	"\tavailable\x18\x03 \x01(\x03R\tavailable\x12\x1e\n" +
+	"\n" +
+	"settlement\x18\x04 \x01(\x03R\n" +
+	"settlement\"~\n" +
+	"\x12watch_orders_param\x12-\n" +
+	"\n" +
+	"order_type\x18\x01 \x01(\x0e2\x0e.pb.ORDER_TYPER\torderType\x12!\n" +
+	"\fmerchant_nos\x18\x02 \x03(\tR\vmerchantNos\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\tR\x06cursor\"\x8c\x02\n" +
+	"\x12order_status_event\x12\x16\n" +
+	"\x06cursor\x18\x01 \x01(\tR\x06cursor\x12-\n" +
+	"\n" +
+	"order_type\x18\x02 \x01(\x0e2\x0e.pb.ORDER_TYPER\torderType\x12\x19\n" +
+	"\border_no\x18\x03 \x01(\tR\aorderNo\x12\x1f\n" +
+	"\vmerchant_no\x18\x04 \x01(\tR\n" +
+	"merchantNo\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x03R\x06amount\x12\x10\n" +
+	"\x03fee\x18\x06 \x01(\x03R\x03fee\x12(\n" +
+	"\x06status\x18\a \x01(\x0e2\x10.pb.ORDER_STATUSR\x06status\x12\x1f\n" +
+	"\vupdate_time\x18\b \x01(\x03R\n" +
+	"updateTime\"e\n" +
+	"\x11batch_query_param\x12-\n" +
+	"\n" +
+	"order_type\x18\x01 \x01(\x0e2\x0e.pb.ORDER_TYPER\torderType\x12!\n" +
+	"\fmerchant_nos\x18\x02 \x03(\tR\vmerchantNos\"\x8d\x01\n" +
+	"\x10batch_query_item\x12\x1f\n" +
+	"\vmerchant_no\x18\x01 \x01(\tR\n" +
+	"merchantNo\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12*\n" +
+	"\x05order\x18\x04 \x01(\v2\x14.pb.order_query_respR\x05order\">\n" +
+	"\x10batch_query_resp\x12*\n" +
+	"\x05items\x18\x01 \x03(\v2\x14.pb.batch_query_itemR\x05items\"\xb6\x01\n" +
+	"\frefund_param\x12\x19\n" +
+	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x1f\n" +
+	"\vmerchant_no\x18\x02 \x01(\tR\n" +
+	"merchantNo\x12\x1b\n" +
+	"\trefund_no\x18\x03 \x01(\tR\brefundNo\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x03R\x06amount\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\x12\x1d\n" +
+	"\n" +
+	"notify_url\x18\x06 \x01(\tR\tnotifyUrl\"\xa8\x01\n" +
+	"\vrefund_resp\x12\x19\n" +
+	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x1f\n" +
+	"\vmerchant_no\x18\x02 \x01(\tR\n" +
+	"merchantNo\x12\x1b\n" +
+	"\trefund_no\x18\x03 \x01(\tR\brefundNo\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x03R\x06amount\x12(\n" +
+	"\x06status\x18\x05 \x01(\x0e2\x10.pb.ORDER_STATUSR\x06status\"b\n" +
+	"\fcancel_param\x12\x19\n" +
+	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x1f\n" +
+	"\vmerchant_no\x18\x02 \x01(\tR\n" +
+	"merchantNo\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"s\n" +
+	"\vcancel_resp\x12\x19\n" +
+	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x1f\n" +
+	"\vmerchant_no\x18\x02 \x01(\tR\n" +
+	"merchantNo\x12(\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x10.pb.ORDER_STATUSR\x06status\"\xe6\x01\n" +
+	"\x11list_orders_param\x12-\n" +
+	"\n" +
+	"order_type\x18\x01 \x01(\x0e2\x0e.pb.ORDER_TYPER\torderType\x12(\n" +
+	"\x06status\x18\x02 \x03(\x0e2\x10.pb.ORDER_STATUSR\x06status\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\x03 \x01(\x03R\tstartTime\x12\x19\n" +
+	"\bend_time\x18\x04 \x01(\x03R\aendTime\x12\x10\n" +
+	"\x03pid\x18\x05 \x01(\x05R\x03pid\x12\x16\n" +
+	"\x06cursor\x18\x06 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\a \x01(\x05R\x05limit\"\xd7\x02\n" +
+	"\x0forder_list_item\x12\x19\n" +
+	"\border_no\x18\x01 \x01(\tR\aorderNo\x12\x1f\n" +
+	"\vmerchant_no\x18\x02 \x01(\tR\n" +
+	"merchantNo\x12-\n" +
+	"\n" +
+	"order_type\x18\x03 \x01(\x0e2\x0e.pb.ORDER_TYPER\torderType\x12\x10\n" +
+	"\x03pid\x18\x04 \x01(\x05R\x03pid\x12\x10\n" +
+	"\x03uid\x18\x05 \x01(\tR\x03uid\x12\x16\n" +
+	"\x06amount\x18\x06 \x01(\x03R\x06amount\x12\x1f\n" +
+	"\vreal_amount\x18\a \x01(\x03R\n" +
+	"realAmount\x12\x10\n" +
+	"\x03fee\x18\b \x01(\x03R\x03fee\x12(\n" +
+	"\x06status\x18\t \x01(\x0e2\x10.pb.ORDER_STATUSR\x06status\x12\x1f\n" +
+	"\vcreate_time\x18\n" +
+	" \x01(\x03R\n" +
+	"createTime\x12\x1f\n" +
+	"\vupdate_time\x18\v \x01(\x03R\n" +
+	"updateTime\"`\n" +
+	"\x10list_orders_resp\x12+\n" +
+	"\x06orders\x18\x01 \x03(\v2\x13.pb.order_list_itemR\x06orders\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor*{\n" +
+	"\fORDER_STATUS\x12\b\n" +
+	"\x04WAIT\x10\x00\x12\x0e\n" +
+	"\n" +
+	"PROCESSING\x10\x01\x12\f\n" +
+	"\bABNORMAL\x10\x02\x12\v\n" +
+	"\aFAILURE\x10\t\x12\v\n" +
+	"\aSUCCESS\x10\n" +
+	"\x12\r\n" +
+	"\tREFUNDING\x10\v\x12\f\n" +
+	"\bREFUNDED\x10\f\x12\f\n" +
+	"\bCANCELED\x10\r*D\n" +
+	"\n" +
+	"ORDER_TYPE\x12\a\n" +
+	"\x03ALL\x10\x00\x12\v\n" +
+	"\aRECEIVE\x10\x01\x12\a\n" +
+	"\x03OUT\x10\x02\x12\v\n" +
+	"\aVIRTUAL\x10\x03\x12\n" +
+	"\n" +
+	"\x06REFUND\x10\x042\xf5\x04\n" +
 	"\vpay_service\x126\n" +
 	"\x0fvirtual_account\x12\x11.pb.pay_rpc_param\x1a\x10.pb.pay_rpc_resp\x12.\n" +
 	"\areceive\x12\x11.pb.pay_rpc_param\x1a\x10.pb.pay_rpc_resp\x124\n" +
@@ -1524,7 +2531,12 @@ const file_pay_client_proto_rawDesc = "" +
 	"\x03out\x12\x11.pb.pay_rpc_param\x1a\x10.pb.pay_rpc_resp\x120\n" +
 	"\tout_query\x12\x11.pb.pay_rpc_param\x1a\x10.pb.pay_rpc_resp\x124\n" +
 	"\rchannel_query\x12\x11.pb.pay_rpc_param\x1a\x10.pb.pay_rpc_resp\x127\n" +
-	"\x10merchant_balance\x12\x11.pb.pay_rpc_param\x1a\x10.pb.pay_rpc_respB\aZ\x05./;pbb\x06proto3"
+	"\x10merchant_balance\x12\x11.pb.pay_rpc_param\x1a\x10.pb.pay_rpc_resp\x125\n" +
+	"\fwatch_orders\x12\x11.pb.pay_rpc_param\x1a\x10.pb.pay_rpc_resp0\x01\x122\n" +
+	"\vbatch_query\x12\x11.pb.pay_rpc_param\x1a\x10.pb.pay_rpc_resp\x122\n" +
+	"\vlist_orders\x12\x11.pb.pay_rpc_param\x1a\x10.pb.pay_rpc_resp\x12-\n" +
+	"\x06refund\x12\x11.pb.pay_rpc_param\x1a\x10.pb.pay_rpc_resp\x12-\n" +
+	"\x06cancel\x12\x11.pb.pay_rpc_param\x1a\x10.pb.pay_rpc_respB\aZ\x05./;pbb\x06proto3"
 
 var (
 	file_pay_client_proto_rawDescOnce sync.Once
@@ -1539,7 +2551,7 @@ func file_pay_client_proto_rawDescGZIP() []byte {
 }
 
 var file_pay_client_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_pay_client_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_pay_client_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
 var file_pay_client_proto_goTypes = []any{
 	(ORDER_STATUS)(0),           // 0: pb.ORDER_STATUS
 	(ORDER_TYPE)(0),             // 1: pb.ORDER_TYPE
@@ -1558,31 +2570,66 @@ var file_pay_client_proto_goTypes = []any{
 	(*ChannelQueryResp)(nil),    // 14: pb.channel_query_resp
 	(*WithdrawMode)(nil),        // 15: pb.withdraw_mode
 	(*MerchantBalanceResp)(nil), // 16: pb.merchant_balance_resp
+	(*WatchOrdersParam)(nil),    // 17: pb.watch_orders_param
+	(*OrderStatusEvent)(nil),    // 18: pb.order_status_event
+	(*BatchQueryParam)(nil),     // 19: pb.batch_query_param
+	(*BatchQueryItem)(nil),      // 20: pb.batch_query_item
+	(*BatchQueryResp)(nil),      // 21: pb.batch_query_resp
+	(*RefundParam)(nil),         // 22: pb.refund_param
+	(*RefundResp)(nil),          // 23: pb.refund_resp
+	(*CancelParam)(nil),         // 24: pb.cancel_param
+	(*CancelResp)(nil),          // 25: pb.cancel_resp
+	(*ListOrdersParam)(nil),     // 26: pb.list_orders_param
+	(*OrderListItem)(nil),       // 27: pb.order_list_item
+	(*ListOrdersResp)(nil),      // 28: pb.list_orders_resp
 }
 var file_pay_client_proto_depIdxs = []int32{
 	0,  // 0: pb.order_query_resp.status:type_name -> pb.ORDER_STATUS
 	0,  // 1: pb.callback_param.status:type_name -> pb.ORDER_STATUS
 	1,  // 2: pb.channel_query_param.order_type:type_name -> pb.ORDER_TYPE
 	15, // 3: pb.channel_query_resp.withdraw_mode:type_name -> pb.withdraw_mode
-	3,  // 4: pb.pay_service.virtual_account:input_type -> pb.pay_rpc_param
-	3,  // 5: pb.pay_service.receive:input_type -> pb.pay_rpc_param
-	3,  // 6: pb.pay_service.receive_query:input_type -> pb.pay_rpc_param
-	3,  // 7: pb.pay_service.out:input_type -> pb.pay_rpc_param
-	3,  // 8: pb.pay_service.out_query:input_type -> pb.pay_rpc_param
-	3,  // 9: pb.pay_service.channel_query:input_type -> pb.pay_rpc_param
-	3,  // 10: pb.pay_service.merchant_balance:input_type -> pb.pay_rpc_param
-	2,  // 11: pb.pay_service.virtual_account:output_type -> pb.pay_rpc_resp
-	2,  // 12: pb.pay_service.receive:output_type -> pb.pay_rpc_resp
-	2,  // 13: pb.pay_service.receive_query:output_type -> pb.pay_rpc_resp
-	2,  // 14: pb.pay_service.out:output_type -> pb.pay_rpc_resp
-	2,  // 15: pb.pay_service.out_query:output_type -> pb.pay_rpc_resp
-	2,  // 16: pb.pay_service.channel_query:output_type -> pb.pay_rpc_resp
-	2,  // 17: pb.pay_service.merchant_balance:output_type -> pb.pay_rpc_resp
-	11, // [11:18] is the sub-list for method output_type
-	4,  // [4:11] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	1,  // 4: pb.watch_orders_param.order_type:type_name -> pb.ORDER_TYPE
+	1,  // 5: pb.order_status_event.order_type:type_name -> pb.ORDER_TYPE
+	0,  // 6: pb.order_status_event.status:type_name -> pb.ORDER_STATUS
+	1,  // 7: pb.batch_query_param.order_type:type_name -> pb.ORDER_TYPE
+	11, // 8: pb.batch_query_item.order:type_name -> pb.order_query_resp
+	20, // 9: pb.batch_query_resp.items:type_name -> pb.batch_query_item
+	0,  // 10: pb.refund_resp.status:type_name -> pb.ORDER_STATUS
+	0,  // 11: pb.cancel_resp.status:type_name -> pb.ORDER_STATUS
+	1,  // 12: pb.list_orders_param.order_type:type_name -> pb.ORDER_TYPE
+	0,  // 13: pb.list_orders_param.status:type_name -> pb.ORDER_STATUS
+	1,  // 14: pb.order_list_item.order_type:type_name -> pb.ORDER_TYPE
+	0,  // 15: pb.order_list_item.status:type_name -> pb.ORDER_STATUS
+	27, // 16: pb.list_orders_resp.orders:type_name -> pb.order_list_item
+	3,  // 17: pb.pay_service.virtual_account:input_type -> pb.pay_rpc_param
+	3,  // 18: pb.pay_service.receive:input_type -> pb.pay_rpc_param
+	3,  // 19: pb.pay_service.receive_query:input_type -> pb.pay_rpc_param
+	3,  // 20: pb.pay_service.out:input_type -> pb.pay_rpc_param
+	3,  // 21: pb.pay_service.out_query:input_type -> pb.pay_rpc_param
+	3,  // 22: pb.pay_service.channel_query:input_type -> pb.pay_rpc_param
+	3,  // 23: pb.pay_service.merchant_balance:input_type -> pb.pay_rpc_param
+	3,  // 24: pb.pay_service.watch_orders:input_type -> pb.pay_rpc_param
+	3,  // 25: pb.pay_service.batch_query:input_type -> pb.pay_rpc_param
+	3,  // 26: pb.pay_service.list_orders:input_type -> pb.pay_rpc_param
+	3,  // 27: pb.pay_service.refund:input_type -> pb.pay_rpc_param
+	3,  // 28: pb.pay_service.cancel:input_type -> pb.pay_rpc_param
+	2,  // 29: pb.pay_service.virtual_account:output_type -> pb.pay_rpc_resp
+	2,  // 30: pb.pay_service.receive:output_type -> pb.pay_rpc_resp
+	2,  // 31: pb.pay_service.receive_query:output_type -> pb.pay_rpc_resp
+	2,  // 32: pb.pay_service.out:output_type -> pb.pay_rpc_resp
+	2,  // 33: pb.pay_service.out_query:output_type -> pb.pay_rpc_resp
+	2,  // 34: pb.pay_service.channel_query:output_type -> pb.pay_rpc_resp
+	2,  // 35: pb.pay_service.merchant_balance:output_type -> pb.pay_rpc_resp
+	2,  // 36: pb.pay_service.watch_orders:output_type -> pb.pay_rpc_resp
+	2,  // 37: pb.pay_service.batch_query:output_type -> pb.pay_rpc_resp
+	2,  // 38: pb.pay_service.list_orders:output_type -> pb.pay_rpc_resp
+	2,  // 39: pb.pay_service.refund:output_type -> pb.pay_rpc_resp
+	2,  // 40: pb.pay_service.cancel:output_type -> pb.pay_rpc_resp
+	29, // [29:41] is the sub-list for method output_type
+	17, // [17:29] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_pay_client_proto_init() }
@@ -1596,7 +2643,7 @@ func file_pay_client_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pay_client_proto_rawDesc), len(file_pay_client_proto_rawDesc)),
 			NumEnums:      2,
-			NumMessages:   15,
+			NumMessages:   27,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -26,6 +26,11 @@ const (
 	PayService_OutQuery_FullMethodName        = "/pb.pay_service/out_query"
 	PayService_ChannelQuery_FullMethodName    = "/pb.pay_service/channel_query"
 	PayService_MerchantBalance_FullMethodName = "/pb.pay_service/merchant_balance"
+	PayService_WatchOrders_FullMethodName     = "/pb.pay_service/watch_orders"
+	PayService_BatchQuery_FullMethodName      = "/pb.pay_service/batch_query"
+	PayService_ListOrders_FullMethodName      = "/pb.pay_service/list_orders"
+	PayService_Refund_FullMethodName          = "/pb.pay_service/refund"
+	PayService_Cancel_FullMethodName          = "/pb.pay_service/cancel"
 )
 
 // PayServiceClient is the client API for PayService service.
@@ -46,6 +51,16 @@ type PayServiceClient interface {
 	ChannelQuery(ctx context.Context, in *PayRpcParam, opts ...grpc.CallOption) (*PayRpcResp, error)
 	// 账户余额查询
 	MerchantBalance(ctx context.Context, in *PayRpcParam, opts ...grpc.CallOption) (*PayRpcResp, error)
+	// 订单状态订阅，每条响应的 data 为加密的 order_status_event
+	WatchOrders(ctx context.Context, in *PayRpcParam, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PayRpcResp], error)
+	// 批量订单查询
+	BatchQuery(ctx context.Context, in *PayRpcParam, opts ...grpc.CallOption) (*PayRpcResp, error)
+	// 订单列表查询
+	ListOrders(ctx context.Context, in *PayRpcParam, opts ...grpc.CallOption) (*PayRpcResp, error)
+	// 收款单退款
+	Refund(ctx context.Context, in *PayRpcParam, opts ...grpc.CallOption) (*PayRpcResp, error)
+	// 取消待处理的付款单
+	Cancel(ctx context.Context, in *PayRpcParam, opts ...grpc.CallOption) (*PayRpcResp, error)
 }
 
 type payServiceClient struct {
@@ -126,6 +141,65 @@ func (c *payServiceClient) MerchantBalance(ctx context.Context, in *PayRpcParam,
 	return out, nil
 }
 
+func (c *payServiceClient) WatchOrders(ctx context.Context, in *PayRpcParam, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PayRpcResp], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PayService_ServiceDesc.Streams[0], PayService_WatchOrders_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PayRpcParam, PayRpcResp]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PayService_WatchOrdersClient = grpc.ServerStreamingClient[PayRpcResp]
+
+func (c *payServiceClient) BatchQuery(ctx context.Context, in *PayRpcParam, opts ...grpc.CallOption) (*PayRpcResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PayRpcResp)
+	err := c.cc.Invoke(ctx, PayService_BatchQuery_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *payServiceClient) ListOrders(ctx context.Context, in *PayRpcParam, opts ...grpc.CallOption) (*PayRpcResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PayRpcResp)
+	err := c.cc.Invoke(ctx, PayService_ListOrders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *payServiceClient) Refund(ctx context.Context, in *PayRpcParam, opts ...grpc.CallOption) (*PayRpcResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PayRpcResp)
+	err := c.cc.Invoke(ctx, PayService_Refund_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *payServiceClient) Cancel(ctx context.Context, in *PayRpcParam, opts ...grpc.CallOption) (*PayRpcResp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PayRpcResp)
+	err := c.cc.Invoke(ctx, PayService_Cancel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PayServiceServer is the server API for PayService service.
 // All implementations should embed UnimplementedPayServiceServer
 // for forward compatibility.
@@ -144,6 +218,16 @@ type PayServiceServer interface {
 	ChannelQuery(context.Context, *PayRpcParam) (*PayRpcResp, error)
 	// 账户余额查询
 	MerchantBalance(context.Context, *PayRpcParam) (*PayRpcResp, error)
+	// 订单状态订阅，每条响应的 data 为加密的 order_status_event
+	WatchOrders(*PayRpcParam, grpc.ServerStreamingServer[PayRpcResp]) error
+	// 批量订单查询
+	BatchQuery(context.Context, *PayRpcParam) (*PayRpcResp, error)
+	// 订单列表查询
+	ListOrders(context.Context, *PayRpcParam) (*PayRpcResp, error)
+	// 收款单退款
+	Refund(context.Context, *PayRpcParam) (*PayRpcResp, error)
+	// 取消待处理的付款单
+	Cancel(context.Context, *PayRpcParam) (*PayRpcResp, error)
 }
 
 // UnimplementedPayServiceServer should be embedded to have
@@ -174,6 +258,21 @@ func (UnimplementedPayServiceServer) ChannelQuery(context.Context, *PayRpcParam)
 func (UnimplementedPayServiceServer) MerchantBalance(context.Context, *PayRpcParam) (*PayRpcResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method MerchantBalance not implemented")
 }
+func (UnimplementedPayServiceServer) WatchOrders(*PayRpcParam, grpc.ServerStreamingServer[PayRpcResp]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchOrders not implemented")
+}
+func (UnimplementedPayServiceServer) BatchQuery(context.Context, *PayRpcParam) (*PayRpcResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchQuery not implemented")
+}
+func (UnimplementedPayServiceServer) ListOrders(context.Context, *PayRpcParam) (*PayRpcResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOrders not implemented")
+}
+func (UnimplementedPayServiceServer) Refund(context.Context, *PayRpcParam) (*PayRpcResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Refund not implemented")
+}
+func (UnimplementedPayServiceServer) Cancel(context.Context, *PayRpcParam) (*PayRpcResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Cancel not implemented")
+}
 func (UnimplementedPayServiceServer) testEmbeddedByValue() {}
 
 // UnsafePayServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -320,6 +419,89 @@ func _PayService_MerchantBalance_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PayService_WatchOrders_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PayRpcParam)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PayServiceServer).WatchOrders(m, &grpc.GenericServerStream[PayRpcParam, PayRpcResp]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PayService_WatchOrdersServer = grpc.ServerStreamingServer[PayRpcResp]
+
+func _PayService_BatchQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PayRpcParam)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PayServiceServer).BatchQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PayService_BatchQuery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PayServiceServer).BatchQuery(ctx, req.(*PayRpcParam))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PayService_ListOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PayRpcParam)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PayServiceServer).ListOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PayService_ListOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PayServiceServer).ListOrders(ctx, req.(*PayRpcParam))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PayService_Refund_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PayRpcParam)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PayServiceServer).Refund(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PayService_Refund_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PayServiceServer).Refund(ctx, req.(*PayRpcParam))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PayService_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PayRpcParam)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PayServiceServer).Cancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PayService_Cancel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PayServiceServer).Cancel(ctx, req.(*PayRpcParam))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PayService_ServiceDesc is the grpc.ServiceDesc for PayService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -355,7 +537,29 @@ var PayService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "merchant_balance",
 			Handler:    _PayService_MerchantBalance_Handler,
 		},
+		{
+			MethodName: "batch_query",
+			Handler:    _PayService_BatchQuery_Handler,
+		},
+		{
+			MethodName: "list_orders",
+			Handler:    _PayService_ListOrders_Handler,
+		},
+		{
+			MethodName: "refund",
+			Handler:    _PayService_Refund_Handler,
+		},
+		{
+			MethodName: "cancel",
+			Handler:    _PayService_Cancel_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "watch_orders",
+			Handler:       _PayService_WatchOrders_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "pay_client.proto",
 }
@@ -0,0 +1,222 @@
+package xmpay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"google.golang.org/grpc"
+)
+
+// route 网关接口，HTTP 使用 path 请求，gRPC 调用 rpc
+type route struct {
+	path string
+	rpc  func(pb.PayServiceClient, context.Context, *pb.PayRpcParam, ...grpc.CallOption) (*pb.PayRpcResp, error)
+}
+
+// operation 绑定了请求与响应类型的网关接口
+type operation[Req, Resp any] struct {
+	route
+}
+
+// 各网关接口，HttpClient 与 GrpcClient 共用
+var (
+	opVirtual      = operation[*pb.VirtualParam, *pb.VirtualResp]{route{CreateVirtual, pb.PayServiceClient.VirtualAccount}}
+	opReceive      = operation[*pb.ReceiveParam, *pb.ReceiveResp]{route{CreateReceive, pb.PayServiceClient.Receive}}
+	opQueryReceive = operation[*pb.OrderQueryParam, *pb.OrderQueryResp]{route{QueryReceive, pb.PayServiceClient.ReceiveQuery}}
+	opOut          = operation[*pb.OutParam, *pb.OutResp]{route{CreateOut, pb.PayServiceClient.Out}}
+	opQueryOut     = operation[*pb.OrderQueryParam, *pb.OrderQueryResp]{route{QueryOut, pb.PayServiceClient.OutQuery}}
+	opChannel      = operation[*pb.ChannelQueryParam, []*pb.ChannelQueryResp]{route{Channel, pb.PayServiceClient.ChannelQuery}}
+	opBalance      = operation[any, *pb.MerchantBalanceResp]{route{Balance, pb.PayServiceClient.MerchantBalance}}
+	opBatchQuery   = operation[*pb.BatchQueryParam, *pb.BatchQueryResp]{route{BatchQuery, pb.PayServiceClient.BatchQuery}}
+	opListOrders   = operation[*pb.ListOrdersParam, *pb.ListOrdersResp]{route{ListOrders, pb.PayServiceClient.ListOrders}}
+	opRefund       = operation[*pb.RefundParam, *pb.RefundResp]{route{Refund, pb.PayServiceClient.Refund}}
+	opCancel       = operation[*pb.CancelParam, *pb.CancelResp]{route{Cancel, pb.PayServiceClient.Cancel}}
+)
+
+// transport 加密请求参数并调用网关接口，返回解密后的响应数据
+type transport interface {
+	invoke(r route, param interface{}) ([]byte, error)
+}
+
+var (
+	_ transport = (*HttpClient)(nil)
+	_ transport = (*GrpcClient)(nil)
+)
+
+// call 通过 t 调用 op，请求参数由下方的 xxxRequest 构造，保证两种传输方式发送的字段一致
+func call[Req, Resp any](t transport, op operation[Req, Resp], req Req) (Resp, error) {
+	respData, err := t.invoke(op.route, req)
+	if err != nil {
+		var zero Resp
+		return zero, err
+	}
+	return unmarshal[Resp](respData)
+}
+
+// decode 校验网关响应码并解密响应数据，gRPC 普通调用与 watch_orders 流共用
+func (c *PayClientImpl) decode(resp *pb.PayRpcResp) ([]byte, error) {
+	if resp.Code != http.StatusOK {
+		return nil, apiError(resp.Code, resp.Message)
+	}
+	data, _, err := c.DecryptKey([]byte(resp.Data))
+	return data, err
+}
+
+func unmarshal[Resp any](respData []byte) (Resp, error) {
+	var data Resp
+	if err := json.Unmarshal(respData, &data); err != nil {
+		var zero Resp
+		return zero, err
+	}
+	return data, nil
+}
+
+func (c *PayClientImpl) virtualRequest(param *OrderParam) *pb.VirtualParam {
+	c.fillOrderNo(param)
+	req := &pb.VirtualParam{
+		OrderNo:   param.OrderNo,
+		Uid:       param.Uid,
+		Ip:        param.Ip,
+		Email:     param.Email,
+		Phone:     param.Phone,
+		Name:      param.Name,
+		IdNum:     param.IdNum,
+		Pid:       param.Pid,
+		NotifyUrl: param.NotifyUrl,
+	}
+	if param.NotifyUrl == "" {
+		req.NotifyUrl = c.config().InNotifyUrl
+	}
+	if param.Pid <= 0 {
+		req.Pid = StringToInt32(c.config().InId)
+	}
+	return req
+}
+
+func (c *PayClientImpl) receiveRequest(param *ReceiveParam) *pb.ReceiveParam {
+	c.fillOrderNo(&param.OrderParam)
+	req := &pb.ReceiveParam{
+		OrderNo:   param.OrderNo,
+		Amount:    param.Amount,
+		Uid:       param.Uid,
+		Ip:        param.Ip,
+		Email:     param.Email,
+		Phone:     param.Phone,
+		Name:      param.Name,
+		Subject:   param.Subject,
+		Body:      param.Body,
+		IdNum:     param.IdNum,
+		Pid:       param.Pid,
+		NotifyUrl: param.NotifyUrl,
+		ReturnUrl: param.ReturnUrl,
+	}
+	if param.NotifyUrl == "" {
+		req.NotifyUrl = c.config().InNotifyUrl
+	}
+	if param.Pid <= 0 {
+		req.Pid = StringToInt32(c.config().InId)
+	}
+	return req
+}
+
+func (c *PayClientImpl) outRequest(param *OutParam) *pb.OutParam {
+	c.fillOrderNo(&param.OrderParam)
+	req := &pb.OutParam{
+		OrderNo:   param.OrderNo,
+		Amount:    param.Amount,
+		Uid:       param.Uid,
+		Ip:        param.Ip,
+		Email:     param.Email,
+		Phone:     param.Phone,
+		Name:      param.Name,
+		IdNum:     param.IdNum,
+		Pid:       param.Pid,
+		BankNo:    param.BankNo,
+		BankCode:  param.BankCode,
+		BankName:  param.BankName,
+		Mode:      param.Mode,
+		NotifyUrl: param.NotifyUrl,
+		Subject:   param.Subject,
+		Body:      param.Body,
+	}
+	if param.NotifyUrl == "" {
+		req.NotifyUrl = c.config().OutNotifyUrl
+	}
+	if param.Pid <= 0 {
+		req.Pid = StringToInt32(c.config().OutId)
+	}
+	return req
+}
+
+func queryRequest(orderNo, trxNo string) *pb.OrderQueryParam {
+	return &pb.OrderQueryParam{
+		OrderNo:    trxNo,
+		MerchantNo: orderNo,
+	}
+}
+
+func (c *PayClientImpl) createVirtual(t transport, param *OrderParam) (*pb.VirtualResp, error) {
+	req := c.virtualRequest(param)
+	if req.Pid <= 0 {
+		return nil, ErrChannelId
+	}
+	data, err := call(t, opVirtual, req)
+	if err == nil && data != nil {
+		c.recordOrder(orderFromCreate(pb.ORDER_TYPE_VIRTUAL, param, req.Pid, data.OrderNo), OrderSourceCreate)
+	}
+	return data, err
+}
+
+func (c *PayClientImpl) createReceive(t transport, param *ReceiveParam) (data *pb.ReceiveResp, err error) {
+	// 订单号由业务键确定或自动生成，不参与幂等比较
+	content := *param
+	content.OrderNo, content.IdempotencyKey = "", ""
+	err = c.idempotent(CreateReceive, idempotencyKey(&param.OrderParam), &content, &data, func() (err error) {
+		req := c.receiveRequest(param)
+		if req.Pid <= 0 {
+			return ErrChannelId
+		}
+		if data, err = call(t, opReceive, req); err == nil && data != nil {
+			c.recordOrder(orderFromCreate(pb.ORDER_TYPE_RECEIVE, &param.OrderParam, req.Pid, data.OrderNo), OrderSourceCreate)
+		}
+		return
+	})
+	if err == nil && data != nil && param.OrderNo == "" {
+		param.OrderNo = data.MerchantNo
+	}
+	return
+}
+
+func (c *PayClientImpl) createOut(t transport, param *OutParam) (data *pb.OutResp, err error) {
+	// 订单号由业务键确定或自动生成，不参与幂等比较
+	content := *param
+	content.OrderNo, content.IdempotencyKey = "", ""
+	err = c.idempotent(CreateOut, idempotencyKey(&param.OrderParam), &content, &data, func() (err error) {
+		req := c.outRequest(param)
+		if req.Pid <= 0 {
+			return ErrChannelId
+		}
+		if data, err = call(t, opOut, req); err == nil && data != nil {
+			c.recordOrder(orderFromCreate(pb.ORDER_TYPE_OUT, &param.OrderParam, req.Pid, data.OrderNo), OrderSourceCreate)
+		}
+		return
+	})
+	if err == nil && data != nil && param.OrderNo == "" {
+		param.OrderNo = data.MerchantNo
+	}
+	return
+}
+
+func (c *PayClientImpl) query(t transport, orderType pb.ORDER_TYPE, orderNo, trxNo string) (*pb.OrderQueryResp, error) {
+	op := opQueryReceive
+	if orderType == pb.ORDER_TYPE_OUT {
+		op = opQueryOut
+	}
+	data, err := call(t, op, queryRequest(orderNo, trxNo))
+	if err == nil {
+		c.recordOrder(orderFromQuery(c.times, orderType, data), OrderSourceQuery)
+	}
+	return data, err
+}
@@ -0,0 +1,340 @@
+package xmpay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+const (
+	testAccessId  = "0123456789abcdef"
+	testAccessKey = "fedcba9876543210"
+)
+
+func testLogger() *logrus.Entry {
+	l := logrus.New()
+	l.Out = io.Discard
+	return logrus.NewEntry(l)
+}
+
+func testConfig(apiUrl string) *Config {
+	return &Config{
+		ApiUrl:       apiUrl,
+		AccessId:     testAccessId,
+		AccessKey:    testAccessKey,
+		InId:         "7",
+		OutId:        "8",
+		InNotifyUrl:  "http://merchant/in",
+		OutNotifyUrl: "http://merchant/out",
+	}
+}
+
+// captured 网关收到的请求，key 为 HTTP 路径或 gRPC 方法名
+type captured struct {
+	mu     sync.Mutex
+	params map[string]*pb.PayRpcParam
+}
+
+func (c *captured) put(key string, param *pb.PayRpcParam) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.params == nil {
+		c.params = make(map[string]*pb.PayRpcParam)
+	}
+	c.params[key] = param
+}
+
+func (c *captured) get(key string) *pb.PayRpcParam {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.params[key]
+}
+
+// testResponse 按接口返回可解码的空响应，渠道查询返回数组
+func testResponse(aes *AES, array bool) *pb.PayRpcResp {
+	data := "{}"
+	if array {
+		data = "[]"
+	}
+	encrypted, _ := aes.Encrypt([]byte(data))
+	return &pb.PayRpcResp{Code: http.StatusOK, Data: encrypted}
+}
+
+// recordingPayService 记录请求参数的 gRPC 客户端桩
+type recordingPayService struct {
+	pb.PayServiceClient
+	got *captured
+	aes *AES
+}
+
+func (s *recordingPayService) reply(method string, in *pb.PayRpcParam) (*pb.PayRpcResp, error) {
+	s.got.put(method, in)
+	return testResponse(s.aes, method == "ChannelQuery"), nil
+}
+
+func (s *recordingPayService) VirtualAccount(_ context.Context, in *pb.PayRpcParam, _ ...grpc.CallOption) (*pb.PayRpcResp, error) {
+	return s.reply("VirtualAccount", in)
+}
+
+func (s *recordingPayService) Receive(_ context.Context, in *pb.PayRpcParam, _ ...grpc.CallOption) (*pb.PayRpcResp, error) {
+	return s.reply("Receive", in)
+}
+
+func (s *recordingPayService) ReceiveQuery(_ context.Context, in *pb.PayRpcParam, _ ...grpc.CallOption) (*pb.PayRpcResp, error) {
+	return s.reply("ReceiveQuery", in)
+}
+
+func (s *recordingPayService) Out(_ context.Context, in *pb.PayRpcParam, _ ...grpc.CallOption) (*pb.PayRpcResp, error) {
+	return s.reply("Out", in)
+}
+
+func (s *recordingPayService) OutQuery(_ context.Context, in *pb.PayRpcParam, _ ...grpc.CallOption) (*pb.PayRpcResp, error) {
+	return s.reply("OutQuery", in)
+}
+
+func (s *recordingPayService) ChannelQuery(_ context.Context, in *pb.PayRpcParam, _ ...grpc.CallOption) (*pb.PayRpcResp, error) {
+	return s.reply("ChannelQuery", in)
+}
+
+func (s *recordingPayService) MerchantBalance(_ context.Context, in *pb.PayRpcParam, _ ...grpc.CallOption) (*pb.PayRpcResp, error) {
+	return s.reply("MerchantBalance", in)
+}
+
+func (s *recordingPayService) BatchQuery(_ context.Context, in *pb.PayRpcParam, _ ...grpc.CallOption) (*pb.PayRpcResp, error) {
+	return s.reply("BatchQuery", in)
+}
+
+func (s *recordingPayService) ListOrders(_ context.Context, in *pb.PayRpcParam, _ ...grpc.CallOption) (*pb.PayRpcResp, error) {
+	return s.reply("ListOrders", in)
+}
+
+func (s *recordingPayService) Refund(_ context.Context, in *pb.PayRpcParam, _ ...grpc.CallOption) (*pb.PayRpcResp, error) {
+	return s.reply("Refund", in)
+}
+
+func (s *recordingPayService) Cancel(_ context.Context, in *pb.PayRpcParam, _ ...grpc.CallOption) (*pb.PayRpcResp, error) {
+	return s.reply("Cancel", in)
+}
+
+// newTestClients 创建连接到同一记录桩的 HTTP 与 gRPC 客户端
+func newTestClients(t *testing.T, got *captured) (*HttpClient, *GrpcClient) {
+	t.Helper()
+	aes := NewAES([]byte(testAccessId), []byte(testAccessKey))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var param pb.PayRpcParam
+		if err := json.NewDecoder(r.Body).Decode(&param); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		got.put(r.URL.Path, &param)
+		_ = json.NewEncoder(w).Encode(testResponse(aes, r.URL.Path == Channel))
+	}))
+	t.Cleanup(server.Close)
+
+	hc := NewHttpClient(testConfig(server.URL), testLogger())
+	gc, err := NewGrpcClient(testConfig("127.0.0.1:1"), testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = gc.Close() })
+	gc.client = &recordingPayService{got: got, aes: aes}
+	return hc, gc
+}
+
+// parityClient HttpClient 与 GrpcClient 共有的方法
+type parityClient interface {
+	PayClient
+	BatchQuerier
+	OrderLister
+	RefundClient
+}
+
+func orderParam() OrderParam {
+	return OrderParam{
+		OrderNo:   "M1001",
+		Ip:        "10.0.0.1",
+		Uid:       "u1",
+		Name:      "张三",
+		Phone:     "13800138000",
+		Email:     "a@example.com",
+		IdNum:     "110101199003076598",
+		Pid:       3,
+		NotifyUrl: "http://merchant/notify",
+		Amount:    10000,
+		Subject:   "subject",
+		Body:      "body",
+	}
+}
+
+func TestRequestParity(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		method string
+		call   func(c parityClient) error
+	}{
+		{"virtual", CreateVirtual, "VirtualAccount", func(c parityClient) error {
+			param := orderParam()
+			_, err := c.CreateVirtual(&param)
+			return err
+		}},
+		{"receive", CreateReceive, "Receive", func(c parityClient) error {
+			_, err := c.CreateReceive(&ReceiveParam{OrderParam: orderParam(), ReturnUrl: "http://merchant/return"})
+			return err
+		}},
+		{"receive default notify", CreateReceive, "Receive", func(c parityClient) error {
+			param := orderParam()
+			param.Pid = 0
+			param.NotifyUrl = ""
+			_, err := c.CreateReceive(&ReceiveParam{OrderParam: param})
+			return err
+		}},
+		{"query receive", QueryReceive, "ReceiveQuery", func(c parityClient) error {
+			_, err := c.QueryReceive("M1001", "T1001")
+			return err
+		}},
+		{"out", CreateOut, "Out", func(c parityClient) error {
+			_, err := c.CreateOut(&OutParam{OrderParam: orderParam(), BankNo: "6222", BankCode: "ICBC", BankName: "工商银行", Mode: "bank"})
+			return err
+		}},
+		{"query out", QueryOut, "OutQuery", func(c parityClient) error {
+			_, err := c.QueryOut("M1002", "")
+			return err
+		}},
+		{"channel", Channel, "ChannelQuery", func(c parityClient) error {
+			_, err := c.Channel(pb.ORDER_TYPE_OUT)
+			return err
+		}},
+		{"balance", Balance, "MerchantBalance", func(c parityClient) error {
+			_, err := c.Balance()
+			return err
+		}},
+		{"batch query", BatchQuery, "BatchQuery", func(c parityClient) error {
+			_, err := c.BatchQuery(pb.ORDER_TYPE_RECEIVE, []string{"M1", "M2"})
+			return err
+		}},
+		{"list orders", ListOrders, "ListOrders", func(c parityClient) error {
+			_, err := c.ListOrders(&ListParam{OrderType: pb.ORDER_TYPE_OUT, Status: []pb.ORDER_STATUS{pb.ORDER_STATUS_SUCCESS}, Pid: 3, Cursor: "c1", Limit: 20})
+			return err
+		}},
+		{"refund", Refund, "Refund", func(c parityClient) error {
+			_, err := c.Refund(&RefundParam{MerchantNo: "M1001", RefundNo: "RF1", Amount: 100, Reason: "reason"})
+			return err
+		}},
+		{"cancel", Cancel, "Cancel", func(c parityClient) error {
+			_, err := c.Cancel(&CancelParam{MerchantNo: "M1002", Reason: "reason"})
+			return err
+		}},
+	}
+
+	aes := NewAES([]byte(testAccessId), []byte(testAccessKey))
+	decode := func(t *testing.T, param *pb.PayRpcParam) map[string]interface{} {
+		t.Helper()
+		if param.AppKey != testAccessId {
+			t.Fatalf("app key = %q, want %q", param.AppKey, testAccessId)
+		}
+		payload := map[string]interface{}{}
+		if param.Data == "" {
+			return payload
+		}
+		data, err := aes.Decrypt([]byte(param.Data))
+		if err != nil {
+			t.Fatalf("decrypt: %v", err)
+		}
+		if err = json.Unmarshal(data, &payload); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		return payload
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &captured{}
+			hc, gc := newTestClients(t, got)
+			if err := tt.call(hc); err != nil {
+				t.Fatalf("http: %v", err)
+			}
+			if err := tt.call(gc); err != nil {
+				t.Fatalf("grpc: %v", err)
+			}
+			httpParam, grpcParam := got.get(tt.path), got.get(tt.method)
+			if httpParam == nil || grpcParam == nil {
+				t.Fatalf("request not captured, http: %v, grpc: %v", httpParam != nil, grpcParam != nil)
+			}
+			httpPayload, grpcPayload := decode(t, httpParam), decode(t, grpcParam)
+			if !reflect.DeepEqual(httpPayload, grpcPayload) {
+				t.Errorf("payload mismatch\nhttp: %v\ngrpc: %v", httpPayload, grpcPayload)
+			}
+		})
+	}
+}
+
+func TestRequestDefaults(t *testing.T) {
+	got := &captured{}
+	hc, _ := newTestClients(t, got)
+	param := orderParam()
+	param.Pid = 0
+	param.NotifyUrl = ""
+	if _, err := hc.CreateReceive(&ReceiveParam{OrderParam: param, ReturnUrl: "http://merchant/return"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _, err := hc.DecryptKey([]byte(got.get(CreateReceive).Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req pb.ReceiveParam
+	if err = json.Unmarshal(data, &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.ReturnUrl != "http://merchant/return" {
+		t.Errorf("return_url = %q", req.ReturnUrl)
+	}
+	if req.Pid != 7 || req.NotifyUrl != "http://merchant/in" {
+		t.Errorf("defaults not applied, pid: %d, notify_url: %q", req.Pid, req.NotifyUrl)
+	}
+}
+
+func TestInvokeDecryptError(t *testing.T) {
+	other := NewAES([]byte("ffffffffffffffff"), []byte("0000000000000000"))
+	encrypted, _ := other.Encrypt([]byte(`{"order_no":"T1"}`))
+	resp := &pb.PayRpcResp{Code: http.StatusOK, Data: encrypted}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+	hc := NewHttpClient(testConfig(server.URL), testLogger())
+	if _, err := hc.QueryReceive("M1", ""); !errors.Is(err, ErrDecryptKey) {
+		t.Errorf("http err = %v, want ErrDecryptKey", err)
+	}
+
+	gc, err := NewGrpcClient(testConfig("127.0.0.1:1"), testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gc.Close()
+	gc.client = &stubPayService{resp: resp}
+	if _, err = gc.QueryReceive("M1", ""); !errors.Is(err, ErrDecryptKey) {
+		t.Errorf("grpc err = %v, want ErrDecryptKey", err)
+	}
+}
+
+// stubPayService 对订单查询返回固定响应的 gRPC 客户端桩
+type stubPayService struct {
+	pb.PayServiceClient
+	resp *pb.PayRpcResp
+}
+
+func (s *stubPayService) ReceiveQuery(context.Context, *pb.PayRpcParam, ...grpc.CallOption) (*pb.PayRpcResp, error) {
+	return s.resp, nil
+}
@@ -0,0 +1,343 @@
+package xmpay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ErrRateLimited 请求超出限流配额
+var ErrRateLimited = errors.New("rate limited")
+
+// RateLimitError 限流错误，errors.Is(err, ErrRateLimited) 为 true
+type RateLimitError struct {
+	Operation  string
+	RetryAfter time.Duration // 建议的重试等待时间
+	Remote     bool          // 是否为网关返回的限流（HTTP 429、Retry-After 或 gRPC ResourceExhausted）
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Remote {
+		return fmt.Sprintf("rate limited by gateway: %s, retry after %s", e.Operation, e.RetryAfter)
+	}
+	return fmt.Sprintf("rate limited: %s, retry after %s", e.Operation, e.RetryAfter)
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// RateLimitMode 超出配额时的处理方式
+type RateLimitMode int
+
+const (
+	RateLimitWait     RateLimitMode = iota // 阻塞等待令牌，等待时间超过 MaxWait 时返回 RateLimitError
+	RateLimitFailFast                      // 立即返回 RateLimitError
+)
+
+// RateLimit 令牌桶配置，Rate 为每秒生成的令牌数，Burst 为桶容量
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitStats 限流统计
+type RateLimitStats struct {
+	Allowed   int64         // 放行的请求数
+	Rejected  int64         // 被拒绝的请求数
+	Waited    int64         // 等待后放行的请求数
+	WaitTime  time.Duration // 累计等待时间
+	Throttled int64         // 网关返回限流的次数
+}
+
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+	// pausedUntil 网关要求暂停到该时间
+	pausedUntil time.Time
+	stats       RateLimitStats
+}
+
+// reserve 预留一个令牌，返回需要等待的时间
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if b.limit.Rate > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate)
+	}
+	b.last = now
+
+	var wait time.Duration
+	if now.Before(b.pausedUntil) {
+		wait = b.pausedUntil.Sub(now)
+	}
+	if b.limit.Rate <= 0 {
+		return wait
+	}
+	if b.tokens < 1 {
+		if d := time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second)); d > wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+// RateLimiter 客户端令牌桶限流，按商户（AccessId）和网关操作分别计数。
+// 操作令牌桶的配额优先级：商户+操作 > 操作 > 默认，未配置的操作不限流。
+// 商户级配额（SetMerchantLimit 的 operation 为空）由该商户的所有操作共用一个令牌桶，与操作令牌桶同时生效
+type RateLimiter struct {
+	Mode    RateLimitMode
+	MaxWait time.Duration // 阻塞模式的最长等待时间，0 表示不限制
+
+	mu       sync.Mutex
+	limits   map[string]RateLimit
+	buckets  map[string]*tokenBucket
+	throttle time.Duration
+}
+
+// NewRateLimiter 创建限流器
+func NewRateLimiter(mode RateLimitMode) *RateLimiter {
+	return &RateLimiter{
+		Mode:     mode,
+		limits:   make(map[string]RateLimit),
+		buckets:  make(map[string]*tokenBucket),
+		throttle: time.Second,
+	}
+}
+
+// SetDefault 设置所有操作的默认配额
+func (l *RateLimiter) SetDefault(rate float64, burst int) *RateLimiter {
+	return l.SetMerchantLimit("", "", rate, burst)
+}
+
+// SetLimit 设置网关操作的配额，operation 为 CreateReceive、CreateOut、QueryReceive 等
+func (l *RateLimiter) SetLimit(operation string, rate float64, burst int) *RateLimiter {
+	return l.SetMerchantLimit("", operation, rate, burst)
+}
+
+// SetMerchantLimit 设置商户的配额，operation 为空时为商户级配额，该商户的所有操作共用。
+// 只更新配额受影响的令牌桶，其余令牌桶的令牌、统计和网关限流暂停保持不变
+func (l *RateLimiter) SetMerchantLimit(accessId, operation string, rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits[accessId+"|"+operation] = RateLimit{Rate: rate, Burst: burst}
+	for key, b := range l.buckets {
+		id, op, _ := strings.Cut(key, "|")
+		if limit := l.resolve(id, op); limit != b.limit {
+			b.limit = limit
+			b.tokens = math.Min(b.tokens, float64(limit.Burst))
+		}
+	}
+	return l
+}
+
+// Metrics 返回各令牌桶的统计，key 为 AccessId|操作，商户级令牌桶的操作为空
+func (l *RateLimiter) Metrics() map[string]RateLimitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m := make(map[string]RateLimitStats, len(l.buckets))
+	for key, b := range l.buckets {
+		m[key] = b.stats
+	}
+	return m
+}
+
+// bucket 返回商户操作的令牌桶，未配置配额时令牌桶不限流，仅用于统计和网关限流暂停
+func (l *RateLimiter) bucket(accessId, operation string) *tokenBucket {
+	key := accessId + "|" + operation
+	if b, ok := l.buckets[key]; ok {
+		return b
+	}
+	limit := l.resolve(accessId, operation)
+	b := &tokenBucket{limit: limit, tokens: float64(limit.Burst), last: time.Now()}
+	l.buckets[key] = b
+	return b
+}
+
+// resolve 查找令牌桶的配额，operation 为空时为商户级配额，
+// 否则按 商户+操作 > 操作 > 默认 的顺序查找
+func (l *RateLimiter) resolve(accessId, operation string) RateLimit {
+	if operation == "" {
+		return l.limits[accessId+"|"]
+	}
+	for _, k := range []string{accessId + "|" + operation, "|" + operation, "|"} {
+		if limit, ok := l.limits[k]; ok {
+			return limit
+		}
+	}
+	return RateLimit{}
+}
+
+// Acquire 获取一个令牌，配置了商户级配额时同时占用商户令牌桶。
+// 阻塞模式下等待直到 ctx 结束，快速失败模式或等待超过 MaxWait 时返回 RateLimitError
+func (l *RateLimiter) Acquire(ctx context.Context, accessId, operation string) error {
+	l.mu.Lock()
+	buckets := []*tokenBucket{l.bucket(accessId, operation)}
+	if _, ok := l.limits[accessId+"|"]; ok && accessId != "" {
+		buckets = append(buckets, l.bucket(accessId, ""))
+	}
+	now := time.Now()
+	var wait time.Duration
+	for _, b := range buckets {
+		if d := b.reserve(now); d > wait {
+			wait = d
+		}
+	}
+	b := buckets[0]
+	if wait > 0 && (l.Mode == RateLimitFailFast || (l.MaxWait > 0 && wait > l.MaxWait)) {
+		b.stats.Rejected++
+		l.mu.Unlock()
+		return &RateLimitError{Operation: operation, RetryAfter: wait}
+	}
+	for _, b := range buckets {
+		b.take(wait)
+	}
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// 未使用的令牌归还令牌桶
+		l.mu.Lock()
+		for _, b := range buckets {
+			b.giveBack()
+		}
+		b.stats.Rejected++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// take 占用一个令牌并记录放行
+func (b *tokenBucket) take(wait time.Duration) {
+	if b.limit.Rate > 0 {
+		b.tokens--
+	}
+	b.stats.Allowed++
+	if wait > 0 {
+		b.stats.Waited++
+		b.stats.WaitTime += wait
+	}
+}
+
+// giveBack 归还取消等待的令牌
+func (b *tokenBucket) giveBack() {
+	if b.limit.Rate > 0 {
+		b.tokens++
+	}
+	b.stats.Allowed--
+}
+
+// Throttle 网关返回限流时暂停商户操作的请求，retryAfter 为 0 时暂停 1 秒
+func (l *RateLimiter) Throttle(accessId, operation string, retryAfter time.Duration) {
+	if retryAfter <= 0 {
+		retryAfter = l.throttle
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucket(accessId, operation)
+	if until := time.Now().Add(retryAfter); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+	b.stats.Throttled++
+}
+
+// parseRetryAfter 解析 Retry-After，支持秒数和 HTTP 日期
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// context 返回 WithContext 设置的上下文，未设置时为 context.Background()
+func (c *PayClientImpl) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// limit 获取限流令牌，未设置限流器时直接放行，ctx 结束时停止等待
+func (c *PayClientImpl) limit(ctx context.Context, operation string) error {
+	if c.limiter == nil {
+		return nil
+	}
+	return c.limiter.Acquire(ctx, c.credentials().accessId, operation)
+}
+
+// throttled 记录网关返回的限流并返回 RateLimitError
+func (c *PayClientImpl) throttled(operation string, retryAfter time.Duration) error {
+	if c.limiter != nil {
+		c.limiter.Throttle(c.credentials().accessId, operation, retryAfter)
+	}
+	c.log.Warnf("rate limited by gateway: %s, retry after %s", operation, retryAfter)
+	return &RateLimitError{Operation: operation, RetryAfter: retryAfter, Remote: true}
+}
+
+// grpcOperations gRPC 方法对应的网关操作，与 HTTP 客户端共用限流配置
+var grpcOperations = map[string]string{
+	pb.PayService_VirtualAccount_FullMethodName:  CreateVirtual,
+	pb.PayService_Receive_FullMethodName:         CreateReceive,
+	pb.PayService_ReceiveQuery_FullMethodName:    QueryReceive,
+	pb.PayService_Out_FullMethodName:             CreateOut,
+	pb.PayService_OutQuery_FullMethodName:        QueryOut,
+	pb.PayService_ChannelQuery_FullMethodName:    Channel,
+	pb.PayService_MerchantBalance_FullMethodName: Balance,
+	pb.PayService_BatchQuery_FullMethodName:      BatchQuery,
+	pb.PayService_ListOrders_FullMethodName:      ListOrders,
+	pb.PayService_Refund_FullMethodName:          Refund,
+	pb.PayService_Cancel_FullMethodName:          Cancel,
+}
+
+// limitInterceptor gRPC 限流拦截器，服务端返回 ResourceExhausted 或 429 响应码时按 retry-after 元数据暂停
+func (c *PayClientImpl) limitInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	operation, ok := grpcOperations[method]
+	if !ok {
+		operation = method
+	}
+	if err := c.limit(ctx, operation); err != nil {
+		return err
+	}
+
+	var header metadata.MD
+	err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Header(&header))...)
+	if status.Code(err) == codes.ResourceExhausted {
+		var retryAfter time.Duration
+		if v := header.Get("retry-after"); len(v) > 0 {
+			retryAfter = parseRetryAfter(v[0])
+		}
+		return c.throttled(operation, retryAfter)
+	}
+	if resp, ok := reply.(*pb.PayRpcResp); ok && err == nil && resp.Code == http.StatusTooManyRequests {
+		return c.throttled(operation, 0)
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+package xmpay
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+func TestSetMerchantLimitKeepsOtherBuckets(t *testing.T) {
+	l := NewRateLimiter(RateLimitFailFast).SetLimit(CreateOut, 1, 1)
+	ctx := context.Background()
+	if err := l.Acquire(ctx, "a", CreateOut); err != nil {
+		t.Fatal(err)
+	}
+	l.Throttle("a", QueryOut, time.Minute)
+
+	// 为商户 b 单独配额不影响商户 a 已用的令牌和网关限流暂停
+	l.SetMerchantLimit("b", CreateOut, 10, 10)
+	if err := l.Acquire(ctx, "a", CreateOut); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("bucket a reset, err = %v", err)
+	}
+	if err := l.Acquire(ctx, "a", QueryOut); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("throttle pause lost, err = %v", err)
+	}
+	if stats := l.Metrics()["a|"+CreateOut]; stats.Allowed != 1 || stats.Rejected != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+	for i := 0; i < 10; i++ {
+		if err := l.Acquire(ctx, "b", CreateOut); err != nil {
+			t.Fatalf("b acquire %d: %v", i, err)
+		}
+	}
+}
+
+func TestSetMerchantLimitUpdatesResolvedBuckets(t *testing.T) {
+	l := NewRateLimiter(RateLimitFailFast).SetDefault(100, 5)
+	ctx := context.Background()
+	if err := l.Acquire(ctx, "a", CreateOut); err != nil {
+		t.Fatal(err)
+	}
+	// 商户级配额对该商户已有的令牌桶生效，令牌不超过新的容量
+	l.SetMerchantLimit("a", "", 0.001, 1)
+	if err := l.Acquire(ctx, "a", CreateOut); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Acquire(ctx, "a", CreateOut); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("merchant limit not applied, err = %v", err)
+	}
+}
+
+func TestAcquireContext(t *testing.T) {
+	l := NewRateLimiter(RateLimitWait).SetLimit(CreateOut, 0.1, 1)
+	if err := l.Acquire(context.Background(), "a", CreateOut); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := l.Acquire(ctx, "a", CreateOut); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waited %s after cancel", elapsed)
+	}
+	// 取消等待后归还令牌，下一次等待时间不因取消而延长
+	l.mu.Lock()
+	tokens := l.buckets["a|"+CreateOut].tokens
+	l.mu.Unlock()
+	if tokens < -0.5 {
+		t.Errorf("tokens = %f, token not returned", tokens)
+	}
+}
+
+func TestMerchantLimitShared(t *testing.T) {
+	// 商户级配额由所有操作共用，操作级配额不会放宽商户级配额
+	l := NewRateLimiter(RateLimitFailFast).SetLimit(QueryOut, 100, 100).SetMerchantLimit("a", "", 0.001, 2)
+	ctx := context.Background()
+	for _, op := range []string{CreateOut, QueryOut} {
+		if err := l.Acquire(ctx, "a", op); err != nil {
+			t.Fatalf("%s: %v", op, err)
+		}
+	}
+	for _, op := range []string{CreateReceive, QueryOut} {
+		if err := l.Acquire(ctx, "a", op); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("%s: err = %v, want rate limited", op, err)
+		}
+	}
+	// 其他商户不受影响
+	if err := l.Acquire(ctx, "b", QueryOut); err != nil {
+		t.Errorf("b: %v", err)
+	}
+	if stats := l.Metrics()["a|"]; stats.Allowed != 2 {
+		t.Errorf("merchant stats = %+v", stats)
+	}
+}
+
+func TestHttpLimitContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var requests int
+	limiter := NewRateLimiter(RateLimitWait).SetLimit(CreateOut, 0.001, 1)
+	c := newReplyClient(t, &pb.OutResp{OrderNo: "T1"}, &requests, WithRateLimiter(limiter), WithContext(ctx))
+
+	if _, err := c.CreateOut(&OutParam{OrderParam: OrderParam{OrderNo: "M1", Amount: 100}}); err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := c.CreateOut(&OutParam{OrderParam: OrderParam{OrderNo: "M2", Amount: 100}})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second || requests != 1 {
+		t.Errorf("elapsed = %s, requests = %d", elapsed, requests)
+	}
+}
@@ -0,0 +1,172 @@
+package xmpay
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+// 退款与取消付款单的 HTTP 路径，同时作为限流和幂等的操作名
+const (
+	Refund = "/gateway/api/order/refund"
+	Cancel = "/gateway/api/order/out/cancel"
+)
+
+// ErrRefundOrderNo 退款和取消付款单须指定订单号或商户订单号
+var ErrRefundOrderNo = errors.New("order no or merchant no required")
+
+// RefundParam 退款参数，OrderNo 与 MerchantNo 至少填写一个
+type RefundParam struct {
+	OrderNo    string `json:"orderNo" comment:"原收款单订单号"`
+	MerchantNo string `json:"merchantNo" comment:"原收款单商户订单号"`
+	RefundNo   string `json:"refundNo" comment:"商户退款单号，为空时由订单号生成器生成"`
+	Amount     int64  `json:"amount" validate:"required" comment:"退款金额（分）"`
+	Reason     string `json:"reason" comment:"退款原因"`
+	NotifyUrl  string `json:"notifyUrl" comment:"退款回调地址，为空时使用收款回调地址"`
+
+	IdempotencyKey string `json:"-" comment:"幂等业务键，为空时使用退款单号，退款单号也为空时使用原订单号与退款金额"`
+}
+
+// CancelParam 取消付款单参数，只能取消 WAIT 状态的付款单
+type CancelParam struct {
+	OrderNo    string `json:"orderNo" comment:"订单号"`
+	MerchantNo string `json:"merchantNo" comment:"商户订单号"`
+	Reason     string `json:"reason" comment:"取消原因"`
+
+	IdempotencyKey string `json:"-" comment:"幂等业务键，为空时使用商户订单号，商户订单号也为空时使用订单号"`
+}
+
+// RefundClient 收款单退款与取消付款单，HttpClient 与 GrpcClient 均已实现
+type RefundClient interface {
+	Refund(param *RefundParam) (*pb.RefundResp, error)
+	Cancel(param *CancelParam) (*pb.CancelResp, error)
+}
+
+var (
+	_ RefundClient = (*HttpClient)(nil)
+	_ RefundClient = (*GrpcClient)(nil)
+)
+
+// refundRequest 构造退款请求，退款单号为空时生成，不修改 param
+func (c *PayClientImpl) refundRequest(param *RefundParam) *pb.RefundParam {
+	req := &pb.RefundParam{
+		OrderNo:    param.OrderNo,
+		MerchantNo: param.MerchantNo,
+		RefundNo:   param.RefundNo,
+		Amount:     param.Amount,
+		Reason:     param.Reason,
+		NotifyUrl:  param.NotifyUrl,
+	}
+	if req.RefundNo == "" && c.orderNo != nil {
+		req.RefundNo = c.orderNo.Next()
+	}
+	if param.NotifyUrl == "" {
+		req.NotifyUrl = c.config().InNotifyUrl
+	}
+	return req
+}
+
+func (p *CancelParam) request() *pb.CancelParam {
+	return &pb.CancelParam{
+		OrderNo:    p.OrderNo,
+		MerchantNo: p.MerchantNo,
+		Reason:     p.Reason,
+	}
+}
+
+// refundKey 退款的业务键，未指定退款单号时由原订单与退款金额确定，
+// 生成的退款单号每次不同，不能作为重试的业务键
+func refundKey(param *RefundParam) string {
+	if param.IdempotencyKey != "" {
+		return param.IdempotencyKey
+	}
+	if param.RefundNo != "" {
+		return param.RefundNo
+	}
+	return fmt.Sprintf("%s|%s|%d", param.OrderNo, param.MerchantNo, param.Amount)
+}
+
+func cancelKey(param *CancelParam) string {
+	if param.IdempotencyKey != "" {
+		return param.IdempotencyKey
+	}
+	if param.MerchantNo != "" {
+		return param.MerchantNo
+	}
+	return param.OrderNo
+}
+
+func orderFromRefund(resp *pb.RefundResp) *OrderRecord {
+	if resp == nil {
+		return nil
+	}
+	return &OrderRecord{
+		MerchantNo: resp.RefundNo,
+		OrderNo:    resp.OrderNo,
+		Type:       pb.ORDER_TYPE_REFUND,
+		Amount:     resp.Amount,
+		Status:     resp.Status,
+	}
+}
+
+func orderFromCancel(resp *pb.CancelResp) *OrderRecord {
+	if resp == nil {
+		return nil
+	}
+	return &OrderRecord{
+		MerchantNo: resp.MerchantNo,
+		OrderNo:    resp.OrderNo,
+		Type:       pb.ORDER_TYPE_OUT,
+		Status:     resp.Status,
+	}
+}
+
+// Refund 对成功的收款单发起退款，退款结果通过 refund_no 非空的回调通知
+func (c *GrpcClient) Refund(param *RefundParam) (*pb.RefundResp, error) {
+	return c.refund(c, param)
+}
+
+// Cancel 取消 WAIT 状态的付款单
+func (c *GrpcClient) Cancel(param *CancelParam) (*pb.CancelResp, error) {
+	return c.cancel(c, param)
+}
+
+// Refund 对成功的收款单发起退款，退款结果通过 refund_no 非空的回调通知
+func (c *HttpClient) Refund(param *RefundParam) (*pb.RefundResp, error) {
+	return c.refund(c, param)
+}
+
+// Cancel 取消 WAIT 状态的付款单
+func (c *HttpClient) Cancel(param *CancelParam) (*pb.CancelResp, error) {
+	return c.cancel(c, param)
+}
+
+func (c *PayClientImpl) refund(t transport, param *RefundParam) (data *pb.RefundResp, err error) {
+	if param.OrderNo == "" && param.MerchantNo == "" {
+		return nil, ErrRefundOrderNo
+	}
+	req := c.refundRequest(param)
+	content := *param
+	content.RefundNo, content.IdempotencyKey = "", ""
+	err = c.idempotent(Refund, refundKey(param), &content, &data, func() (err error) {
+		if data, err = call(t, opRefund, req); err == nil {
+			c.recordOrder(orderFromRefund(data), OrderSourceCreate)
+		}
+		return
+	})
+	return
+}
+
+func (c *PayClientImpl) cancel(t transport, param *CancelParam) (data *pb.CancelResp, err error) {
+	if param.OrderNo == "" && param.MerchantNo == "" {
+		return nil, ErrRefundOrderNo
+	}
+	err = c.idempotent(Cancel, cancelKey(param), param.request(), &data, func() (err error) {
+		if data, err = call(t, opCancel, param.request()); err == nil {
+			c.recordOrder(orderFromCancel(data), OrderSourceCancel)
+		}
+		return
+	})
+	return
+}
@@ -0,0 +1,105 @@
+package xmpay
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+// newReplyClient 返回网关以 resp 应答所有请求的 HttpClient，requests 记录收到的请求数
+func newReplyClient(t *testing.T, resp interface{}, requests *int, opts ...Option) *HttpClient {
+	t.Helper()
+	aes := NewAES([]byte(testAccessId), []byte(testAccessKey))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		data, _ := json.Marshal(resp)
+		encrypted, _ := aes.Encrypt(data)
+		_ = json.NewEncoder(w).Encode(&pb.PayRpcResp{Code: http.StatusOK, Data: encrypted})
+	}))
+	t.Cleanup(server.Close)
+	return NewHttpClient(testConfig(server.URL), testLogger(), opts...)
+}
+
+func TestRefundRequiresOrderNo(t *testing.T) {
+	var requests int
+	c := newReplyClient(t, &pb.RefundResp{}, &requests)
+	if _, err := c.Refund(&RefundParam{Amount: 100}); !errors.Is(err, ErrRefundOrderNo) {
+		t.Errorf("refund: err = %v", err)
+	}
+	if _, err := c.Cancel(&CancelParam{Reason: "reason"}); !errors.Is(err, ErrRefundOrderNo) {
+		t.Errorf("cancel: err = %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("%d requests sent without order no", requests)
+	}
+}
+
+func TestRefundKeepsParam(t *testing.T) {
+	gen, err := NewOrderNoGenerator("RF", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests int
+	c := newReplyClient(t, &pb.RefundResp{RefundNo: "RF1", Status: pb.ORDER_STATUS_REFUNDING}, &requests,
+		WithOrderNoGenerator(gen), WithIdempotencyStore(NewMemoryIdempotencyStore(0)))
+
+	param := &RefundParam{MerchantNo: "M1", Amount: 100}
+	first, err := c.Refund(param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if param.RefundNo != "" || param.NotifyUrl != "" {
+		t.Errorf("param modified: %+v", param)
+	}
+	// 未指定退款单号时按原订单与金额去重，重试返回首次的退款单
+	again, err := c.Refund(param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 || again.RefundNo != first.RefundNo {
+		t.Errorf("requests = %d, refund no = %s, want 1, %s", requests, again.RefundNo, first.RefundNo)
+	}
+	// 同一订单的另一笔退款须指定退款单号或业务键
+	if _, err = c.Refund(&RefundParam{MerchantNo: "M1", Amount: 100, IdempotencyKey: "second"}); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+}
+
+func TestCancelByOrderNoIdempotent(t *testing.T) {
+	var requests int
+	c := newReplyClient(t, &pb.CancelResp{OrderNo: "T1", Status: pb.ORDER_STATUS_CANCELED}, &requests,
+		WithIdempotencyStore(NewMemoryIdempotencyStore(0)))
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Cancel(&CancelParam{OrderNo: "T1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
+
+func TestCancelOrderSource(t *testing.T) {
+	store := NewMemoryOrderStore()
+	var requests int
+	c := newReplyClient(t, &pb.CancelResp{OrderNo: "T1", MerchantNo: "M1", Status: pb.ORDER_STATUS_CANCELED}, &requests, WithOrderStore(store))
+
+	if _, err := c.Cancel(&CancelParam{MerchantNo: "M1"}); err != nil {
+		t.Fatal(err)
+	}
+	history, err := store.History("M1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := history[len(history)-1]; last.Source != OrderSourceCancel || last.Status != pb.ORDER_STATUS_CANCELED {
+		t.Errorf("history = %+v", last)
+	}
+}
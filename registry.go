@@ -0,0 +1,277 @@
+package xmpay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrMerchantNotFound = errors.New("merchant not found")
+	ErrMerchantExists   = errors.New("merchant already exists")
+)
+
+// MerchantClient 商户客户端，HttpClient 与 GrpcClient 均已实现
+type MerchantClient interface {
+	PayClient
+	CallbackParser
+}
+
+// ClientFactory 根据商户配置创建客户端
+type ClientFactory func(config *Config) (MerchantClient, error)
+
+// HttpClientFactory 为每个商户创建 HttpClient
+func HttpClientFactory(log *logrus.Entry, opts ...Option) ClientFactory {
+	return func(config *Config) (MerchantClient, error) {
+		c, err := NewHttpClientE(config, log, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+}
+
+// GrpcClientFactory 为每个商户创建 GrpcClient
+func GrpcClientFactory(log *logrus.Entry, opts ...Option) ClientFactory {
+	return func(config *Config) (MerchantClient, error) {
+		c, err := NewGrpcClient(config, log, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+}
+
+type merchant struct {
+	client   MerchantClient
+	inflight sync.WaitGroup
+}
+
+// MerchantRegistry 多商户客户端注册表，按商户标识路由调用，按客户端当前的主密钥和旧密钥路由回调
+type MerchantRegistry struct {
+	factory ClientFactory
+	log     *logrus.Entry
+
+	mu        sync.RWMutex
+	merchants map[string]*merchant
+}
+
+// NewMerchantRegistry 为每个商户配置创建并缓存一个客户端
+func NewMerchantRegistry(configs []Config, factory ClientFactory, log *logrus.Entry) (*MerchantRegistry, error) {
+	if log == nil {
+		log = logrus.WithField("model", "MerchantRegistry")
+	}
+	r := &MerchantRegistry{
+		factory:   factory,
+		log:       log,
+		merchants: make(map[string]*merchant),
+	}
+	for i := range configs {
+		config := configs[i]
+		if err := r.Add(&config); err != nil {
+			_ = r.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Add 添加商户，以 MerchantId 为商户标识，为空时使用客户端创建时解析的 AccessId，
+// 之后轮换密钥不改变商户标识。标识已存在时返回 ErrMerchantExists
+func (r *MerchantRegistry) Add(config *Config) error {
+	if config.MerchantId != "" && r.exists(config.MerchantId) {
+		return fmt.Errorf("%w: %s", ErrMerchantExists, config.MerchantId)
+	}
+
+	client, err := r.factory(config)
+	if err != nil {
+		return err
+	}
+	id := merchantId(client, config)
+	if id == "" {
+		closeClient(client)
+		return errors.New("merchant id and access id are empty")
+	}
+
+	label := config.MerchantId
+	if label == "" {
+		label = maskSecret(id)
+	}
+	r.mu.Lock()
+	if _, ok := r.merchants[id]; ok {
+		r.mu.Unlock()
+		closeClient(client)
+		return fmt.Errorf("%w: %s", ErrMerchantExists, label)
+	}
+	r.merchants[id] = &merchant{client: client}
+	r.mu.Unlock()
+	r.log.Infof("merchant added: %s", label)
+	return nil
+}
+
+func (r *MerchantRegistry) exists(id string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.merchants[id]
+	return ok
+}
+
+// merchantId 返回商户标识：MerchantId，为空时为客户端当前的主密钥 AccessId
+func merchantId(client MerchantClient, config *Config) string {
+	if config.MerchantId != "" {
+		return config.MerchantId
+	}
+	if c, ok := client.(keyHolder); ok {
+		primary, _ := c.Keys()
+		return primary.AccessId
+	}
+	return config.AccessId
+}
+
+// keyHolder 可返回当前密钥的客户端
+type keyHolder interface {
+	Keys() (Credentials, []Credentials)
+}
+
+// Remove 移除商户，新的调用立即返回 ErrMerchantNotFound，
+// 等待进行中的调用结束后关闭客户端
+func (r *MerchantRegistry) Remove(id string) error {
+	r.mu.Lock()
+	m, ok := r.merchants[id]
+	delete(r.merchants, id)
+	r.mu.Unlock()
+	if !ok {
+		return ErrMerchantNotFound
+	}
+
+	m.inflight.Wait()
+	closeClient(m.client)
+	r.log.Infof("merchant removed: %s", maskSecret(id))
+	return nil
+}
+
+// Merchants 返回已注册商户的标识
+func (r *MerchantRegistry) Merchants() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]string, 0, len(r.merchants))
+	for id := range r.merchants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Client 返回商户客户端，商户被移除后客户端可能已关闭，长时间持有时应使用 Do
+func (r *MerchantRegistry) Client(id string) (MerchantClient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.merchants[id]
+	if !ok {
+		return nil, ErrMerchantNotFound
+	}
+	return m.client, nil
+}
+
+// Do 使用商户客户端执行 fn，执行期间移除商户不会关闭该客户端
+func (r *MerchantRegistry) Do(id string, fn func(c MerchantClient) error) error {
+	m, err := r.acquire(id)
+	if err != nil {
+		return err
+	}
+	defer m.inflight.Done()
+	return fn(m.client)
+}
+
+func (r *MerchantRegistry) acquire(id string) (*merchant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.merchants[id]
+	if !ok {
+		return nil, ErrMerchantNotFound
+	}
+	m.inflight.Add(1)
+	return m, nil
+}
+
+// ParseCallback 按回调请求体中的 app_key 路由到对应商户解析，
+// 可作为 CallbackDispatcher 的 CallbackParser 使用
+func (r *MerchantRegistry) ParseCallback(body []byte) (*pb.CallbackParam, error) {
+	_, cb, err := r.ParseMerchantCallback(body)
+	return cb, err
+}
+
+// ParseMerchantCallback 解析回调并返回所属商户的标识。app_key 依次与各商户当前的主密钥、
+// 旧密钥匹配，密钥轮换后以新旧密钥加密的回调都能路由到原商户
+func (r *MerchantRegistry) ParseMerchantCallback(body []byte) (string, *pb.CallbackParam, error) {
+	var param pb.PayRpcParam
+	if err := json.Unmarshal(body, &param); err != nil {
+		return "", nil, err
+	}
+	id := r.route(param.AppKey)
+	if id == "" {
+		return "", nil, ErrCallbackAppKey
+	}
+	var cb *pb.CallbackParam
+	err := r.Do(id, func(c MerchantClient) error {
+		var err error
+		cb, err = c.ParseCallback(body)
+		return err
+	})
+	if errors.Is(err, ErrMerchantNotFound) {
+		return "", nil, ErrCallbackAppKey
+	}
+	return id, cb, err
+}
+
+// route 返回密钥与 appKey 匹配的商户标识，主密钥优先于旧密钥；
+// 无法返回密钥的客户端按商户标识匹配
+func (r *MerchantRegistry) route(appKey string) string {
+	if appKey == "" {
+		return ""
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var fallback string
+	for id, m := range r.merchants {
+		c, ok := m.client.(keyHolder)
+		if !ok {
+			if id == appKey {
+				fallback = id
+			}
+			continue
+		}
+		primary, previous := c.Keys()
+		if primary.AccessId == appKey {
+			return id
+		}
+		for _, p := range previous {
+			if p.AccessId == appKey {
+				fallback = id
+			}
+		}
+	}
+	return fallback
+}
+
+// Close 移除并关闭所有商户客户端
+func (r *MerchantRegistry) Close() error {
+	for _, id := range r.Merchants() {
+		_ = r.Remove(id)
+	}
+	return nil
+}
+
+func closeClient(client interface{}) {
+	if c, ok := client.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
+
+var _ CallbackParser = (*MerchantRegistry)(nil)
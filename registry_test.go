@@ -0,0 +1,140 @@
+package xmpay
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+// closingClient 记录是否已关闭的客户端
+type closingClient struct {
+	*HttpClient
+	closed atomic.Bool
+}
+
+func (c *closingClient) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func newTestRegistry(t *testing.T, configs ...Config) (*MerchantRegistry, map[string]*closingClient) {
+	t.Helper()
+	clients := map[string]*closingClient{}
+	factory := func(config *Config) (MerchantClient, error) {
+		c, err := NewHttpClientE(config, testLogger())
+		if err != nil {
+			return nil, err
+		}
+		cc := &closingClient{HttpClient: c}
+		clients[config.MerchantId+config.AccessId] = cc
+		return cc, nil
+	}
+	r, err := NewMerchantRegistry(configs, factory, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+	return r, clients
+}
+
+func TestRegistryAdd(t *testing.T) {
+	config := *testConfig("http://127.0.0.1:1")
+	r, _ := newTestRegistry(t, config)
+
+	if err := r.Add(&config); !errors.Is(err, ErrMerchantExists) {
+		t.Errorf("duplicate access id, err = %v", err)
+	}
+	other := config
+	other.MerchantId = "M002"
+	if err := r.Add(&other); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add(&other); !errors.Is(err, ErrMerchantExists) {
+		t.Errorf("duplicate merchant id, err = %v", err)
+	}
+
+	// 密钥由 SecretProvider 提供时以解析后的 AccessId 为标识
+	secrets := &testSecrets{cred: Credentials{AccessId: "1111111111111111", AccessKey: "2222222222222222"}}
+	r.factory = HttpClientFactory(testLogger(), WithSecretProvider(secrets))
+	provided := config
+	provided.AccessId, provided.AccessKey = "", ""
+	if err := r.Add(&provided); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Merchants(); len(got) != 3 || got[0] != testAccessId || got[1] != secrets.cred.AccessId || got[2] != "M002" {
+		t.Errorf("merchants = %v", got)
+	}
+}
+
+func TestRegistryRemoveInflight(t *testing.T) {
+	r, clients := newTestRegistry(t, *testConfig("http://127.0.0.1:1"))
+	client := clients[testAccessId]
+
+	started, release, done := make(chan struct{}), make(chan struct{}), make(chan error)
+	go func() {
+		done <- r.Do(testAccessId, func(c MerchantClient) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	removed := make(chan error)
+	go func() { removed <- r.Remove(testAccessId) }()
+	// 移除后新的调用立即失败，进行中的调用结束前不关闭客户端
+	deadline := time.Now().Add(time.Second)
+	for len(r.Merchants()) != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := r.Do(testAccessId, func(c MerchantClient) error { return nil }); !errors.Is(err, ErrMerchantNotFound) {
+		t.Errorf("call after remove, err = %v", err)
+	}
+	if client.closed.Load() {
+		t.Fatal("client closed during call")
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+	if err := <-removed; err != nil {
+		t.Error(err)
+	}
+	if !client.closed.Load() {
+		t.Error("client not closed after remove")
+	}
+}
+
+func TestRegistryCallbackRouting(t *testing.T) {
+	config := *testConfig("http://127.0.0.1:1")
+	config.MerchantId = "M001"
+	other := *testConfig("http://127.0.0.1:1")
+	other.MerchantId, other.AccessId = "M002", "abcdefabcdefabcd"
+	r, clients := newTestRegistry(t, config, other)
+
+	// 轮换密钥后，新旧密钥加密的回调都路由到原商户
+	rotated := Credentials{AccessId: "1111111111111111", AccessKey: "2222222222222222"}
+	if err := clients["M001"+testAccessId].SetKeys(rotated, Credentials{AccessId: testAccessId, AccessKey: testAccessKey}); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []Credentials{rotated, {testAccessId, testAccessKey}} {
+		body := callbackBody(t, key.AccessId, key.AccessKey, &pb.CallbackParam{MerchantNo: "O1", Status: pb.ORDER_STATUS_SUCCESS})
+		id, cb, err := r.ParseMerchantCallback(body)
+		if err != nil || id != "M001" || cb.MerchantNo != "O1" {
+			t.Errorf("callback with %s: %s, %+v, %v", key.AccessId, id, cb, err)
+		}
+	}
+	body := callbackBody(t, other.AccessId, testAccessKey, &pb.CallbackParam{MerchantNo: "O2"})
+	if id, _, err := r.ParseMerchantCallback(body); err != nil || id != "M002" {
+		t.Errorf("other merchant: %s, %v", id, err)
+	}
+
+	body = callbackBody(t, "2222222222222222", testAccessKey, &pb.CallbackParam{MerchantNo: "O3"})
+	if _, _, err := r.ParseMerchantCallback(body); !errors.Is(err, ErrCallbackAppKey) {
+		t.Errorf("unknown app key, err = %v", err)
+	}
+}
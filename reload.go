@@ -0,0 +1,319 @@
+package xmpay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrClientClosed = errors.New("client closed")
+
+// configurable 支持不重建即替换配置和密钥的客户端
+type configurable interface {
+	setConfig(config *Config)
+	SetKeys(primary Credentials, previous ...Credentials) error
+	Keys() (Credentials, []Credentials)
+	resolveKeys(config *Config) (Credentials, error)
+}
+
+// ReloadableClient 支持配置热更新的客户端。通知地址、默认通道、密钥等配置原子替换后立即生效，
+// 密钥变化时原密钥保留为旧密钥，仍可解密轮换前加密的回调；ApiUrl 变化时重建客户端，
+// 旧客户端在进行中的调用结束后关闭
+type ReloadableClient struct {
+	factory ClientFactory
+	log     *logrus.Entry
+
+	reload  sync.Mutex // 串行化 Reload，避免并发重建
+	mu      sync.RWMutex
+	current *merchant
+	config  *Config
+}
+
+// NewReloadableClient 创建客户端，并以解析后的密钥校验配置
+func NewReloadableClient(config *Config, factory ClientFactory, log *logrus.Entry) (*ReloadableClient, error) {
+	if log == nil {
+		log = logrus.WithField("model", "ReloadableClient")
+	}
+	client, err := factory(config)
+	if err != nil {
+		return nil, err
+	}
+	if err = validateClient(client, config); err != nil {
+		closeClient(client)
+		return nil, err
+	}
+	return &ReloadableClient{
+		factory: factory,
+		log:     log,
+		current: &merchant{client: client},
+		config:  config,
+	}, nil
+}
+
+// Config 返回当前生效的配置
+func (r *ReloadableClient) Config() *Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.config
+}
+
+// Keys 返回当前客户端的主密钥和旧密钥，客户端不支持时返回配置中的密钥
+func (r *ReloadableClient) Keys() (Credentials, []Credentials) {
+	r.mu.RLock()
+	cur, config := r.current, r.config
+	r.mu.RUnlock()
+	if cur != nil {
+		if c, ok := cur.client.(keyHolder); ok {
+			return c.Keys()
+		}
+	}
+	return Credentials{AccessId: config.AccessId, AccessKey: config.AccessKey}, nil
+}
+
+// Reload 校验并应用新配置，校验失败时保留原配置。配置了 SecretProvider 时密钥以提供者返回的为准
+func (r *ReloadableClient) Reload(config *Config) error {
+	r.reload.Lock()
+	defer r.reload.Unlock()
+
+	r.mu.RLock()
+	cur, prev := r.current, r.config
+	r.mu.RUnlock()
+	if cur == nil {
+		return ErrClientClosed
+	}
+
+	c, ok := cur.client.(configurable)
+	if !ok || needRebuild(prev, config) {
+		return r.rebuild(cur, config)
+	}
+	cred, err := c.resolveKeys(config)
+	if err != nil {
+		return err
+	}
+	if err = validateWithKeys(config, cred); err != nil {
+		return err
+	}
+	if primary, _ := c.Keys(); primary != cred {
+		if err = rotateKeys(c, c, cred); err != nil {
+			return err
+		}
+	}
+	c.setConfig(config)
+	r.mu.Lock()
+	r.config = config
+	r.mu.Unlock()
+	r.log.Info("config reloaded")
+	return nil
+}
+
+// needRebuild ApiUrl 或负载均衡策略变化时需要重建客户端（gRPC 重新拨号）
+func needRebuild(old, config *Config) bool {
+	return !equalStrings(old.Endpoints(), config.Endpoints()) || old.LoadBalance != config.LoadBalance
+}
+
+// rotateKeys 使用 primary 加密请求，from 的主密钥和旧密钥保留为旧密钥
+func rotateKeys(c, from configurable, primary Credentials) error {
+	old, previous := from.Keys()
+	return c.SetKeys(primary, mergeKeys(primary, append([]Credentials{old}, previous...)...)...)
+}
+
+// validateClient 以客户端解析后的主密钥校验配置
+func validateClient(client MerchantClient, config *Config) error {
+	cred := Credentials{AccessId: config.AccessId, AccessKey: config.AccessKey}
+	if c, ok := client.(configurable); ok {
+		cred, _ = c.Keys()
+	}
+	return validateWithKeys(config, cred)
+}
+
+// validateWithKeys 以 cred 代替配置中的密钥校验，使用 SecretProvider 时配置中的 access_id 可为空
+func validateWithKeys(config *Config, cred Credentials) error {
+	resolved := *config
+	resolved.AccessId, resolved.AccessKey = cred.AccessId, cred.AccessKey
+	return resolved.Validate()
+}
+
+// rebuild 创建新客户端并替换，等待旧客户端进行中的调用结束后关闭。调用方持有 r.reload
+func (r *ReloadableClient) rebuild(old *merchant, config *Config) error {
+	client, err := r.factory(config)
+	if err != nil {
+		return err
+	}
+	if err = validateClient(client, config); err != nil {
+		closeClient(client)
+		return err
+	}
+	// 新客户端保留旧客户端的密钥，避免丢弃轮换期间仍在使用的密钥
+	next, ok1 := client.(configurable)
+	prev, ok2 := old.client.(configurable)
+	if ok1 && ok2 {
+		primary, _ := next.Keys()
+		if err = rotateKeys(next, prev, primary); err != nil {
+			closeClient(client)
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	if r.current == nil {
+		r.mu.Unlock()
+		closeClient(client)
+		return ErrClientClosed
+	}
+	r.current = &merchant{client: client}
+	r.config = config
+	r.mu.Unlock()
+	r.log.Info("config reloaded, client rebuilt")
+
+	go func() {
+		old.inflight.Wait()
+		closeClient(old.client)
+	}()
+	return nil
+}
+
+// Watch 从 ch 接收新配置并应用，直到 ctx 结束或 ch 关闭
+func (r *ReloadableClient) Watch(ctx context.Context, ch <-chan *Config) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case config, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := r.Reload(config); err != nil {
+				r.log.Errorf("config reload failed, err: %v", err)
+			}
+		}
+	}
+}
+
+// WatchFile 定期检查配置文件，内容变化时通过 LoadConfig 加载并应用，直到 ctx 结束
+func (r *ReloadableClient) WatchFile(ctx context.Context, path string, interval time.Duration) {
+	last, _ := os.ReadFile(path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			data, err := os.ReadFile(path)
+			if err != nil {
+				r.log.Errorf("config read failed, err: %v", err)
+				continue
+			}
+			if bytes.Equal(data, last) {
+				continue
+			}
+			last = data
+
+			config, err := LoadConfig(path)
+			if err == nil {
+				err = r.Reload(config)
+			}
+			if err != nil {
+				r.log.Errorf("config reload failed, err: %v", err)
+			}
+		}
+	}
+}
+
+// Close 关闭客户端，等待进行中的调用结束
+func (r *ReloadableClient) Close() error {
+	r.mu.Lock()
+	old := r.current
+	r.current = nil
+	r.mu.Unlock()
+	if old != nil {
+		old.inflight.Wait()
+		closeClient(old.client)
+	}
+	return nil
+}
+
+func (r *ReloadableClient) do(fn func(c MerchantClient) error) error {
+	r.mu.RLock()
+	m := r.current
+	if m == nil {
+		r.mu.RUnlock()
+		return ErrClientClosed
+	}
+	m.inflight.Add(1)
+	r.mu.RUnlock()
+	defer m.inflight.Done()
+	return fn(m.client)
+}
+
+func (r *ReloadableClient) CreateVirtual(param *OrderParam) (data *pb.VirtualResp, err error) {
+	err = r.do(func(c MerchantClient) (err error) {
+		data, err = c.CreateVirtual(param)
+		return err
+	})
+	return data, err
+}
+
+func (r *ReloadableClient) CreateReceive(param *ReceiveParam) (data *pb.ReceiveResp, err error) {
+	err = r.do(func(c MerchantClient) (err error) {
+		data, err = c.CreateReceive(param)
+		return err
+	})
+	return data, err
+}
+
+func (r *ReloadableClient) QueryReceive(orderNo, trxNo string) (data *pb.OrderQueryResp, err error) {
+	err = r.do(func(c MerchantClient) (err error) {
+		data, err = c.QueryReceive(orderNo, trxNo)
+		return err
+	})
+	return data, err
+}
+
+func (r *ReloadableClient) CreateOut(param *OutParam) (data *pb.OutResp, err error) {
+	err = r.do(func(c MerchantClient) (err error) {
+		data, err = c.CreateOut(param)
+		return err
+	})
+	return data, err
+}
+
+func (r *ReloadableClient) QueryOut(orderNo, trxNo string) (data *pb.OrderQueryResp, err error) {
+	err = r.do(func(c MerchantClient) (err error) {
+		data, err = c.QueryOut(orderNo, trxNo)
+		return err
+	})
+	return data, err
+}
+
+func (r *ReloadableClient) Channel(orderType pb.ORDER_TYPE) (data []*pb.ChannelQueryResp, err error) {
+	err = r.do(func(c MerchantClient) (err error) {
+		data, err = c.Channel(orderType)
+		return err
+	})
+	return data, err
+}
+
+func (r *ReloadableClient) Balance() (data *pb.MerchantBalanceResp, err error) {
+	err = r.do(func(c MerchantClient) (err error) {
+		data, err = c.Balance()
+		return err
+	})
+	return data, err
+}
+
+func (r *ReloadableClient) ParseCallback(body []byte) (data *pb.CallbackParam, err error) {
+	err = r.do(func(c MerchantClient) (err error) {
+		data, err = c.ParseCallback(body)
+		return err
+	})
+	return data, err
+}
+
+var _ MerchantClient = (*ReloadableClient)(nil)
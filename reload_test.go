@@ -0,0 +1,175 @@
+package xmpay
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+// callbackBody 使用 accessId/accessKey 加密回调参数
+func callbackBody(t *testing.T, accessId, accessKey string, cb *pb.CallbackParam) []byte {
+	t.Helper()
+	data, err := json.Marshal(cb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypt, err := NewAES([]byte(accessId), []byte(accessKey)).Encrypt(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := json.Marshal(&pb.PayRpcParam{AppKey: accessId, Data: encrypt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func newTestReloadable(t *testing.T, builds *int) *ReloadableClient {
+	t.Helper()
+	factory := func(config *Config) (MerchantClient, error) {
+		*builds++
+		return NewHttpClient(config, testLogger()), nil
+	}
+	r, err := NewReloadableClient(testConfig("http://127.0.0.1:1"), factory, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+	return r
+}
+
+func TestReloadRotatesKeys(t *testing.T) {
+	var builds int
+	r := newTestReloadable(t, &builds)
+	client := r.current.client.(*HttpClient)
+
+	config := *r.Config()
+	config.AccessId, config.AccessKey = "1111111111111111", "2222222222222222"
+	config.InNotifyUrl = "http://127.0.0.1/notify"
+	if err := r.Reload(&config); err != nil {
+		t.Fatal(err)
+	}
+	if builds != 1 || r.current.client != MerchantClient(client) {
+		t.Fatalf("client rebuilt on key change, builds = %d", builds)
+	}
+	primary, previous := client.Keys()
+	if primary.AccessId != config.AccessId || len(previous) != 1 || previous[0].AccessId != testAccessId {
+		t.Errorf("keys = %v, %v", primary, previous)
+	}
+	if got := client.CurrentConfig(); got.InNotifyUrl != config.InNotifyUrl || got.AccessId != config.AccessId {
+		t.Errorf("client config not updated: %+v", got)
+	}
+
+	// 轮换前加密的回调仍可解密
+	for _, key := range []Credentials{{testAccessId, testAccessKey}, {config.AccessId, config.AccessKey}} {
+		body := callbackBody(t, key.AccessId, key.AccessKey, &pb.CallbackParam{MerchantNo: "M1", Status: pb.ORDER_STATUS_SUCCESS})
+		if _, err := r.ParseCallback(body); err != nil {
+			t.Errorf("callback with %s: %v", key.AccessId, err)
+		}
+	}
+}
+
+func TestReloadRebuildKeepsKey(t *testing.T) {
+	var builds int
+	r := newTestReloadable(t, &builds)
+
+	config := *r.Config()
+	config.ApiUrl = "http://127.0.0.1:2"
+	config.AccessId, config.AccessKey = "1111111111111111", "2222222222222222"
+	if err := r.Reload(&config); err != nil {
+		t.Fatal(err)
+	}
+	if builds != 2 {
+		t.Fatalf("builds = %d, want 2", builds)
+	}
+	_, previous := r.current.client.(*HttpClient).Keys()
+	if len(previous) != 1 || previous[0].AccessId != testAccessId {
+		t.Errorf("previous keys = %v", previous)
+	}
+}
+
+func TestReloadConcurrent(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		builds int
+	)
+	factory := func(config *Config) (MerchantClient, error) {
+		mu.Lock()
+		builds++
+		mu.Unlock()
+		return NewHttpClient(config, testLogger()), nil
+	}
+	r, err := NewReloadableClient(testConfig("http://127.0.0.1:1"), factory, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	config := *r.Config()
+	config.ApiUrl = "http://127.0.0.1:2"
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := config
+			if err := r.Reload(&c); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	// 首次 Reload 重建后其余 Reload 只替换配置
+	if builds != 2 {
+		t.Errorf("builds = %d, want 2", builds)
+	}
+}
+
+// testSecrets 可在测试中修改的密钥提供者
+type testSecrets struct{ cred Credentials }
+
+func (p *testSecrets) Credentials() (*Credentials, error) {
+	cred := p.cred
+	return &cred, nil
+}
+
+func TestReloadSecretProvider(t *testing.T) {
+	secrets := &testSecrets{cred: Credentials{AccessId: testAccessId, AccessKey: testAccessKey}}
+	config := testConfig("http://127.0.0.1:1")
+	config.AccessId, config.AccessKey = "", ""
+	r, err := NewReloadableClient(config, HttpClientFactory(testLogger(), WithSecretProvider(secrets)), testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	client := r.current.client.(*HttpClient)
+
+	// 配置中没有密钥时保留提供者的密钥
+	next := *config
+	next.InNotifyUrl = "http://127.0.0.1/notify"
+	if err = r.Reload(&next); err != nil {
+		t.Fatal(err)
+	}
+	if primary, _ := client.Keys(); primary.AccessId != testAccessId {
+		t.Errorf("primary = %v", primary)
+	}
+
+	// 提供者的密钥变化时原地轮换，配置中的密钥不生效
+	secrets.cred = Credentials{AccessId: "1111111111111111", AccessKey: "2222222222222222"}
+	next.AccessId, next.AccessKey = "3333333333333333", ""
+	if err = r.Reload(&next); err != nil {
+		t.Fatal(err)
+	}
+	primary, previous := client.Keys()
+	if primary != secrets.cred || len(previous) == 0 || previous[0].AccessId != testAccessId {
+		t.Errorf("keys = %v, %v", primary, previous)
+	}
+
+	// 未配置提供者时仍要求合法的 access_id
+	factory := func(config *Config) (MerchantClient, error) { return NewHttpClient(config, testLogger()), nil }
+	if _, err = NewReloadableClient(config, factory, testLogger()); err == nil {
+		t.Error("empty access id accepted without secret provider")
+	}
+}
@@ -0,0 +1,202 @@
+package xmpay
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Credentials 商户密钥
+type Credentials struct {
+	AccessId  string `json:"accessId" yaml:"access_id"`
+	AccessKey string `json:"accessKey" yaml:"access_key"`
+}
+
+// SecretProvider 密钥提供者，客户端在创建和刷新密钥时调用
+type SecretProvider interface {
+	Credentials() (*Credentials, error)
+}
+
+// credentials 客户端当前使用的密钥
+type credentials struct {
+	accessId  string
+	accessKey string
+	aes       *AES
+}
+
+func newCredentials(accessId, accessKey string) (*credentials, error) {
+	if _, err := aes.NewCipher([]byte(accessId)); err != nil {
+		return nil, fmt.Errorf("invalid access id: %w", err)
+	}
+	if accessKey != "" && len(accessKey) < aes.BlockSize {
+		return nil, fmt.Errorf("invalid access key: length must be at least %d bytes", aes.BlockSize)
+	}
+	return &credentials{
+		accessId:  accessId,
+		accessKey: accessKey,
+		aes:       NewAES([]byte(accessId), []byte(accessKey)),
+	}, nil
+}
+
+// RefreshCredentials 从 SecretProvider 重新获取密钥并原子替换，未配置提供者时不做处理。
+// 密钥变化时原主密钥和 SetKeys 设置的旧密钥均保留为旧密钥，轮换期间仍可解密使用旧密钥加密的回调
+func (c *PayClientImpl) RefreshCredentials() error {
+	if c.secrets == nil {
+		return nil
+	}
+	cred, err := c.secrets.Credentials()
+	if err != nil {
+		return err
+	}
+	next, err := newCredentials(cred.AccessId, cred.AccessKey)
+	if err != nil {
+		return err
+	}
+	cur := c.keys.Load()
+	if cur != nil && cur.primary.accessId == next.accessId && cur.primary.accessKey == next.accessKey {
+		return nil
+	}
+	r := cur.rotate(next)
+	c.keys.Store(r)
+	c.log.Infof("credentials refreshed, accessId: %s, previous: %d", maskSecret(next.accessId), len(r.previous))
+	return nil
+}
+
+// resolveKeys 返回 config 生效的主密钥，配置了 SecretProvider 时以提供者返回的为准
+func (c *PayClientImpl) resolveKeys(config *Config) (Credentials, error) {
+	if c.secrets == nil {
+		return Credentials{AccessId: config.AccessId, AccessKey: config.AccessKey}, nil
+	}
+	cred, err := c.secrets.Credentials()
+	if err != nil {
+		return Credentials{}, err
+	}
+	return *cred, nil
+}
+
+// WatchCredentials 定期刷新密钥直到 ctx 结束，用于密钥轮换
+func (c *PayClientImpl) WatchCredentials(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.RefreshCredentials(); err != nil {
+				c.log.Errorf("credentials refresh failed, err: %v", err)
+			}
+		}
+	}
+}
+
+func maskSecret(s string) string {
+	if len(s) <= 4 {
+		return "****"
+	}
+	return s[:4] + "****"
+}
+
+// StaticSecretProvider 固定密钥，用于本地开发和测试
+type StaticSecretProvider Credentials
+
+func (p StaticSecretProvider) Credentials() (*Credentials, error) {
+	cred := Credentials(p)
+	return &cred, nil
+}
+
+// EnvSecretProvider 从环境变量读取密钥
+type EnvSecretProvider struct {
+	IdEnv  string
+	KeyEnv string
+}
+
+// NewEnvSecretProvider 读取 XMPAY_ACCESS_ID 与 XMPAY_ACCESS_KEY
+func NewEnvSecretProvider() *EnvSecretProvider {
+	return &EnvSecretProvider{IdEnv: EnvPrefix + "ACCESS_ID", KeyEnv: EnvPrefix + "ACCESS_KEY"}
+}
+
+func (p *EnvSecretProvider) Credentials() (*Credentials, error) {
+	cred := &Credentials{AccessId: os.Getenv(p.IdEnv), AccessKey: os.Getenv(p.KeyEnv)}
+	if cred.AccessId == "" {
+		return nil, fmt.Errorf("env %s is empty", p.IdEnv)
+	}
+	return cred, nil
+}
+
+// FileSecretProvider 从文件读取密钥，文件内容首尾空白会被忽略，
+// 适用于以文件方式挂载的 Kubernetes Secret
+type FileSecretProvider struct {
+	IdFile  string
+	KeyFile string
+}
+
+// NewFileSecretProvider 读取目录下的 access_id 与 access_key 文件
+func NewFileSecretProvider(dir string) *FileSecretProvider {
+	return &FileSecretProvider{
+		IdFile:  filepath.Join(dir, "access_id"),
+		KeyFile: filepath.Join(dir, "access_key"),
+	}
+}
+
+func (p *FileSecretProvider) Credentials() (*Credentials, error) {
+	id, err := os.ReadFile(p.IdFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := os.ReadFile(p.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &Credentials{
+		AccessId:  strings.TrimSpace(string(id)),
+		AccessKey: strings.TrimSpace(string(key)),
+	}, nil
+}
+
+// ExecSecretProvider 执行外部命令获取密钥，命令需向标准输出写入
+// {"accessId":"...","accessKey":"..."} 格式的 JSON
+type ExecSecretProvider struct {
+	Command string
+	Args    []string
+	Timeout time.Duration
+}
+
+// NewExecSecretProvider 创建命令密钥提供者，默认超时 10 秒
+func NewExecSecretProvider(command string, args ...string) *ExecSecretProvider {
+	return &ExecSecretProvider{Command: command, Args: args, Timeout: 10 * time.Second}
+}
+
+func (p *ExecSecretProvider) Credentials() (*Credentials, error) {
+	ctx := context.Background()
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Command, p.Args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("secret command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var cred Credentials
+	if err := json.Unmarshal(stdout.Bytes(), &cred); err != nil {
+		return nil, fmt.Errorf("secret command output invalid: %w", err)
+	}
+	if cred.AccessId == "" {
+		return nil, errors.New("secret command output missing accessId")
+	}
+	return &cred, nil
+}
@@ -0,0 +1,144 @@
+package xmpay
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+// failingSecretProvider 总是返回错误的密钥提供者
+type failingSecretProvider struct{ err error }
+
+func (p failingSecretProvider) Credentials() (*Credentials, error) {
+	return nil, p.err
+}
+
+// secretProvider 每次调用返回 creds 中的下一组密钥
+type secretProvider struct {
+	creds []Credentials
+	calls int
+}
+
+func (p *secretProvider) Credentials() (*Credentials, error) {
+	cred := p.creds[p.calls%len(p.creds)]
+	p.calls++
+	return &cred, nil
+}
+
+func TestSecretProviderOverridesConfig(t *testing.T) {
+	want := Credentials{AccessId: "1111111111111111", AccessKey: "2222222222222222"}
+	provider := WithSecretProvider(StaticSecretProvider(want))
+
+	hc, err := NewHttpClientE(testConfig("http://127.0.0.1:1"), testLogger(), provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gc, err := NewGrpcClient(testConfig("127.0.0.1:1"), testLogger(), provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gc.Close()
+
+	for name, c := range map[string]*PayClientImpl{"http": &hc.PayClientImpl, "grpc": &gc.PayClientImpl} {
+		if primary, _ := c.Keys(); primary != want {
+			t.Errorf("%s: primary = %v, want %v", name, primary, want)
+		}
+	}
+}
+
+func TestSecretProviderError(t *testing.T) {
+	errSecret := errors.New("secret unavailable")
+	provider := WithSecretProvider(failingSecretProvider{errSecret})
+
+	if _, err := NewHttpClientE(testConfig("http://127.0.0.1:1"), testLogger(), provider); !errors.Is(err, errSecret) {
+		t.Errorf("NewHttpClientE: err = %v", err)
+	}
+	if _, err := NewGrpcClient(testConfig("127.0.0.1:1"), testLogger(), provider); !errors.Is(err, errSecret) {
+		t.Errorf("NewGrpcClient: err = %v", err)
+	}
+	if _, err := HttpClientFactory(testLogger(), provider)(testConfig("http://127.0.0.1:1")); !errors.Is(err, errSecret) {
+		t.Errorf("HttpClientFactory: err = %v", err)
+	}
+
+	// NewHttpClient 回退到配置中的密钥
+	c := NewHttpClient(testConfig("http://127.0.0.1:1"), testLogger(), provider)
+	if primary, _ := c.Keys(); primary.AccessId != testAccessId {
+		t.Errorf("fallback primary = %v", primary)
+	}
+}
+
+func TestRefreshCredentialsRotates(t *testing.T) {
+	first := Credentials{AccessId: "1111111111111111", AccessKey: "2222222222222222"}
+	second := Credentials{AccessId: "3333333333333333", AccessKey: "4444444444444444"}
+	provider := &secretProvider{creds: []Credentials{first, first, second}}
+
+	c, err := NewHttpClientE(testConfig("http://127.0.0.1:1"), testLogger(), WithSecretProvider(provider))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 密钥未变化时不替换
+	if err = c.RefreshCredentials(); err != nil {
+		t.Fatal(err)
+	}
+	if primary, previous := c.Keys(); primary != first || len(previous) != 1 || previous[0].AccessId != testAccessId {
+		t.Fatalf("keys = %v, %v", primary, previous)
+	}
+
+	if err = c.RefreshCredentials(); err != nil {
+		t.Fatal(err)
+	}
+	primary, previous := c.Keys()
+	if primary != second || len(previous) != 2 || previous[0] != first || previous[1].AccessId != testAccessId {
+		t.Errorf("keys = %v, %v, want %v, [%v %s]", primary, previous, second, first, testAccessId)
+	}
+	body := callbackBody(t, first.AccessId, first.AccessKey, &pb.CallbackParam{MerchantNo: "M1"})
+	if _, err = c.ParseCallback(body); err != nil {
+		t.Errorf("callback with previous key: %v", err)
+	}
+}
+
+func TestRefreshCredentialsInvalid(t *testing.T) {
+	c := NewHttpClient(testConfig("http://127.0.0.1:1"), testLogger(),
+		WithSecretProvider(StaticSecretProvider{AccessId: "short", AccessKey: "2222222222222222"}))
+	if err := c.RefreshCredentials(); err == nil {
+		t.Error("invalid access id accepted")
+	}
+	if primary, _ := c.Keys(); primary.AccessId != testAccessId {
+		t.Errorf("primary replaced by invalid key: %v", primary)
+	}
+}
+
+func TestFileSecretProvider(t *testing.T) {
+	dir := t.TempDir()
+	for name, value := range map[string]string{"access_id": " 1111111111111111\n", "access_key": "2222222222222222\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cred, err := NewFileSecretProvider(dir).Credentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *cred != (Credentials{AccessId: "1111111111111111", AccessKey: "2222222222222222"}) {
+		t.Errorf("credentials = %v", cred)
+	}
+	if _, err = NewFileSecretProvider(t.TempDir()).Credentials(); err == nil {
+		t.Error("missing files accepted")
+	}
+}
+
+func TestEnvSecretProvider(t *testing.T) {
+	t.Setenv(EnvPrefix+"ACCESS_ID", "")
+	if _, err := NewEnvSecretProvider().Credentials(); err == nil {
+		t.Error("empty env accepted")
+	}
+	t.Setenv(EnvPrefix+"ACCESS_ID", "1111111111111111")
+	t.Setenv(EnvPrefix+"ACCESS_KEY", "2222222222222222")
+	cred, err := NewEnvSecretProvider().Credentials()
+	if err != nil || cred.AccessId != "1111111111111111" || cred.AccessKey != "2222222222222222" {
+		t.Errorf("credentials = %v, err = %v", cred, err)
+	}
+}
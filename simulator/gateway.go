@@ -0,0 +1,510 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type gatewayOrder struct {
+	orderType  pb.ORDER_TYPE
+	resp       *pb.OrderQueryResp
+	createTime int64
+}
+
+// Gateway 模拟网关的下单、订单查询和订单状态订阅，同时提供 gRPC 服务和 HTTP 接口，用于测试订阅、轮询和命令行工具
+type Gateway struct {
+	pb.UnimplementedPayServiceServer
+
+	appKey string
+	aes    *xmpay.AES
+
+	// DisableWatch 为 true 时 watch_orders 返回 Unimplemented，用于测试轮询降级
+	DisableWatch bool
+	// DisableBatch 为 true 时 batch_query 返回 Unimplemented，HTTP 返回 404，用于测试逐个查询降级
+	DisableBatch bool
+
+	// Channels 通道查询返回的通道，按 Type 过滤
+	Channels []*pb.ChannelQueryResp
+	// Balance 商户余额查询的返回值
+	Balance *pb.MerchantBalanceResp
+
+	mu      sync.Mutex
+	orders  map[string]*gatewayOrder
+	events  []*pb.OrderStatusEvent
+	publish chan struct{} // 有新事件时关闭并替换
+	drop    chan struct{} // 断开订阅时关闭并替换
+}
+
+// NewGateway 创建模拟网关，使用配置中的 AccessId/AccessKey 加解密
+func NewGateway(config *xmpay.Config) *Gateway {
+	return &Gateway{
+		appKey:  config.AccessId,
+		aes:     xmpay.NewAES([]byte(config.AccessId), []byte(config.AccessKey)),
+		orders:  make(map[string]*gatewayOrder),
+		publish: make(chan struct{}),
+		drop:    make(chan struct{}),
+		Channels: []*pb.ChannelQueryResp{
+			{Channel: 1, Name: "SIM-RECEIVE", Type: int32(pb.ORDER_TYPE_RECEIVE), Status: 1, SingleMin: 100, SingleMax: 5000000, DayMax: 100000000},
+			{Channel: 2, Name: "SIM-OUT", Type: int32(pb.ORDER_TYPE_OUT), Status: 1, SingleMin: 100, SingleMax: 5000000, DayMax: 100000000},
+			{Channel: 3, Name: "SIM-VIRTUAL", Type: int32(pb.ORDER_TYPE_VIRTUAL), Status: 1},
+		},
+		Balance: &pb.MerchantBalanceResp{Name: "simulator", Total: 100000000, Available: 90000000, Settlement: 10000000},
+	}
+}
+
+// Register 注册到 gRPC 服务
+func (g *Gateway) Register(s *grpc.Server) {
+	pb.RegisterPayServiceServer(s, g)
+}
+
+// SetOrder 设置订单当前状态并推送状态变更事件，UpdateTime 为 0 时使用当前时间（毫秒）
+func (g *Gateway) SetOrder(orderType pb.ORDER_TYPE, resp *pb.OrderQueryResp) *pb.OrderStatusEvent {
+	r := proto.Clone(resp).(*pb.OrderQueryResp)
+	if r.UpdateTime == 0 {
+		r.UpdateTime = time.Now().UnixMilli()
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	createTime := r.UpdateTime
+	if old, ok := g.orders[r.MerchantNo]; ok {
+		createTime = old.createTime
+	}
+	g.orders[r.MerchantNo] = &gatewayOrder{orderType: orderType, resp: r, createTime: createTime}
+	event := &pb.OrderStatusEvent{
+		Cursor:     strconv.Itoa(len(g.events) + 1),
+		OrderType:  orderType,
+		OrderNo:    r.OrderNo,
+		MerchantNo: r.MerchantNo,
+		Amount:     r.Amount,
+		Fee:        r.Fee,
+		Status:     r.Status,
+		UpdateTime: r.UpdateTime,
+	}
+	g.events = append(g.events, event)
+	close(g.publish)
+	g.publish = make(chan struct{})
+	return event
+}
+
+// DropWatchers 断开当前所有订阅，客户端应按游标重连
+func (g *Gateway) DropWatchers() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	close(g.drop)
+	g.drop = make(chan struct{})
+}
+
+// Receive 创建 WAIT 状态的收款单
+func (g *Gateway) Receive(_ context.Context, param *pb.PayRpcParam) (*pb.PayRpcResp, error) {
+	return g.receive(param), nil
+}
+
+// Out 创建 WAIT 状态的付款单
+func (g *Gateway) Out(_ context.Context, param *pb.PayRpcParam) (*pb.PayRpcResp, error) {
+	return g.out(param), nil
+}
+
+// VirtualAccount 创建虚拟账户
+func (g *Gateway) VirtualAccount(_ context.Context, param *pb.PayRpcParam) (*pb.PayRpcResp, error) {
+	return g.virtual(param), nil
+}
+
+func (g *Gateway) ChannelQuery(_ context.Context, param *pb.PayRpcParam) (*pb.PayRpcResp, error) {
+	return g.channel(param), nil
+}
+
+func (g *Gateway) MerchantBalance(_ context.Context, param *pb.PayRpcParam) (*pb.PayRpcResp, error) {
+	return g.balance(param), nil
+}
+
+func (g *Gateway) ReceiveQuery(_ context.Context, param *pb.PayRpcParam) (*pb.PayRpcResp, error) {
+	return g.query(pb.ORDER_TYPE_RECEIVE, param), nil
+}
+
+func (g *Gateway) OutQuery(_ context.Context, param *pb.PayRpcParam) (*pb.PayRpcResp, error) {
+	return g.query(pb.ORDER_TYPE_OUT, param), nil
+}
+
+func (g *Gateway) BatchQuery(_ context.Context, param *pb.PayRpcParam) (*pb.PayRpcResp, error) {
+	if g.DisableBatch {
+		return nil, status.Error(codes.Unimplemented, "method BatchQuery not implemented")
+	}
+	return g.batchQuery(param), nil
+}
+
+func (g *Gateway) ListOrders(_ context.Context, param *pb.PayRpcParam) (*pb.PayRpcResp, error) {
+	return g.listOrders(param), nil
+}
+
+// Refund 对 SUCCESS 状态的收款单退款，返回 REFUNDING
+func (g *Gateway) Refund(_ context.Context, param *pb.PayRpcParam) (*pb.PayRpcResp, error) {
+	return g.refund(param), nil
+}
+
+// Cancel 取消 WAIT 状态的付款单并推送状态变更事件
+func (g *Gateway) Cancel(_ context.Context, param *pb.PayRpcParam) (*pb.PayRpcResp, error) {
+	return g.cancel(param), nil
+}
+
+// WatchOrders 推送游标之后的事件，游标为空时只推送订阅之后的事件
+func (g *Gateway) WatchOrders(param *pb.PayRpcParam, stream grpc.ServerStreamingServer[pb.PayRpcResp]) error {
+	if g.DisableWatch {
+		return status.Error(codes.Unimplemented, "method WatchOrders not implemented")
+	}
+	var req pb.WatchOrdersParam
+	if resp := g.decode(param, &req); resp != nil {
+		return stream.Send(resp)
+	}
+	merchantNos := make(map[string]bool, len(req.MerchantNos))
+	for _, no := range req.MerchantNos {
+		merchantNos[no] = true
+	}
+
+	g.mu.Lock()
+	next := len(g.events)
+	g.mu.Unlock()
+	if req.Cursor != "" {
+		cursor, err := strconv.Atoi(req.Cursor)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, "invalid cursor")
+		}
+		next = cursor
+	}
+
+	for {
+		g.mu.Lock()
+		events := g.events[min(next, len(g.events)):]
+		publish, drop := g.publish, g.drop
+		g.mu.Unlock()
+
+		for _, event := range events {
+			next++
+			if req.OrderType != pb.ORDER_TYPE_ALL && event.OrderType != req.OrderType {
+				continue
+			}
+			if len(merchantNos) > 0 && !merchantNos[event.MerchantNo] {
+				continue
+			}
+			if err := stream.Send(g.encode(event)); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-drop:
+			return status.Error(codes.Unavailable, "watcher dropped")
+		case <-publish:
+		}
+	}
+}
+
+// ServeHTTP 提供下单、订单查询、批量查询、订单列表、退款、取消付款单、通道和余额查询的 HTTP 接口
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == xmpay.BatchQuery && g.DisableBatch {
+		http.NotFound(w, r)
+		return
+	}
+	var handle func(param *pb.PayRpcParam) *pb.PayRpcResp
+	switch r.URL.Path {
+	case xmpay.CreateReceive:
+		handle = g.receive
+	case xmpay.CreateOut:
+		handle = g.out
+	case xmpay.CreateVirtual:
+		handle = g.virtual
+	case xmpay.Channel:
+		handle = g.channel
+	case xmpay.Balance:
+		handle = g.balance
+	case xmpay.QueryReceive:
+		handle = func(param *pb.PayRpcParam) *pb.PayRpcResp { return g.query(pb.ORDER_TYPE_RECEIVE, param) }
+	case xmpay.QueryOut:
+		handle = func(param *pb.PayRpcParam) *pb.PayRpcResp { return g.query(pb.ORDER_TYPE_OUT, param) }
+	case xmpay.BatchQuery:
+		handle = g.batchQuery
+	case xmpay.ListOrders:
+		handle = g.listOrders
+	case xmpay.Refund:
+		handle = g.refund
+	case xmpay.Cancel:
+		handle = g.cancel
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, xmpay.MaxCallbackBody))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var param pb.PayRpcParam
+	if err = json.Unmarshal(body, &param); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(handle(&param))
+}
+
+func (g *Gateway) receive(param *pb.PayRpcParam) *pb.PayRpcResp {
+	var req pb.ReceiveParam
+	if resp := g.decode(param, &req); resp != nil {
+		return resp
+	}
+	if resp := g.create(pb.ORDER_TYPE_RECEIVE, req.OrderNo, req.Amount, req.Pid); resp != nil {
+		return resp
+	}
+	return g.encode(&pb.ReceiveResp{
+		OrderNo:    "SIM" + req.OrderNo,
+		MerchantNo: req.OrderNo,
+		PayUrl:     "https://simulator.local/pay/" + req.OrderNo,
+	})
+}
+
+func (g *Gateway) out(param *pb.PayRpcParam) *pb.PayRpcResp {
+	var req pb.OutParam
+	if resp := g.decode(param, &req); resp != nil {
+		return resp
+	}
+	if resp := g.create(pb.ORDER_TYPE_OUT, req.OrderNo, req.Amount, req.Pid); resp != nil {
+		return resp
+	}
+	return g.encode(&pb.OutResp{OrderNo: "SIM" + req.OrderNo, MerchantNo: req.OrderNo})
+}
+
+func (g *Gateway) virtual(param *pb.PayRpcParam) *pb.PayRpcResp {
+	var req pb.VirtualParam
+	if resp := g.decode(param, &req); resp != nil {
+		return resp
+	}
+	if req.Uid == "" {
+		return &pb.PayRpcResp{Code: http.StatusBadRequest, Message: "uid is required"}
+	}
+	if resp := g.create(pb.ORDER_TYPE_VIRTUAL, req.OrderNo, 0, req.Pid); resp != nil {
+		return resp
+	}
+	return g.encode(&pb.VirtualResp{
+		OrderNo:     "SIM" + req.OrderNo,
+		MerchantNo:  req.OrderNo,
+		AccountName: req.Name,
+		AccountNo:   "SIM" + req.Uid,
+		PayUrl:      "https://simulator.local/virtual/" + req.OrderNo,
+	})
+}
+
+// create 记录 WAIT 状态的新订单，订单号重复时返回错误响应
+func (g *Gateway) create(orderType pb.ORDER_TYPE, merchantNo string, amount int64, pid int32) *pb.PayRpcResp {
+	if merchantNo == "" || pid <= 0 || (orderType != pb.ORDER_TYPE_VIRTUAL && amount <= 0) {
+		return &pb.PayRpcResp{Code: http.StatusBadRequest, Message: "invalid param"}
+	}
+	g.mu.Lock()
+	_, exists := g.orders[merchantNo]
+	g.mu.Unlock()
+	if exists {
+		return &pb.PayRpcResp{Code: http.StatusConflict, Message: "duplicate order"}
+	}
+	g.SetOrder(orderType, &pb.OrderQueryResp{
+		OrderNo:    "SIM" + merchantNo,
+		MerchantNo: merchantNo,
+		Amount:     amount,
+		Status:     pb.ORDER_STATUS_WAIT,
+	})
+	return nil
+}
+
+func (g *Gateway) channel(param *pb.PayRpcParam) *pb.PayRpcResp {
+	var req pb.ChannelQueryParam
+	if resp := g.decode(param, &req); resp != nil {
+		return resp
+	}
+	channels := make([]*pb.ChannelQueryResp, 0, len(g.Channels))
+	for _, channel := range g.Channels {
+		if req.OrderType == pb.ORDER_TYPE_ALL || channel.Type == int32(req.OrderType) {
+			channels = append(channels, channel)
+		}
+	}
+	return g.encode(channels)
+}
+
+// balance 余额查询不携带参数，只校验 app_key
+func (g *Gateway) balance(param *pb.PayRpcParam) *pb.PayRpcResp {
+	if param.AppKey != g.appKey {
+		return &pb.PayRpcResp{Code: http.StatusUnauthorized, Message: "invalid app key"}
+	}
+	return g.encode(g.Balance)
+}
+
+func (g *Gateway) query(orderType pb.ORDER_TYPE, param *pb.PayRpcParam) *pb.PayRpcResp {
+	var req pb.OrderQueryParam
+	if resp := g.decode(param, &req); resp != nil {
+		return resp
+	}
+	g.mu.Lock()
+	order, ok := g.orders[req.MerchantNo]
+	g.mu.Unlock()
+	if !ok || order.orderType != orderType {
+		return &pb.PayRpcResp{Code: http.StatusNotFound, Message: "order not found"}
+	}
+	return g.encode(order.resp)
+}
+
+func (g *Gateway) batchQuery(param *pb.PayRpcParam) *pb.PayRpcResp {
+	var req pb.BatchQueryParam
+	if resp := g.decode(param, &req); resp != nil {
+		return resp
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	result := &pb.BatchQueryResp{}
+	for _, merchantNo := range req.MerchantNos {
+		item := &pb.BatchQueryItem{MerchantNo: merchantNo, Code: http.StatusOK}
+		if order, ok := g.orders[merchantNo]; ok && order.orderType == req.OrderType {
+			item.Order = order.resp
+		} else {
+			item.Code, item.Message = http.StatusNotFound, "order not found"
+		}
+		result.Items = append(result.Items, item)
+	}
+	return g.encode(result)
+}
+
+// listOrders 按创建时间和商户订单号排序分页，游标为偏移量，不按通道过滤
+func (g *Gateway) listOrders(param *pb.PayRpcParam) *pb.PayRpcResp {
+	var req pb.ListOrdersParam
+	if resp := g.decode(param, &req); resp != nil {
+		return resp
+	}
+	offset := 0
+	if req.Cursor != "" {
+		var err error
+		if offset, err = strconv.Atoi(req.Cursor); err != nil {
+			return &pb.PayRpcResp{Code: http.StatusBadRequest, Message: "invalid cursor"}
+		}
+	}
+
+	g.mu.Lock()
+	var items []*pb.OrderListItem
+	for _, order := range g.orders {
+		if req.OrderType != pb.ORDER_TYPE_ALL && order.orderType != req.OrderType {
+			continue
+		}
+		if len(req.Status) > 0 && !slices.Contains(req.Status, order.resp.Status) {
+			continue
+		}
+		if order.createTime < req.StartTime || (req.EndTime > 0 && order.createTime >= req.EndTime) {
+			continue
+		}
+		items = append(items, &pb.OrderListItem{
+			OrderNo:    order.resp.OrderNo,
+			MerchantNo: order.resp.MerchantNo,
+			OrderType:  order.orderType,
+			Amount:     order.resp.Amount,
+			Fee:        order.resp.Fee,
+			Status:     order.resp.Status,
+			CreateTime: order.createTime,
+			UpdateTime: order.resp.UpdateTime,
+		})
+	}
+	g.mu.Unlock()
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].CreateTime != items[j].CreateTime {
+			return items[i].CreateTime < items[j].CreateTime
+		}
+		return items[i].MerchantNo < items[j].MerchantNo
+	})
+
+	result := &pb.ListOrdersResp{}
+	if offset < len(items) {
+		end := min(offset+int(max(req.Limit, 1)), len(items))
+		result.Orders = items[offset:end]
+		if end < len(items) {
+			result.NextCursor = strconv.Itoa(end)
+		}
+	}
+	return g.encode(result)
+}
+
+func (g *Gateway) refund(param *pb.PayRpcParam) *pb.PayRpcResp {
+	var req pb.RefundParam
+	if resp := g.decode(param, &req); resp != nil {
+		return resp
+	}
+	g.mu.Lock()
+	order, ok := g.orders[req.MerchantNo]
+	g.mu.Unlock()
+	if !ok || order.orderType != pb.ORDER_TYPE_RECEIVE {
+		return &pb.PayRpcResp{Code: http.StatusNotFound, Message: "order not found"}
+	}
+	if order.resp.Status != pb.ORDER_STATUS_SUCCESS || req.Amount <= 0 || req.Amount > order.resp.Amount {
+		return &pb.PayRpcResp{Code: http.StatusBadRequest, Message: "order cannot be refunded"}
+	}
+	return g.encode(&pb.RefundResp{
+		OrderNo:    "SIM" + req.RefundNo,
+		MerchantNo: req.MerchantNo,
+		RefundNo:   req.RefundNo,
+		Amount:     req.Amount,
+		Status:     pb.ORDER_STATUS_REFUNDING,
+	})
+}
+
+func (g *Gateway) cancel(param *pb.PayRpcParam) *pb.PayRpcResp {
+	var req pb.CancelParam
+	if resp := g.decode(param, &req); resp != nil {
+		return resp
+	}
+	g.mu.Lock()
+	order, ok := g.orders[req.MerchantNo]
+	g.mu.Unlock()
+	if !ok || order.orderType != pb.ORDER_TYPE_OUT {
+		return &pb.PayRpcResp{Code: http.StatusNotFound, Message: "order not found"}
+	}
+	if order.resp.Status != pb.ORDER_STATUS_WAIT {
+		return &pb.PayRpcResp{Code: http.StatusBadRequest, Message: "order cannot be canceled"}
+	}
+	canceled := proto.Clone(order.resp).(*pb.OrderQueryResp)
+	canceled.Status = pb.ORDER_STATUS_CANCELED
+	canceled.UpdateTime = 0
+	g.SetOrder(pb.ORDER_TYPE_OUT, canceled)
+	return g.encode(&pb.CancelResp{OrderNo: canceled.OrderNo, MerchantNo: canceled.MerchantNo, Status: canceled.Status})
+}
+
+// decode 校验 app_key 并解密请求参数，失败时返回错误响应
+func (g *Gateway) decode(param *pb.PayRpcParam, v interface{}) *pb.PayRpcResp {
+	if param.AppKey != g.appKey {
+		return &pb.PayRpcResp{Code: http.StatusUnauthorized, Message: "invalid app key"}
+	}
+	data, err := g.aes.Decrypt([]byte(param.Data))
+	if err == nil {
+		err = json.Unmarshal(data, v)
+	}
+	if err != nil {
+		return &pb.PayRpcResp{Code: http.StatusBadRequest, Message: "invalid param"}
+	}
+	return nil
+}
+
+func (g *Gateway) encode(v interface{}) *pb.PayRpcResp {
+	data, _ := json.Marshal(v)
+	encrypt, err := g.aes.Encrypt(data)
+	if err != nil {
+		return &pb.PayRpcResp{Code: http.StatusInternalServerError, Message: err.Error()}
+	}
+	return &pb.PayRpcResp{Code: http.StatusOK, Message: "success", Data: encrypt}
+}
@@ -0,0 +1,130 @@
+package simulator
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+func newGatewayClient(t *testing.T) (*Gateway, *xmpay.HttpClient) {
+	t.Helper()
+	g := NewGateway(testConfig(""))
+	server := httptest.NewServer(g)
+	t.Cleanup(server.Close)
+	return g, xmpay.NewHttpClient(testConfig(server.URL), testLogger())
+}
+
+func apiCode(err error) int {
+	var e *xmpay.ApiError
+	if errors.As(err, &e) {
+		return int(e.Code)
+	}
+	return 0
+}
+
+func TestGatewayQuery(t *testing.T) {
+	g, c := newGatewayClient(t)
+	event := g.SetOrder(pb.ORDER_TYPE_RECEIVE, &pb.OrderQueryResp{OrderNo: "T1", MerchantNo: "M1", Amount: 100, Status: pb.ORDER_STATUS_SUCCESS})
+	if event.Cursor != "1" || event.UpdateTime == 0 {
+		t.Errorf("event = %+v", event)
+	}
+
+	resp, err := c.QueryReceive("M1", "")
+	if err != nil || resp.OrderNo != "T1" || resp.Status != pb.ORDER_STATUS_SUCCESS {
+		t.Fatalf("resp = %+v, err = %v", resp, err)
+	}
+	// 订单类型不符视为不存在
+	if _, err = c.QueryOut("M1", ""); apiCode(err) != http.StatusNotFound {
+		t.Errorf("query out, err = %v", err)
+	}
+
+	// app_key 不符时拒绝
+	other := testConfig(c.CurrentConfig().ApiUrl)
+	other.AccessId = "abcdefabcdefabcd"
+	if _, err = xmpay.NewHttpClient(other, testLogger()).QueryReceive("M1", ""); apiCode(err) != http.StatusUnauthorized {
+		t.Errorf("wrong app key, err = %v", err)
+	}
+}
+
+func TestGatewayListOrders(t *testing.T) {
+	g, c := newGatewayClient(t)
+	for i, no := range []string{"M1", "M2", "M3"} {
+		g.SetOrder(pb.ORDER_TYPE_OUT, &pb.OrderQueryResp{MerchantNo: no, Status: pb.ORDER_STATUS_SUCCESS, UpdateTime: int64(1000 + i)})
+	}
+	g.SetOrder(pb.ORDER_TYPE_RECEIVE, &pb.OrderQueryResp{MerchantNo: "R1", Status: pb.ORDER_STATUS_SUCCESS, UpdateTime: 999})
+
+	it := c.Orders(&xmpay.ListParam{OrderType: pb.ORDER_TYPE_OUT, Limit: 2})
+	var got []string
+	for it.Next() {
+		got = append(got, it.Order().MerchantNo)
+	}
+	if it.Err() != nil || len(got) != 3 || got[0] != "M1" || got[2] != "M3" {
+		t.Errorf("orders = %v, err = %v", got, it.Err())
+	}
+}
+
+func TestGatewayRefundAndCancel(t *testing.T) {
+	g, c := newGatewayClient(t)
+	g.SetOrder(pb.ORDER_TYPE_RECEIVE, &pb.OrderQueryResp{MerchantNo: "M1", Amount: 100, Status: pb.ORDER_STATUS_SUCCESS})
+	g.SetOrder(pb.ORDER_TYPE_OUT, &pb.OrderQueryResp{MerchantNo: "O1", Amount: 100, Status: pb.ORDER_STATUS_WAIT})
+
+	refund, err := c.Refund(&xmpay.RefundParam{MerchantNo: "M1", RefundNo: "RF1", Amount: 50})
+	if err != nil || refund.Status != pb.ORDER_STATUS_REFUNDING || refund.RefundNo != "RF1" {
+		t.Fatalf("refund = %+v, err = %v", refund, err)
+	}
+	if _, err = c.Refund(&xmpay.RefundParam{MerchantNo: "M1", RefundNo: "RF2", Amount: 500}); apiCode(err) != http.StatusBadRequest {
+		t.Errorf("refund over amount, err = %v", err)
+	}
+
+	cancel, err := c.Cancel(&xmpay.CancelParam{MerchantNo: "O1"})
+	if err != nil || cancel.Status != pb.ORDER_STATUS_CANCELED {
+		t.Fatalf("cancel = %+v, err = %v", cancel, err)
+	}
+	if resp, _ := c.QueryOut("O1", ""); resp.GetStatus() != pb.ORDER_STATUS_CANCELED {
+		t.Errorf("status after cancel = %v", resp.GetStatus())
+	}
+	// 非 WAIT 状态不能取消
+	if _, err = c.Cancel(&xmpay.CancelParam{MerchantNo: "O1", IdempotencyKey: "again"}); apiCode(err) != http.StatusBadRequest {
+		t.Errorf("cancel twice, err = %v", err)
+	}
+}
+
+func TestGatewayDisableBatch(t *testing.T) {
+	g, c := newGatewayClient(t)
+	g.DisableBatch = true
+	g.SetOrder(pb.ORDER_TYPE_RECEIVE, &pb.OrderQueryResp{MerchantNo: "M1", Status: pb.ORDER_STATUS_SUCCESS})
+
+	// 批量查询不可用时客户端逐个查询
+	results, err := c.BatchQuery(pb.ORDER_TYPE_RECEIVE, []string{"M1", "M2"})
+	if err != nil || results["M1"].Order == nil || results["M2"].Err == nil {
+		t.Errorf("results = %+v, err = %v", results, err)
+	}
+}
+
+func TestGatewayCreate(t *testing.T) {
+	_, c := newGatewayClient(t)
+	resp, err := c.CreateOut(&xmpay.OutParam{OrderParam: xmpay.OrderParam{OrderNo: "O1", Amount: 100}, BankNo: "6222"})
+	if err != nil || resp.MerchantNo != "O1" {
+		t.Fatalf("resp = %+v, err = %v", resp, err)
+	}
+	order, err := c.QueryOut("O1", "")
+	if err != nil || order.OrderNo != resp.OrderNo || order.Status != pb.ORDER_STATUS_WAIT {
+		t.Fatalf("order = %+v, err = %v", order, err)
+	}
+	// 重复的商户订单号
+	if _, err = c.CreateOut(&xmpay.OutParam{OrderParam: xmpay.OrderParam{OrderNo: "O1", Amount: 100}, BankNo: "6222"}); apiCode(err) != http.StatusConflict {
+		t.Errorf("duplicate, err = %v", err)
+	}
+
+	channels, err := c.Channel(pb.ORDER_TYPE_RECEIVE)
+	if err != nil || len(channels) != 1 || channels[0].Type != int32(pb.ORDER_TYPE_RECEIVE) {
+		t.Errorf("channels = %v, err = %v", channels, err)
+	}
+	if balance, err := c.Balance(); err != nil || balance.Available == 0 {
+		t.Errorf("balance = %v, err = %v", balance, err)
+	}
+}
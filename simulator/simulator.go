@@ -0,0 +1,211 @@
+// Package simulator 模拟 XMPAY 网关向本地回调地址推送回调，并提供订单查询与订阅的模拟网关，用于本地开发调试和测试
+package simulator
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+// Simulator 回调模拟器，按网关相同的方式加密回调参数并推送
+type Simulator struct {
+	appKey    string
+	aes       *xmpay.AES
+	notifyUrl string
+	client    *http.Client
+}
+
+// Step 场景中的一次推送
+type Step struct {
+	Name     string            // 步骤名称
+	Callback *pb.CallbackParam // 回调参数
+	AppKey   string            // 非空时覆盖 app_key
+	Tamper   bool              // 是否篡改密文
+	Expect   string            // 期望的应答内容，为空时不校验
+}
+
+// Result 推送结果
+type Result struct {
+	Step       string `json:"step"`
+	StatusCode int    `json:"statusCode"`
+	Body       string `json:"body"`
+	Expect     string `json:"expect,omitempty"`
+	Passed     bool   `json:"passed"`
+	Error      string `json:"error,omitempty"`
+}
+
+// New 创建回调模拟器，使用配置中的 AccessId/AccessKey 加密
+func New(config *xmpay.Config, notifyUrl string) *Simulator {
+	return &Simulator{
+		appKey:    config.AccessId,
+		aes:       xmpay.NewAES([]byte(config.AccessId), []byte(config.AccessKey)),
+		notifyUrl: notifyUrl,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Callback 构造回调参数，终态时结束时间为当前时间
+func Callback(merchantNo string, status pb.ORDER_STATUS, amount int64) *pb.CallbackParam {
+	cb := &pb.CallbackParam{
+		OrderNo:    "SIM" + merchantNo,
+		MerchantNo: merchantNo,
+		RealAmount: amount,
+		Status:     status,
+		Remark:     "simulator",
+	}
+	if isFinal(status) {
+		cb.FinishTime = time.Now().Unix()
+	}
+	return cb
+}
+
+// RefundCallback 构造退款回调参数，merchantNo 为原收款单号
+func RefundCallback(merchantNo, refundNo string, status pb.ORDER_STATUS, amount int64) *pb.CallbackParam {
+	cb := Callback(merchantNo, status, 0)
+	cb.OrderNo = "SIM" + refundNo
+	cb.RefundNo = refundNo
+	cb.RefundAmount = amount
+	return cb
+}
+
+func isFinal(status pb.ORDER_STATUS) bool {
+	switch status {
+	case pb.ORDER_STATUS_SUCCESS, pb.ORDER_STATUS_FAILURE, pb.ORDER_STATUS_REFUNDED, pb.ORDER_STATUS_CANCELED:
+		return true
+	}
+	return false
+}
+
+// Encode 按网关格式生成回调请求体
+func (s *Simulator) Encode(cb *pb.CallbackParam) ([]byte, error) {
+	return s.encode(Step{Callback: cb})
+}
+
+// Send 推送一次回调
+func (s *Simulator) Send(cb *pb.CallbackParam) (*Result, error) {
+	return s.send(Step{Name: cb.Status.String(), Callback: cb})
+}
+
+// Run 依次执行场景中的推送
+func (s *Simulator) Run(steps []Step) ([]*Result, error) {
+	results := make([]*Result, 0, len(steps))
+	for _, step := range steps {
+		r, err := s.send(step)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+func (s *Simulator) encode(step Step) ([]byte, error) {
+	data, err := json.Marshal(step.Callback)
+	if err != nil {
+		return nil, err
+	}
+	encrypt, err := s.aes.Encrypt(data)
+	if err != nil {
+		return nil, err
+	}
+	if step.Tamper {
+		encrypt = tamper(encrypt)
+	}
+	appKey := s.appKey
+	if step.AppKey != "" {
+		appKey = step.AppKey
+	}
+	return json.Marshal(&pb.PayRpcParam{AppKey: appKey, Data: encrypt})
+}
+
+func (s *Simulator) send(step Step) (*Result, error) {
+	body, err := s.encode(step)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Step: step.Name, Expect: step.Expect}
+
+	resp, err := s.client.Post(s.notifyUrl, "application/json", bytes.NewReader(body))
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result.StatusCode = resp.StatusCode
+	result.Body = string(respBody)
+	result.Passed = step.Expect == "" || result.Body == step.Expect
+	return result, nil
+}
+
+// tamper 翻转首个密文字节，保持十六进制格式不变
+func tamper(encrypt string) string {
+	data, err := hex.DecodeString(encrypt)
+	if err != nil || len(data) == 0 {
+		return encrypt
+	}
+	data[0] ^= 0xff
+	return hex.EncodeToString(data)
+}
+
+// 内置场景名称
+const (
+	ScenarioNormal      = "normal"       // PROCESSING 后 SUCCESS
+	ScenarioDuplicate   = "duplicate"    // 同一 SUCCESS 回调重复推送
+	ScenarioOutOfOrder  = "out-of-order" // SUCCESS 后推送 PROCESSING
+	ScenarioWrongAppKey = "wrong-app-key"
+	ScenarioTampered    = "tampered"
+)
+
+// Scenarios 内置场景名称列表
+var Scenarios = []string{ScenarioNormal, ScenarioDuplicate, ScenarioOutOfOrder, ScenarioWrongAppKey, ScenarioTampered}
+
+var ErrUnknownScenario = errors.New("unknown scenario")
+
+// Scenario 按名称生成内置场景的推送步骤
+func Scenario(name, merchantNo string, amount int64) ([]Step, error) {
+	processing := Callback(merchantNo, pb.ORDER_STATUS_PROCESSING, amount)
+	success := Callback(merchantNo, pb.ORDER_STATUS_SUCCESS, amount)
+
+	switch name {
+	case ScenarioNormal:
+		return []Step{
+			{Name: "processing", Callback: processing, Expect: xmpay.CallbackSuccess},
+			{Name: "success", Callback: success, Expect: xmpay.CallbackSuccess},
+		}, nil
+	case ScenarioDuplicate:
+		return []Step{
+			{Name: "success", Callback: success, Expect: xmpay.CallbackSuccess},
+			{Name: "success duplicate 1", Callback: success, Expect: xmpay.CallbackSuccess},
+			{Name: "success duplicate 2", Callback: success, Expect: xmpay.CallbackSuccess},
+		}, nil
+	case ScenarioOutOfOrder:
+		return []Step{
+			{Name: "success", Callback: success, Expect: xmpay.CallbackSuccess},
+			{Name: "processing after success", Callback: processing, Expect: xmpay.CallbackSuccess},
+		}, nil
+	case ScenarioWrongAppKey:
+		return []Step{
+			{Name: "wrong app key", Callback: success, AppKey: "simulator-wrong-app-key", Expect: xmpay.CallbackFail},
+		}, nil
+	case ScenarioTampered:
+		return []Step{
+			{Name: "tampered ciphertext", Callback: success, Tamper: true, Expect: xmpay.CallbackFail},
+		}, nil
+	}
+	return nil, ErrUnknownScenario
+}
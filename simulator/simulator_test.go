@@ -0,0 +1,117 @@
+package simulator
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	testAccessId  = "0123456789abcdef"
+	testAccessKey = "fedcba9876543210"
+)
+
+func testLogger() *logrus.Entry {
+	l := logrus.New()
+	l.Out = io.Discard
+	return logrus.NewEntry(l)
+}
+
+func testConfig(apiUrl string) *xmpay.Config {
+	return &xmpay.Config{ApiUrl: apiUrl, AccessId: testAccessId, AccessKey: testAccessKey, InId: "7", OutId: "8"}
+}
+
+// newNotifyServer 以真实的 CallbackDispatcher 处理回调，calls 记录各商户订单号的处理次数
+func newNotifyServer(t *testing.T, config *xmpay.Config) (*httptest.Server, map[string]int) {
+	t.Helper()
+	client := xmpay.NewHttpClient(config, testLogger())
+	dispatcher := xmpay.NewCallbackDispatcher(client, xmpay.NewMemoryCallbackStore(), testLogger())
+	calls := map[string]int{}
+	dispatcher.Handle(func(cb *pb.CallbackParam) error {
+		calls[cb.MerchantNo+"|"+cb.Status.String()]++
+		return nil
+	})
+	server := httptest.NewServer(dispatcher)
+	t.Cleanup(server.Close)
+	return server, calls
+}
+
+func TestScenarios(t *testing.T) {
+	for _, name := range Scenarios {
+		server, _ := newNotifyServer(t, testConfig("http://127.0.0.1:1"))
+		steps, err := Scenario(name, "M1", 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results, err := New(testConfig(""), server.URL).Run(steps)
+		if err != nil || len(results) != len(steps) {
+			t.Fatalf("%s: results = %d, err = %v", name, len(results), err)
+		}
+		for _, r := range results {
+			if !r.Passed || r.Error != "" {
+				t.Errorf("%s: %+v", name, r)
+			}
+		}
+	}
+	if _, err := Scenario("unknown", "M1", 100); !errors.Is(err, ErrUnknownScenario) {
+		t.Errorf("unknown scenario, err = %v", err)
+	}
+}
+
+func TestDuplicateHandledOnce(t *testing.T) {
+	server, calls := newNotifyServer(t, testConfig("http://127.0.0.1:1"))
+	steps, _ := Scenario(ScenarioDuplicate, "M1", 100)
+	if _, err := New(testConfig(""), server.URL).Run(steps); err != nil {
+		t.Fatal(err)
+	}
+	if calls["M1|SUCCESS"] != 1 {
+		t.Errorf("calls = %v", calls)
+	}
+}
+
+func TestEmptyAccessKey(t *testing.T) {
+	// accessKey 为空时客户端与模拟器都以 accessId 作为 IV
+	config := testConfig("http://127.0.0.1:1")
+	config.AccessKey = ""
+	server, calls := newNotifyServer(t, config)
+
+	r, err := New(config, server.URL).Send(Callback("M1", pb.ORDER_STATUS_SUCCESS, 100))
+	if err != nil || r.StatusCode != http.StatusOK || r.Body != xmpay.CallbackSuccess || calls["M1|SUCCESS"] != 1 {
+		t.Errorf("result = %+v, err = %v, calls = %v", r, err, calls)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	client := xmpay.NewHttpClient(testConfig("http://127.0.0.1:1"), testLogger())
+	cb := RefundCallback("M1", "R1", pb.ORDER_STATUS_REFUNDED, 50)
+	body, err := New(testConfig(""), "").Encode(cb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.ParseCallback(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.MerchantNo != "M1" || got.RefundNo != "R1" || got.RefundAmount != 50 || got.FinishTime == 0 {
+		t.Errorf("callback = %+v", got)
+	}
+	// 非终态没有结束时间
+	if cb = Callback("M1", pb.ORDER_STATUS_PROCESSING, 0); cb.FinishTime != 0 {
+		t.Errorf("finish time = %d", cb.FinishTime)
+	}
+}
+
+func TestSendUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	r, err := New(testConfig(""), server.URL).Send(Callback("M1", pb.ORDER_STATUS_SUCCESS, 100))
+	if err != nil || r.Error == "" || r.Passed {
+		t.Errorf("result = %+v, err = %v", r, err)
+	}
+}
@@ -0,0 +1,74 @@
+package xmpay
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Placeholder SQL 参数占位符风格
+type Placeholder int
+
+const (
+	Question Placeholder = iota // ?，MySQL、SQLite
+	Dollar                      // $1，PostgreSQL
+)
+
+// sqlExecutor *sql.DB 与 *sql.Tx 的公共方法
+type sqlExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// rebind 将语句中的 ? 替换为对应风格的占位符
+func (p Placeholder) rebind(query string) string {
+	if p != Dollar {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func unixMilli(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).In(Location())
+}
+
+// isUniqueViolation 是否为唯一约束冲突。驱动实现 SQLState() 时按 SQLSTATE 23505 判断，
+// 否则按 MySQL、PostgreSQL、SQLite 驱动的错误信息判断
+func isUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) {
+		return state.SQLState() == "23505"
+	}
+	msg := err.Error()
+	for _, s := range []string{
+		"Error 1062",                   // MySQL ER_DUP_ENTRY
+		"duplicate key value violates", // PostgreSQL
+		"SQLSTATE 23505",
+		"UNIQUE constraint failed", // SQLite
+		"PRIMARY KEY constraint failed",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package xmpay
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+// TimeUnit 网关时间戳单位
+type TimeUnit int32
+
+const (
+	TimeUnitAuto   TimeUnit = iota // 按数值大小识别秒、毫秒、微秒或纳秒
+	TimeUnitSecond                 // 秒
+	TimeUnitMilli                  // 毫秒
+)
+
+// DefaultTimezone 默认业务时区
+const DefaultTimezone = "Asia/Shanghai"
+
+// ErrTimeUnitAuto 时间戳单位为自动识别，无法确定发送给网关的时间戳单位
+var ErrTimeUnitAuto = errors.New("time unit is auto, set WithTimeUnit to send timestamps")
+
+var defaultLocation = loadLocation(DefaultTimezone)
+
+// loadLocation 加载时区，系统缺少时区数据时 Asia/Shanghai 回退为固定的东八区
+func loadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.FixedZone("CST", 8*60*60)
+	}
+	return loc
+}
+
+// TimeSettings 网关时间戳单位和业务时区，通过 WithTimeUnit、WithLocation 按客户端设置，
+// 零值为自动识别单位、DefaultTimezone 时区
+type TimeSettings struct {
+	Unit     TimeUnit
+	Location *time.Location
+}
+
+func (s TimeSettings) location() *time.Location {
+	if s.Location == nil {
+		return defaultLocation
+	}
+	return s.Location
+}
+
+// ParseTimestamp 将网关时间戳转换为业务时区的时间，0 返回零值
+func (s TimeSettings) ParseTimestamp(ts int64) time.Time {
+	if ts == 0 {
+		return time.Time{}
+	}
+	var t time.Time
+	switch s.Unit {
+	case TimeUnitSecond:
+		t = time.Unix(ts, 0)
+	case TimeUnitMilli:
+		t = time.UnixMilli(ts)
+	default:
+		t = parseAuto(ts)
+	}
+	return t.In(s.location())
+}
+
+// ParseTimestampString 解析十进制字符串形式的网关时间戳，空字符串和 "0" 返回零值
+func (s TimeSettings) ParseTimestampString(ts string) (time.Time, error) {
+	ts = strings.TrimSpace(ts)
+	if ts == "" {
+		return time.Time{}, nil
+	}
+	n, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: %w", ts, err)
+	}
+	return s.ParseTimestamp(n), nil
+}
+
+// FormatTimestamp 将时间转换为网关时间戳，零值返回 0；单位为自动识别时无法确定网关的单位，返回 ErrTimeUnitAuto
+func (s TimeSettings) FormatTimestamp(t time.Time) (int64, error) {
+	if t.IsZero() {
+		return 0, nil
+	}
+	switch s.Unit {
+	case TimeUnitSecond:
+		return t.Unix(), nil
+	case TimeUnitMilli:
+		return t.UnixMilli(), nil
+	default:
+		return 0, ErrTimeUnitAuto
+	}
+}
+
+// Now 返回业务时区的当前时间
+func (s TimeSettings) Now() time.Time {
+	return time.Now().In(s.location())
+}
+
+// parseAuto 按数量级识别单位：1e11 秒约为 5138 年，1e11 毫秒约为 1973 年
+func parseAuto(ts int64) time.Time {
+	abs := ts
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs < 1e11:
+		return time.Unix(ts, 0)
+	case abs < 1e14:
+		return time.UnixMilli(ts)
+	case abs < 1e17:
+		return time.UnixMicro(ts)
+	default:
+		return time.Unix(0, ts)
+	}
+}
+
+// timesOf 返回客户端的时间设置，未实现 Times 的客户端使用默认设置
+func timesOf(client interface{}) TimeSettings {
+	if c, ok := client.(interface{ Times() TimeSettings }); ok {
+		return c.Times()
+	}
+	return TimeSettings{}
+}
+
+// Location 返回默认业务时区
+func Location() *time.Location {
+	return defaultLocation
+}
+
+// ParseTimestamp 按默认设置将网关时间戳转换为时间，0 返回零值
+func ParseTimestamp(ts int64) time.Time {
+	return TimeSettings{}.ParseTimestamp(ts)
+}
+
+// Now 返回默认业务时区的当前时间
+func Now() time.Time {
+	return time.Now().In(defaultLocation)
+}
+
+// OrderUpdateTime 按默认设置返回订单查询结果的更新时间，设置了时间选项的客户端使用 Times().ParseTimestamp
+func OrderUpdateTime(resp *pb.OrderQueryResp) time.Time {
+	return ParseTimestamp(resp.GetUpdateTime())
+}
+
+// CallbackFinishTime 按默认设置返回回调的订单完成时间
+func CallbackFinishTime(cb *pb.CallbackParam) time.Time {
+	return ParseTimestamp(cb.GetFinishTime())
+}
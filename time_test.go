@@ -0,0 +1,110 @@
+package xmpay
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseAuto(t *testing.T) {
+	want := time.Date(2024, 5, 1, 8, 30, 15, 0, time.UTC)
+	tests := []struct {
+		name string
+		ts   int64
+		want time.Time
+	}{
+		{"seconds", want.Unix(), want},
+		{"milliseconds", want.UnixMilli() + 123, want.Add(123 * time.Millisecond)},
+		{"microseconds", want.UnixMicro(), want},
+		{"nanoseconds", want.UnixNano(), want},
+		{"seconds before 1973", 1e8, time.Unix(1e8, 0)},
+		{"negative seconds", -want.Unix(), time.Unix(-want.Unix(), 0)},
+	}
+	for _, tt := range tests {
+		if got := parseAuto(tt.ts); !got.Equal(tt.want) {
+			t.Errorf("%s: parseAuto(%d) = %v, want %v", tt.name, tt.ts, got, tt.want)
+		}
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	utc8 := time.FixedZone("UTC+8", 8*60*60)
+	want := time.Date(2024, 5, 1, 8, 30, 15, 0, utc8)
+	tests := []struct {
+		name     string
+		settings TimeSettings
+		ts       int64
+		want     time.Time
+	}{
+		{"zero", TimeSettings{}, 0, time.Time{}},
+		{"auto seconds", TimeSettings{}, want.Unix(), want},
+		{"auto milliseconds", TimeSettings{}, want.UnixMilli(), want},
+		{"fixed seconds", TimeSettings{Unit: TimeUnitSecond}, want.Unix(), want},
+		{"fixed milliseconds", TimeSettings{Unit: TimeUnitMilli}, want.UnixMilli(), want},
+		// 固定单位不按数量级识别
+		{"milliseconds as seconds", TimeSettings{Unit: TimeUnitMilli}, want.Unix(), time.UnixMilli(want.Unix())},
+		{"location", TimeSettings{Location: utc8}, want.Unix(), want},
+	}
+	for _, tt := range tests {
+		got := tt.settings.ParseTimestamp(tt.ts)
+		if !got.Equal(tt.want) || got.IsZero() != tt.want.IsZero() {
+			t.Errorf("%s: ParseTimestamp(%d) = %v, want %v", tt.name, tt.ts, got, tt.want)
+		}
+		if !got.IsZero() && got.Location() != tt.settings.location() {
+			t.Errorf("%s: location = %v, want %v", tt.name, got.Location(), tt.settings.location())
+		}
+	}
+	if got := ParseTimestamp(want.Unix()); got.Location() != Location() || !got.Equal(want) {
+		t.Errorf("default ParseTimestamp = %v", got)
+	}
+}
+
+func TestParseTimestampString(t *testing.T) {
+	want := time.Date(2024, 5, 1, 8, 30, 15, 0, time.UTC)
+	tests := []struct {
+		ts      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"0", time.Time{}, false},
+		{" 1714552215 ", want, false},
+		{"1714552215000", want, false},
+		{"2024-05-01", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := TimeSettings{}.ParseTimestampString(tt.ts)
+		if (err != nil) != tt.wantErr || !got.Equal(tt.want) {
+			t.Errorf("ParseTimestampString(%q) = %v, %v, want %v", tt.ts, got, err, tt.want)
+		}
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 8, 30, 15, 0, time.UTC)
+	if got, err := (TimeSettings{Unit: TimeUnitSecond}).FormatTimestamp(ts); err != nil || got != ts.Unix() {
+		t.Errorf("seconds = %d, %v", got, err)
+	}
+	if got, err := (TimeSettings{Unit: TimeUnitMilli}).FormatTimestamp(ts); err != nil || got != ts.UnixMilli() {
+		t.Errorf("milliseconds = %d, %v", got, err)
+	}
+	if got, err := (TimeSettings{}).FormatTimestamp(time.Time{}); err != nil || got != 0 {
+		t.Errorf("zero = %d, %v", got, err)
+	}
+	// 自动识别时不猜测网关的单位
+	if _, err := (TimeSettings{}).FormatTimestamp(ts); !errors.Is(err, ErrTimeUnitAuto) {
+		t.Errorf("auto err = %v", err)
+	}
+}
+
+func TestClientTimeOptions(t *testing.T) {
+	loc := time.FixedZone("UTC+7", 7*60*60)
+	client := NewHttpClient(testConfig("http://127.0.0.1:1"), testLogger(), WithTimeUnit(TimeUnitSecond), WithLocation(loc))
+	if times := client.Times(); times.Unit != TimeUnitSecond || times.Now().Location() != loc {
+		t.Errorf("times = %+v", times)
+	}
+	// 其他客户端不受影响
+	if times := NewHttpClient(testConfig("http://127.0.0.1:1"), testLogger()).Times(); times.Unit != TimeUnitAuto || times.location() != Location() {
+		t.Errorf("default times = %+v", times)
+	}
+}
@@ -0,0 +1,239 @@
+package xmpay
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrVirtualAccountNotFound = errors.New("virtual account not found")
+	ErrVirtualAccountUid      = errors.New("virtual account uid is empty")
+)
+
+// VirtualAccount 用户的虚拟账户
+type VirtualAccount struct {
+	Uid         string    `json:"uid"`
+	OrderNo     string    `json:"orderNo"`    // 平台订单号
+	MerchantNo  string    `json:"merchantNo"` // 创建虚拟账户时的商户订单号
+	AccountName string    `json:"accountName"`
+	AccountNo   string    `json:"accountNo"`
+	PayUrl      string    `json:"payUrl"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// VirtualAccountStore 虚拟账户存储，每个 Uid 对应一个虚拟账户
+type VirtualAccountStore interface {
+	// Save 保存虚拟账户，Uid 已存在时覆盖
+	Save(account *VirtualAccount) error
+	// Get 按 Uid 查询，不存在时返回 ErrVirtualAccountNotFound
+	Get(uid string) (*VirtualAccount, error)
+	// GetByMerchantNo 按创建时的商户订单号查询，不存在时返回 ErrVirtualAccountNotFound。
+	// 账户被覆盖后，原商户订单号在保留期内仍可查到该 Uid 的账户
+	GetByMerchantNo(merchantNo string) (*VirtualAccount, error)
+}
+
+// DefaultRetainMerchantNo 虚拟账户被覆盖后原商户订单号的默认保留时长，保留期内原账户的回调仍可关联到用户
+const DefaultRetainMerchantNo = 24 * time.Hour
+
+// MemoryVirtualAccountStore 基于内存的虚拟账户存储
+type MemoryVirtualAccountStore struct {
+	RetainMerchantNo time.Duration // 账户被覆盖后原商户订单号的保留时长，0 表示立即删除
+
+	mu       sync.RWMutex
+	accounts map[string]*VirtualAccount
+	merchant map[string]string
+	previous map[string]previousMerchantNo // 按 Uid 记录被覆盖账户的商户订单号
+}
+
+type previousMerchantNo struct {
+	merchantNo string
+	expireAt   time.Time
+}
+
+func NewMemoryVirtualAccountStore() *MemoryVirtualAccountStore {
+	return &MemoryVirtualAccountStore{
+		RetainMerchantNo: DefaultRetainMerchantNo,
+		accounts:         make(map[string]*VirtualAccount),
+		merchant:         make(map[string]string),
+		previous:         make(map[string]previousMerchantNo),
+	}
+}
+
+func (s *MemoryVirtualAccountStore) Save(account *VirtualAccount) error {
+	if account.Uid == "" {
+		return ErrVirtualAccountUid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, ok := s.accounts[account.Uid]; ok && old.MerchantNo != account.MerchantNo {
+		// 每个 Uid 只保留最近一次被覆盖的商户订单号
+		if prev, ok := s.previous[account.Uid]; ok && s.merchant[prev.merchantNo] == account.Uid {
+			delete(s.merchant, prev.merchantNo)
+		}
+		delete(s.previous, account.Uid)
+		if old.MerchantNo != "" && s.RetainMerchantNo > 0 {
+			s.previous[account.Uid] = previousMerchantNo{merchantNo: old.MerchantNo, expireAt: Now().Add(s.RetainMerchantNo)}
+		} else {
+			delete(s.merchant, old.MerchantNo)
+		}
+	}
+	a := *account
+	s.accounts[a.Uid] = &a
+	if a.MerchantNo != "" {
+		s.merchant[a.MerchantNo] = a.Uid
+	}
+	return nil
+}
+
+func (s *MemoryVirtualAccountStore) Get(uid string) (*VirtualAccount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	a, ok := s.accounts[uid]
+	if !ok {
+		return nil, ErrVirtualAccountNotFound
+	}
+	account := *a
+	return &account, nil
+}
+
+func (s *MemoryVirtualAccountStore) GetByMerchantNo(merchantNo string) (*VirtualAccount, error) {
+	s.mu.Lock()
+	uid, ok := s.merchant[merchantNo]
+	if prev, retained := s.previous[uid]; ok && retained && prev.merchantNo == merchantNo && !Now().Before(prev.expireAt) {
+		delete(s.merchant, merchantNo)
+		delete(s.previous, uid)
+		ok = false
+	}
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrVirtualAccountNotFound
+	}
+	return s.Get(uid)
+}
+
+// VirtualClient 创建虚拟账户的客户端，HttpClient 与 GrpcClient 均已实现
+type VirtualClient interface {
+	CreateVirtual(param *OrderParam) (*pb.VirtualResp, error)
+}
+
+// VirtualAccountHandlerFunc 关联到虚拟账户的收款回调处理函数
+type VirtualAccountHandlerFunc func(account *VirtualAccount, cb *pb.CallbackParam) error
+
+// VirtualAccountManager 管理用户与虚拟账户的对应关系，首次使用时创建并保存，
+// 同一 Uid 的并发请求只创建一次
+type VirtualAccountManager struct {
+	client VirtualClient
+	store  VirtualAccountStore
+	log    *logrus.Entry
+	locks  keyedMutex
+}
+
+// NewVirtualAccountManager 创建虚拟账户管理，store 为 nil 时使用内存存储
+func NewVirtualAccountManager(client VirtualClient, store VirtualAccountStore, log *logrus.Entry) *VirtualAccountManager {
+	if store == nil {
+		store = NewMemoryVirtualAccountStore()
+	}
+	if log == nil {
+		log = logrus.WithField("model", "VirtualAccountManager")
+	}
+	return &VirtualAccountManager{client: client, store: store, log: log}
+}
+
+// Get 返回用户的虚拟账户，不存在时使用 param 创建，param.Uid 必填
+func (m *VirtualAccountManager) Get(param *OrderParam) (*VirtualAccount, error) {
+	if param.Uid == "" {
+		return nil, ErrVirtualAccountUid
+	}
+	unlock := m.locks.lock(param.Uid)
+	defer unlock()
+
+	account, err := m.store.Get(param.Uid)
+	if err == nil {
+		return account, nil
+	}
+	if !errors.Is(err, ErrVirtualAccountNotFound) {
+		return nil, err
+	}
+	return m.create(param, nil)
+}
+
+// Refresh 重新创建用户的虚拟账户并覆盖已保存的账户，param.OrderNo 为空时由订单号生成器生成。
+// 原账户的商户订单号由存储保留一段时间，期间原账户的收款回调仍可关联到该用户
+func (m *VirtualAccountManager) Refresh(param *OrderParam) (*VirtualAccount, error) {
+	if param.Uid == "" {
+		return nil, ErrVirtualAccountUid
+	}
+	unlock := m.locks.lock(param.Uid)
+	defer unlock()
+
+	old, err := m.store.Get(param.Uid)
+	if err != nil && !errors.Is(err, ErrVirtualAccountNotFound) {
+		return nil, err
+	}
+	return m.create(param, old)
+}
+
+// Lookup 查询已保存的虚拟账户，不会创建
+func (m *VirtualAccountManager) Lookup(uid string) (*VirtualAccount, error) {
+	return m.store.Get(uid)
+}
+
+func (m *VirtualAccountManager) create(param *OrderParam, old *VirtualAccount) (*VirtualAccount, error) {
+	p := *param
+	resp, err := m.client.CreateVirtual(&p)
+	if err != nil {
+		return nil, err
+	}
+
+	now := timesOf(m.client).Now()
+	account := &VirtualAccount{
+		Uid:         p.Uid,
+		OrderNo:     resp.OrderNo,
+		MerchantNo:  resp.MerchantNo,
+		AccountName: resp.AccountName,
+		AccountNo:   resp.AccountNo,
+		PayUrl:      resp.PayUrl,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if account.MerchantNo == "" {
+		account.MerchantNo = p.OrderNo
+	}
+	if old != nil {
+		account.CreatedAt = old.CreatedAt
+	}
+	if err = m.store.Save(account); err != nil {
+		return nil, err
+	}
+	m.log.Infof("virtual account saved, uid: %s, accountNo: %s", account.Uid, account.AccountNo)
+	return account, nil
+}
+
+// Link 按商户订单号（当前或保留期内的原订单号）查找收款回调对应的虚拟账户。
+// 不按 Uid 匹配，拥有虚拟账户的用户的其他订单回调不会被关联
+func (m *VirtualAccountManager) Link(cb *pb.CallbackParam) (*VirtualAccount, error) {
+	if cb.MerchantNo == "" {
+		return nil, ErrVirtualAccountNotFound
+	}
+	return m.store.GetByMerchantNo(cb.MerchantNo)
+}
+
+// Handle 返回回调处理函数，关联到虚拟账户的回调交给 fn 处理，其余回调忽略，
+// 可注册到 CallbackDispatcher
+func (m *VirtualAccountManager) Handle(fn VirtualAccountHandlerFunc) CallbackHandlerFunc {
+	return func(cb *pb.CallbackParam) error {
+		account, err := m.Link(cb)
+		if errors.Is(err, ErrVirtualAccountNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return fn(account, cb)
+	}
+}
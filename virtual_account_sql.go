@@ -0,0 +1,102 @@
+package xmpay
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const virtualAccountColumns = "uid, order_no, merchant_no, account_name, account_no, pay_url, created_at, updated_at"
+
+// SQLVirtualAccountStore 基于 database/sql 的虚拟账户存储，账户被覆盖时原商户订单号记录在 prev_merchant_no
+type SQLVirtualAccountStore struct {
+	RetainMerchantNo time.Duration // 账户被覆盖后原商户订单号的保留时长，0 表示不保留
+
+	db          *sql.DB
+	table       string
+	placeholder Placeholder
+}
+
+// NewSQLVirtualAccountStore 创建 SQL 虚拟账户存储，table 为空时使用 xmpay_virtual_account
+func NewSQLVirtualAccountStore(db *sql.DB, table string, placeholder Placeholder) *SQLVirtualAccountStore {
+	if table == "" {
+		table = "xmpay_virtual_account"
+	}
+	return &SQLVirtualAccountStore{RetainMerchantNo: DefaultRetainMerchantNo, db: db, table: table, placeholder: placeholder}
+}
+
+// CreateTable 创建虚拟账户表
+func (s *SQLVirtualAccountStore) CreateTable() error {
+	_, err := s.db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	uid VARCHAR(64) NOT NULL PRIMARY KEY,
+	order_no VARCHAR(64) NOT NULL DEFAULT '',
+	merchant_no VARCHAR(64) NOT NULL DEFAULT '',
+	account_name VARCHAR(128) NOT NULL DEFAULT '',
+	account_no VARCHAR(64) NOT NULL DEFAULT '',
+	pay_url VARCHAR(512) NOT NULL DEFAULT '',
+	prev_merchant_no VARCHAR(64) NOT NULL DEFAULT '',
+	prev_expires_at BIGINT NOT NULL DEFAULT 0,
+	created_at BIGINT NOT NULL,
+	updated_at BIGINT NOT NULL
+)`, s.table))
+	if err != nil {
+		return err
+	}
+	if _, err = s.db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_merchant_no ON %s (merchant_no)", s.table, s.table)); err != nil {
+		return err
+	}
+	_, err = s.db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_prev_merchant_no ON %s (prev_merchant_no)", s.table, s.table))
+	return err
+}
+
+func (s *SQLVirtualAccountStore) Save(account *VirtualAccount) error {
+	if account.Uid == "" {
+		return ErrVirtualAccountUid
+	}
+	// 商户订单号变化时保留原订单号，prev_* 须在 merchant_no 之前赋值（MySQL 按顺序求值）
+	const changed = "merchant_no <> ? AND merchant_no <> ''"
+	expireAt := Now().Add(s.RetainMerchantNo).UnixMilli()
+	res, err := s.db.Exec(s.rebind("UPDATE %s SET prev_merchant_no = CASE WHEN "+changed+" THEN merchant_no ELSE prev_merchant_no END, "+
+		"prev_expires_at = CASE WHEN "+changed+" THEN ? ELSE prev_expires_at END, "+
+		"order_no = ?, merchant_no = ?, account_name = ?, account_no = ?, pay_url = ?, updated_at = ? WHERE uid = ?"),
+		account.MerchantNo, account.MerchantNo, expireAt, account.OrderNo, account.MerchantNo, account.AccountName, account.AccountNo, account.PayUrl, account.UpdatedAt.UnixMilli(), account.Uid)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		return nil
+	}
+	_, err = s.db.Exec(s.rebind("INSERT INTO %s ("+virtualAccountColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?)"),
+		account.Uid, account.OrderNo, account.MerchantNo, account.AccountName, account.AccountNo, account.PayUrl, account.CreatedAt.UnixMilli(), account.UpdatedAt.UnixMilli())
+	return err
+}
+
+func (s *SQLVirtualAccountStore) Get(uid string) (*VirtualAccount, error) {
+	return s.scan(s.db.QueryRow(s.rebind("SELECT "+virtualAccountColumns+" FROM %s WHERE uid = ?"), uid))
+}
+
+func (s *SQLVirtualAccountStore) GetByMerchantNo(merchantNo string) (*VirtualAccount, error) {
+	// 当前订单号优先，其次为保留期内的原订单号
+	return s.scan(s.db.QueryRow(s.rebind("SELECT "+virtualAccountColumns+" FROM %s WHERE merchant_no = ? OR (prev_merchant_no = ? AND prev_expires_at > ?) "+
+		"ORDER BY CASE WHEN merchant_no = ? THEN 0 ELSE 1 END LIMIT 1"), merchantNo, merchantNo, Now().UnixMilli(), merchantNo))
+}
+
+func (s *SQLVirtualAccountStore) scan(row *sql.Row) (*VirtualAccount, error) {
+	var (
+		a                    VirtualAccount
+		createdAt, updatedAt int64
+	)
+	err := row.Scan(&a.Uid, &a.OrderNo, &a.MerchantNo, &a.AccountName, &a.AccountNo, &a.PayUrl, &createdAt, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrVirtualAccountNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	a.CreatedAt = unixMilli(createdAt)
+	a.UpdatedAt = unixMilli(updatedAt)
+	return &a, nil
+}
+
+func (s *SQLVirtualAccountStore) rebind(query string) string {
+	return s.placeholder.rebind(fmt.Sprintf(query, s.table))
+}
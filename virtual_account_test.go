@@ -0,0 +1,85 @@
+package xmpay
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+)
+
+// virtualStub 每次创建返回新商户订单号的桩
+type virtualStub struct{ calls int }
+
+func (v *virtualStub) CreateVirtual(param *OrderParam) (*pb.VirtualResp, error) {
+	v.calls++
+	no := string(rune('0' + v.calls))
+	return &pb.VirtualResp{OrderNo: "T" + no, MerchantNo: "M" + no, AccountNo: "A" + no}, nil
+}
+
+func TestVirtualAccountRefreshKeepsMerchantNo(t *testing.T) {
+	store := NewMemoryVirtualAccountStore()
+	m := NewVirtualAccountManager(&virtualStub{}, store, testLogger())
+	param := &OrderParam{Uid: "u1"}
+
+	first, err := m.Get(param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.CreatedAt.Location() != Location() {
+		t.Errorf("created at location = %v", first.CreatedAt.Location())
+	}
+	refreshed, err := m.Refresh(param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.MerchantNo == first.MerchantNo || !refreshed.CreatedAt.Equal(first.CreatedAt) {
+		t.Fatalf("refreshed = %+v", refreshed)
+	}
+
+	// 保留期内原账户的回调仍关联到该用户的当前账户
+	account, err := m.Link(&pb.CallbackParam{MerchantNo: first.MerchantNo})
+	if err != nil || account.AccountNo != refreshed.AccountNo {
+		t.Fatalf("link old merchant no: %+v, %v", account, err)
+	}
+
+	// 再次覆盖只保留最近一次的原订单号
+	third, err := m.Refresh(param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.GetByMerchantNo(first.MerchantNo); !errors.Is(err, ErrVirtualAccountNotFound) {
+		t.Errorf("first merchant no still linked, err = %v", err)
+	}
+	if account, err = store.GetByMerchantNo(refreshed.MerchantNo); err != nil || account.AccountNo != third.AccountNo {
+		t.Errorf("previous merchant no: %+v, %v", account, err)
+	}
+
+	// 过期后不再关联
+	store.mu.Lock()
+	prev := store.previous["u1"]
+	prev.expireAt = Now().Add(-time.Second)
+	store.previous["u1"] = prev
+	store.mu.Unlock()
+	if _, err = store.GetByMerchantNo(refreshed.MerchantNo); !errors.Is(err, ErrVirtualAccountNotFound) {
+		t.Errorf("expired merchant no still linked, err = %v", err)
+	}
+	if _, err = store.GetByMerchantNo(third.MerchantNo); err != nil {
+		t.Errorf("current merchant no: %v", err)
+	}
+}
+
+func TestVirtualAccountLinkByMerchantNo(t *testing.T) {
+	m := NewVirtualAccountManager(&virtualStub{}, nil, testLogger())
+	account, err := m.Get(&OrderParam{Uid: "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linked, err := m.Link(&pb.CallbackParam{Uid: "u1", MerchantNo: account.MerchantNo}); err != nil || linked.Uid != "u1" {
+		t.Errorf("link = %+v, %v", linked, err)
+	}
+	// 同一用户的其他订单回调不关联到虚拟账户
+	if _, err = m.Link(&pb.CallbackParam{Uid: "u1", MerchantNo: "OTHER"}); !errors.Is(err, ErrVirtualAccountNotFound) {
+		t.Errorf("other order linked, err = %v", err)
+	}
+}
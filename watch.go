@@ -0,0 +1,319 @@
+package xmpay
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrWatchMerchantNos 轮询订阅必须指定商户订单号
+var ErrWatchMerchantNos = errors.New("polling watch requires merchant nos")
+
+// gRPC 订阅断开后的重连间隔，连续失败时翻倍
+const (
+	watchRetryMin = time.Second
+	watchRetryMax = 30 * time.Second
+)
+
+// WatchParam 订单状态订阅参数
+type WatchParam struct {
+	OrderType   pb.ORDER_TYPE // 订单类型，ALL 表示全部
+	MerchantNos []string      // 商户订单号，gRPC 订阅为空时订阅全部订单，轮询订阅必填
+	Cursor      string        // 从该游标之后继续推送，通常为上次 OrderStream.Cursor 的值
+}
+
+// OrderWatcher 订阅订单状态变更，GrpcClient 使用 watch_orders 流，HttpClient 与 OrderPoller 轮询查询接口
+type OrderWatcher interface {
+	WatchOrders(ctx context.Context, param *WatchParam) (*OrderStream, error)
+}
+
+var (
+	_ OrderWatcher = (*GrpcClient)(nil)
+	_ OrderWatcher = (*HttpClient)(nil)
+	_ OrderWatcher = (*OrderPoller)(nil)
+)
+
+// OrderStream 订单状态变更流，ctx 结束、调用 Close 或遇到不可恢复的错误时关闭 Events
+type OrderStream struct {
+	events chan *pb.OrderStatusEvent
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	cursor string
+	err    error
+}
+
+func newOrderStream(ctx context.Context, cursor string) (*OrderStream, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &OrderStream{
+		events: make(chan *pb.OrderStatusEvent),
+		cancel: cancel,
+		done:   make(chan struct{}),
+		cursor: cursor,
+	}, ctx
+}
+
+// Events 返回状态变更事件
+func (s *OrderStream) Events() <-chan *pb.OrderStatusEvent {
+	return s.events
+}
+
+// Cursor 返回最后一个已交付事件的游标，可用于重新订阅时续传
+func (s *OrderStream) Cursor() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursor
+}
+
+// Err 返回导致流关闭的错误，ctx 结束或调用 Close 时为 nil，应在 Events 关闭后调用
+func (s *OrderStream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close 停止订阅并等待流关闭
+func (s *OrderStream) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// send 交付事件，事件被接收后才推进游标
+func (s *OrderStream) send(ctx context.Context, event *pb.OrderStatusEvent) bool {
+	select {
+	case s.events <- event:
+	case <-ctx.Done():
+		return false
+	}
+	if event.Cursor != "" {
+		s.mu.Lock()
+		s.cursor = event.Cursor
+		s.mu.Unlock()
+	}
+	return true
+}
+
+func (s *OrderStream) finish(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		err = nil
+	}
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+	s.cancel()
+	close(s.events)
+	close(s.done)
+}
+
+// WatchOrders 通过 watch_orders 流订阅订单状态变更，连接断开时按游标自动重连续传；
+// 网关未实现该接口且指定了商户订单号时改为轮询查询接口
+func (c *GrpcClient) WatchOrders(ctx context.Context, param *WatchParam) (*OrderStream, error) {
+	stream, ctx := newOrderStream(ctx, param.Cursor)
+	go func() {
+		stream.finish(ctx, c.watchOrders(ctx, param, stream))
+	}()
+	return stream, nil
+}
+
+func (c *GrpcClient) watchOrders(ctx context.Context, param *WatchParam, stream *OrderStream) error {
+	delay := watchRetryMin
+	for {
+		received, err := c.watchOnce(ctx, param, stream)
+		if ctx.Err() != nil {
+			return nil
+		}
+		switch status.Code(err) {
+		case codes.Unimplemented:
+			if len(param.MerchantNos) == 0 {
+				return err
+			}
+			c.log.Warnf("watch_orders not implemented, fallback to polling")
+			return NewOrderPoller(c, c.log).poll(ctx, param, stream)
+		case codes.InvalidArgument, codes.PermissionDenied, codes.Unauthenticated:
+			return err
+		}
+		if IsApiError(err) || errors.Is(err, ErrDecryptKey) {
+			return err
+		}
+		if received {
+			delay = watchRetryMin
+		}
+		c.log.Warnf("watch_orders disconnected, retry in %s, cursor: %s, err: %v", delay, stream.Cursor(), err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > watchRetryMax {
+			delay = watchRetryMax
+		}
+	}
+}
+
+// watchOnce 建立一次订阅并接收事件直到连接断开，返回期间是否收到过事件
+func (c *GrpcClient) watchOnce(ctx context.Context, param *WatchParam, stream *OrderStream) (bool, error) {
+	rpcParam := c.encrypt(&pb.WatchOrdersParam{
+		OrderType:   param.OrderType,
+		MerchantNos: param.MerchantNos,
+		Cursor:      stream.Cursor(),
+	})
+	s, err := c.client.WatchOrders(ctx, rpcParam)
+	if err != nil {
+		return false, grpcError(err)
+	}
+
+	received := false
+	for {
+		resp, err := s.Recv()
+		if err == io.EOF {
+			return received, nil
+		}
+		if err != nil {
+			return received, grpcError(err)
+		}
+		data, err := c.decode(resp)
+		if err != nil {
+			return received, err
+		}
+		event, err := unmarshal[*pb.OrderStatusEvent](data)
+		if err != nil {
+			return received, err
+		}
+		c.recordOrder(orderFromEvent(c.times, event), OrderSourceWatch)
+		if !stream.send(ctx, event) {
+			return received, nil
+		}
+		received = true
+	}
+}
+
+// WatchOrders HTTP 传输没有推送接口，通过轮询查询接口订阅指定订单
+func (c *HttpClient) WatchOrders(ctx context.Context, param *WatchParam) (*OrderStream, error) {
+	return NewOrderPoller(c, c.log).WatchOrders(ctx, param)
+}
+
+// QueryClient 查询订单的客户端，HttpClient 与 GrpcClient 均已实现
+type QueryClient interface {
+	QueryReceive(orderNo, trxNo string) (*pb.OrderQueryResp, error)
+	QueryOut(orderNo, trxNo string) (*pb.OrderQueryResp, error)
+}
+
+// OrderPoller 定期查询指定订单，状态变化时推送事件，所有订单到达终态后关闭流。
+// 事件游标为订单更新时间（毫秒），续传时跳过不晚于游标的更新
+type OrderPoller struct {
+	client QueryClient
+	log    *logrus.Entry
+
+	Interval time.Duration // 查询间隔
+}
+
+// NewOrderPoller 创建轮询订阅，默认每 5 秒查询一次
+func NewOrderPoller(client QueryClient, log *logrus.Entry) *OrderPoller {
+	if log == nil {
+		log = logrus.WithField("model", "OrderPoller")
+	}
+	return &OrderPoller{client: client, log: log, Interval: 5 * time.Second}
+}
+
+// WatchOrders 轮询订阅指定的商户订单号
+func (p *OrderPoller) WatchOrders(ctx context.Context, param *WatchParam) (*OrderStream, error) {
+	if len(param.MerchantNos) == 0 {
+		return nil, ErrWatchMerchantNos
+	}
+	stream, ctx := newOrderStream(ctx, param.Cursor)
+	go func() {
+		stream.finish(ctx, p.poll(ctx, param, stream))
+	}()
+	return stream, nil
+}
+
+type polledOrder struct {
+	orderType pb.ORDER_TYPE
+	status    pb.ORDER_STATUS
+	seen      bool
+}
+
+func (p *OrderPoller) poll(ctx context.Context, param *WatchParam, stream *OrderStream) error {
+	var since int64
+	if param.Cursor != "" {
+		since, _ = strconv.ParseInt(param.Cursor, 10, 64)
+	}
+	pending := make(map[string]*polledOrder, len(param.MerchantNos))
+	for _, merchantNo := range param.MerchantNos {
+		pending[merchantNo] = &polledOrder{orderType: param.OrderType}
+	}
+
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		for merchantNo, order := range pending {
+			resp, err := p.query(merchantNo, order)
+			if err != nil {
+				p.log.Warnf("poll order failed, merchantNo: %s, err: %v", merchantNo, err)
+				continue
+			}
+			if isFinalStatus(resp.Status) {
+				delete(pending, merchantNo)
+			}
+			updated := timesOf(p.client).ParseTimestamp(resp.GetUpdateTime()).UnixMilli()
+			if (order.seen && resp.Status == order.status) || (since > 0 && updated <= since) {
+				order.seen, order.status = true, resp.Status
+				continue
+			}
+			order.seen, order.status = true, resp.Status
+			event := &pb.OrderStatusEvent{
+				Cursor:     strconv.FormatInt(updated, 10),
+				OrderType:  order.orderType,
+				OrderNo:    resp.OrderNo,
+				MerchantNo: resp.MerchantNo,
+				Amount:     resp.Amount,
+				Fee:        resp.Fee,
+				Status:     resp.Status,
+				UpdateTime: resp.UpdateTime,
+			}
+			if !stream.send(ctx, event) {
+				return nil
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// query 按订单类型查询，类型未知时先查收款单，网关返回业务错误再查付款单
+func (p *OrderPoller) query(merchantNo string, order *polledOrder) (*pb.OrderQueryResp, error) {
+	switch order.orderType {
+	case pb.ORDER_TYPE_OUT:
+		return p.client.QueryOut(merchantNo, "")
+	case pb.ORDER_TYPE_RECEIVE:
+		return p.client.QueryReceive(merchantNo, "")
+	}
+	resp, err := p.client.QueryReceive(merchantNo, "")
+	if err == nil {
+		order.orderType = pb.ORDER_TYPE_RECEIVE
+		return resp, nil
+	}
+	if !IsApiError(err) {
+		return nil, err
+	}
+	if resp, err = p.client.QueryOut(merchantNo, ""); err == nil {
+		order.orderType = pb.ORDER_TYPE_OUT
+	}
+	return resp, err
+}
@@ -0,0 +1,152 @@
+package xmpay_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	xmpay "github.com/XingMenTech/XMPAY-SDK-GO"
+	"github.com/XingMenTech/XMPAY-SDK-GO/pb"
+	"github.com/XingMenTech/XMPAY-SDK-GO/simulator"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// startGateway 在本地端口启动模拟网关，返回连接该网关的 GrpcClient
+func startGateway(t *testing.T) (*simulator.Gateway, *xmpay.GrpcClient) {
+	t.Helper()
+	config := &xmpay.Config{AccessId: "0123456789abcdef", AccessKey: "fedcba9876543210", InId: "7", OutId: "8"}
+	gateway := simulator.NewGateway(config)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	gateway.Register(server)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	config.ApiUrl = lis.Addr().String()
+	client, err := xmpay.NewGrpcClient(config, logrus.NewEntry(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return gateway, client
+}
+
+func setOrder(g *simulator.Gateway, merchantNo string, status pb.ORDER_STATUS) {
+	g.SetOrder(pb.ORDER_TYPE_RECEIVE, &pb.OrderQueryResp{OrderNo: "T" + merchantNo, MerchantNo: merchantNo, Amount: 100, Status: status})
+}
+
+// nextEvent 等待下一个事件，超时或流关闭时测试失败
+func nextEvent(t *testing.T, stream *xmpay.OrderStream) *pb.OrderStatusEvent {
+	t.Helper()
+	select {
+	case event, ok := <-stream.Events():
+		if !ok {
+			t.Fatalf("stream closed, err: %v", stream.Err())
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for event")
+	}
+	return nil
+}
+
+func TestWatchOrdersReconnect(t *testing.T) {
+	gateway, client := startGateway(t)
+	stream, err := client.WatchOrders(context.Background(), &xmpay.WatchParam{Cursor: "0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	setOrder(gateway, "M1", pb.ORDER_STATUS_PROCESSING)
+	if event := nextEvent(t, stream); event.MerchantNo != "M1" || event.Cursor != "1" {
+		t.Fatalf("event = %v", event)
+	}
+
+	// 断开期间产生的事件在重连后按游标补发，已交付的事件不重复推送
+	gateway.DropWatchers()
+	setOrder(gateway, "M2", pb.ORDER_STATUS_PROCESSING)
+	setOrder(gateway, "M1", pb.ORDER_STATUS_SUCCESS)
+	for _, want := range []string{"M2", "M1"} {
+		if event := nextEvent(t, stream); event.MerchantNo != want {
+			t.Fatalf("event = %v, want %s", event, want)
+		}
+	}
+	_ = stream.Close()
+	if stream.Cursor() != "3" {
+		t.Errorf("cursor = %s, want 3", stream.Cursor())
+	}
+}
+
+func TestWatchOrdersResume(t *testing.T) {
+	gateway, client := startGateway(t)
+	setOrder(gateway, "M1", pb.ORDER_STATUS_PROCESSING)
+	setOrder(gateway, "M2", pb.ORDER_STATUS_PROCESSING)
+
+	stream, err := client.WatchOrders(context.Background(), &xmpay.WatchParam{Cursor: "0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextEvent(t, stream)
+	_ = stream.Close()
+	cursor := stream.Cursor()
+	if cursor != "1" {
+		t.Fatalf("cursor = %s, want 1", cursor)
+	}
+
+	// 使用上次的游标重新订阅，从未交付的事件继续
+	setOrder(gateway, "M3", pb.ORDER_STATUS_SUCCESS)
+	stream, err = client.WatchOrders(context.Background(), &xmpay.WatchParam{Cursor: cursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	for _, want := range []string{"M2", "M3"} {
+		if event := nextEvent(t, stream); event.MerchantNo != want {
+			t.Fatalf("event = %v, want %s", event, want)
+		}
+	}
+}
+
+func TestWatchOrdersPollingFallback(t *testing.T) {
+	gateway, client := startGateway(t)
+	gateway.DisableWatch = true
+	setOrder(gateway, "M1", pb.ORDER_STATUS_SUCCESS)
+
+	// 未指定商户订单号时无法降级为轮询
+	stream, err := client.WatchOrders(context.Background(), &xmpay.WatchParam{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range stream.Events() {
+	}
+	if stream.Err() == nil {
+		t.Error("watch all orders: want unimplemented error")
+	}
+
+	stream, err = client.WatchOrders(context.Background(), &xmpay.WatchParam{MerchantNos: []string{"M1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	event := nextEvent(t, stream)
+	if event.MerchantNo != "M1" || event.Status != pb.ORDER_STATUS_SUCCESS || event.OrderType != pb.ORDER_TYPE_RECEIVE {
+		t.Errorf("event = %v", event)
+	}
+	// 所有订单到达终态后关闭流
+	if _, ok := <-stream.Events(); ok {
+		t.Error("stream not closed after final status")
+	}
+	if err = stream.Err(); err != nil {
+		t.Errorf("err = %v", err)
+	}
+}